@@ -0,0 +1,112 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(&ClusterReplicaAutoscalerPolicy{}, &ClusterReplicaAutoscalerPolicyList{})
+}
+
+// ClusterReplicaAutoscalerPolicySpec supplies defaults and enforces limits for selected ReplicaAutoscalers
+type ClusterReplicaAutoscalerPolicySpec struct {
+	// NamespaceSelector selects namespaces of ReplicaAutoscalers by labels, nil selects all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// AutoscalerSelector selects ReplicaAutoscalers by labels, nil selects all autoscalers.
+	// Autoscalers selected by both selectors are governed by the policy.
+	// +optional
+	AutoscalerSelector *metav1.LabelSelector `json:"autoscalerSelector,omitempty"`
+	// Priority decides which defaults take precedence among policies selecting the same autoscaler,
+	// higher one wins and policies of the same priority are ordered by name.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Defaults are applied to fields not set by autoscalers.
+	// +optional
+	Defaults *ReplicaAutoscalerPolicyDefaults `json:"defaults,omitempty"`
+	// Limits are enforced on autoscalers, limits of all selecting policies are enforced together.
+	// +optional
+	Limits *ReplicaAutoscalerPolicyLimits `json:"limits,omitempty"`
+}
+
+// ReplicaAutoscalerPolicyDefaults are defaults of ReplicaAutoscaler spec
+type ReplicaAutoscalerPolicyDefaults struct {
+	// Strategy is used if autoscaler has no strategy.
+	// +optional
+	Strategy *ReplicaAutoscalerStrategy `json:"strategy,omitempty"`
+	// Exhaust is used if autoscaler has no exhaust config.
+	// +optional
+	Exhaust *Exhaust `json:"exhaust,omitempty"`
+	// ReplicatorSettings is the base which replicator settings of autoscaler are merged into.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	ReplicatorSettings *runtime.RawExtension `json:"replicatorSettings,omitempty"`
+	// Targets are base settings which default settings of autoscaler targets with the same metric are merged into.
+	// +optional
+	Targets []ReplicaAutoscalerPolicyTarget `json:"targets,omitempty"`
+}
+
+// ReplicaAutoscalerPolicyTarget is default settings of targets with the metric
+type ReplicaAutoscalerPolicyTarget struct {
+	Metric string `json:"metric"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Settings *runtime.RawExtension `json:"settings"`
+}
+
+// ReplicaAutoscalerPolicyLimits are hard limits of ReplicaAutoscaler
+type ReplicaAutoscalerPolicyLimits struct {
+	// MaxReplicas is the ceiling of max replicas and replica patches.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// MinScalingColdDownSeconds is the minimum cold-down between scaling.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinScalingColdDownSeconds *int32 `json:"minScalingColdDownSeconds,omitempty"`
+	// AllowedScalers are scalers which targets could use, empty means all scalers are allowed.
+	// +listType=set
+	// +optional
+	AllowedScalers []string `json:"allowedScalers,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName=rapolicy
+//+kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+//+kubebuilder:printcolumn:name="MaxReplicas",type=integer,JSONPath=`.spec.limits.maxReplicas`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterReplicaAutoscalerPolicy is the Schema for the clusterreplicaautoscalerpolicies API,
+// it supplies organization-wide defaults and guardrails for ReplicaAutoscalers.
+type ClusterReplicaAutoscalerPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterReplicaAutoscalerPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterReplicaAutoscalerPolicyList contains a list of ClusterReplicaAutoscalerPolicy
+type ClusterReplicaAutoscalerPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterReplicaAutoscalerPolicy `json:"items"`
+}
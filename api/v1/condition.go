@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -12,6 +14,8 @@ const (
 	ConditionReady          ConditionType = "Ready"
 	ConditionPanicMode      ConditionType = "PanicMode"
 	ConditionExhausted      ConditionType = "Exhausted"
+	ConditionPaused         ConditionType = "Paused"
+	ConditionConflict       ConditionType = "Conflict"
 )
 
 type Conditions []Condition
@@ -44,6 +48,11 @@ func InitializeConditions(c *Conditions) {
 }
 
 func SetCondition(conditions Conditions, condition Condition) Conditions {
+	return SetConditionAt(conditions, condition, time.Now())
+}
+
+// SetConditionAt sets condition and takes now as last transition time if condition transited
+func SetConditionAt(conditions Conditions, condition Condition, now time.Time) Conditions {
 	if len(conditions) == 0 {
 		InitializeConditions(&conditions)
 	}
@@ -52,7 +61,7 @@ func SetCondition(conditions Conditions, condition Condition) Conditions {
 		if cond.Type == condition.Type {
 			found = true
 			if conditions[i].Status != condition.Status || conditions[i].Reason != condition.Reason {
-				conditions[i].LastTransitionTime = metav1.Now()
+				conditions[i].LastTransitionTime = metav1.NewTime(now)
 			}
 			conditions[i].Status = condition.Status
 			conditions[i].Reason = condition.Reason
@@ -60,7 +69,7 @@ func SetCondition(conditions Conditions, condition Condition) Conditions {
 		}
 	}
 	if !found {
-		condition.LastTransitionTime = metav1.Now()
+		condition.LastTransitionTime = metav1.NewTime(now)
 		conditions = append(conditions, condition)
 	}
 	return conditions
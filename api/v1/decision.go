@@ -0,0 +1,57 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DecisionStage indicates which stage of scaling flow contributed a decision step
+type DecisionStage string
+
+const (
+	// DecisionStageScaler is the desired replicas calculated by a scaler for a target
+	DecisionStageScaler DecisionStage = "Scaler"
+	// DecisionStageReplicator is the aggregated desired replicas made by replicator
+	DecisionStageReplicator DecisionStage = "Replicator"
+	// DecisionStageTuner is the adjustment made by tuner of replicator
+	DecisionStageTuner DecisionStage = "Tuner"
+	// DecisionStageReplicaPatch is the scaling range applied by working replica patch
+	DecisionStageReplicaPatch DecisionStage = "ReplicaPatch"
+	// DecisionStageLimit is the clamping by min/max replicas
+	DecisionStageLimit DecisionStage = "Limit"
+	// DecisionStageDryRun means the final decision is not performed
+	DecisionStageDryRun DecisionStage = "DryRun"
+)
+
+// DecisionStep is a single explanation entry of scaling decision
+type DecisionStep struct {
+	// Stage of scaling flow which made this step
+	Stage DecisionStage `json:"stage"`
+	// Source is the name of target, replicator or tuner which made this step
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Replicas is the desired replicas after this step
+	Replicas int32 `json:"replicas"`
+	// Message is the human readable explanation of this step
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ScalingDecision is the structured trace of how the final desired replicas is decided
+type ScalingDecision struct {
+	// Time is the time when the decision first made, it won't be refreshed if decision unchanged.
+	Time metav1.Time `json:"time"`
+	// CurrentReplicas is the replicas of scale target when making decision
+	CurrentReplicas int32 `json:"currentReplicas"`
+	// DesiredReplicas is the final desired replicas
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// PanicMode indicates whether the autoscaler is in panic mode
+	// +optional
+	PanicMode bool `json:"panicMode,omitempty"`
+	// DryRun indicates whether the scaling action is skipped by dry-run
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Steps are the explanation entries in order of scaling flow
+	// +listType=atomic
+	// +optional
+	Steps []DecisionStep `json:"steps,omitempty"`
+}
@@ -8,6 +8,11 @@ const (
 )
 
 const (
-	EventReasonScaling   = "Scaling"
-	EventReasonPanicMode = "PanicMode"
+	EventReasonScaling       = "Scaling"
+	EventReasonPanicMode     = "PanicMode"
+	EventReasonPaused        = "Paused"
+	EventReasonConflict      = "Conflict"
+	EventReasonQuotaExceeded = "QuotaExceeded"
+
+	EventReasonScalingWindowExpired = "ScalingWindowExpired"
 )
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -94,8 +95,89 @@ type ReplicaAutoscalerSpec struct {
 
 	// +optional
 	Exhaust *Exhaust `json:"exhaust,omitempty" yaml:"exhaust,omitempty"`
+
+	// ConflictPolicy decides what to do when HorizontalPodAutoscaler targets the same object.
+	// Conflicts with other autoscalers(ReplicaAutoscaler or ScaledObject) always refuse to scale,
+	// and HPAs are not taken over then or under dry run.
+	// +kubebuilder:validation:Enum=Refuse;DeleteHPA;SuspendHPA
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// ReplicaPatchMode decides how replica patches working at the same time are combined,
+	// defaults to HighestPriority.
+	// +kubebuilder:validation:Enum=HighestPriority;Widest;Narrowest
+	// +optional
+	ReplicaPatchMode ReplicaPatchMode `json:"replicaPatchMode,omitempty"`
+
+	// Members are scale targets scaled proportionally along with ScaleTargetRef, which drives autoscaling
+	// with its pods and replicas.
+	// +listType=atomic
+	// +optional
+	Members []ScaleTargetMember `json:"members,omitempty"`
+	// ReplicaBudget is the limit of total replicas shared by ScaleTargetRef and members,
+	// desired replicas of ScaleTargetRef are lowered until the total fits in.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReplicaBudget *int32 `json:"replicaBudget,omitempty"`
 }
 
+// ScaleTargetMember is a scale target following replicas of ScaleTargetRef by ratio
+type ScaleTargetMember struct {
+	// ScaleTargetRef points to the member to scale
+	ScaleTargetRef CrossVersionObjectReference `json:"scaleTargetRef"`
+	// Ratio of member replicas to desired replicas of ScaleTargetRef, e.g. `0.5` or `2`.
+	// Only 3 decimal places are taken into account.
+	Ratio resource.Quantity `json:"ratio"`
+	// Rounding decides how fractional replicas are rounded, defaults to Ceil.
+	// +kubebuilder:validation:Enum=Ceil;Floor;Round
+	// +optional
+	Rounding MemberRounding `json:"rounding,omitempty"`
+	// MinReplicas is the lower limit of member replicas
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of member replicas
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// MemberRounding is how fractional replicas of member are rounded
+type MemberRounding string
+
+const (
+	// MemberRoundingCeil rounds up, it's the default rounding.
+	MemberRoundingCeil MemberRounding = "Ceil"
+	// MemberRoundingFloor rounds down.
+	MemberRoundingFloor MemberRounding = "Floor"
+	// MemberRoundingRound rounds half up.
+	MemberRoundingRound MemberRounding = "Round"
+)
+
+// ReplicaPatchMode is how working replica patches are combined
+type ReplicaPatchMode string
+
+const (
+	// ReplicaPatchHighestPriority applies the working patch of highest priority, the first one wins on a tie
+	ReplicaPatchHighestPriority ReplicaPatchMode = "HighestPriority"
+	// ReplicaPatchWidest applies the lowest min replicas and highest max replicas of working patches
+	ReplicaPatchWidest ReplicaPatchMode = "Widest"
+	// ReplicaPatchNarrowest applies the highest min replicas and lowest max replicas of working patches,
+	// the patch of highest priority is applied if they have no common range.
+	ReplicaPatchNarrowest ReplicaPatchMode = "Narrowest"
+)
+
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyRefuse refuses to scale when conflict found, it's the default policy.
+	ConflictPolicyRefuse ConflictPolicy = "Refuse"
+	// ConflictPolicyDeleteHPA takes over the target by deleting the conflicted HPA.
+	ConflictPolicyDeleteHPA ConflictPolicy = "DeleteHPA"
+	// ConflictPolicySuspendHPA takes over the target by disabling both scale up and down of the conflicted HPA.
+	ConflictPolicySuspendHPA ConflictPolicy = "SuspendHPA"
+)
+
 const (
 	// DefaultMinReplicas is the default maximum number of replicas if not provided
 	DefaultMinReplicas int32 = 1
@@ -127,16 +209,84 @@ type TargetSettings struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	Schedules []ScheduleTargetSettings `json:"schedules,omitempty"`
+
+	// MergeMode decides how schedules hit at the same time are applied to default settings,
+	// defaults to HighestPriority.
+	// +kubebuilder:validation:Enum=HighestPriority;Merge
+	// +optional
+	MergeMode ScheduleMergeMode `json:"mergeMode,omitempty"`
 }
 
+// ScheduleMergeMode is how hit schedules are applied to default settings
+type ScheduleMergeMode string
+
+const (
+	// ScheduleHighestPriority merges settings of the hit schedule of highest priority, the first one wins on a tie
+	ScheduleHighestPriority ScheduleMergeMode = "HighestPriority"
+	// ScheduleMerge merges settings of all hit schedules in order of priority, so the highest priority wins on conflict
+	ScheduleMerge ScheduleMergeMode = "Merge"
+)
+
 type ScheduleTargetSettings struct {
 	Timezone string `json:"timezone"`
-	Start    string `json:"start"`
-	End      string `json:"end"`
+	// Start is required unless calendar works alone.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// End is required unless duration is set.
+	// +optional
+	End string `json:"end,omitempty"`
+	// Duration makes cron schedule a window lasting for duration from each start, instead of ending at end.
+	// Ranges and lists are allowed in minute and hour of start then, e.g. `0 */2 * * *` with `15m`.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Calendar narrows the period to or out of calendar dates, e.g. weekdays except holidays.
+	// The calendar itself is the period without start.
+	// +optional
+	Calendar *ScheduleCalendar `json:"calendar,omitempty"`
+	// Priority of the schedule, higher one takes precedence over overlapping schedules.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// LeadTime activates the schedule earlier than each start, e.g. `5m` for warming up.
+	// +optional
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+	// ScaleDownDelay keeps the schedule working after each end for the delay.
+	// +optional
+	ScaleDownDelay *metav1.Duration `json:"scaleDownDelay,omitempty"`
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Settings *runtime.RawExtension `json:"settings"`
 }
 
+// CalendarMode indicates how calendar narrows the schedule period
+type CalendarMode string
+
+const (
+	// CalendarInclude makes schedule work only within calendar
+	CalendarInclude CalendarMode = "Include"
+	// CalendarExclude makes schedule work only out of calendar
+	CalendarExclude CalendarMode = "Exclude"
+)
+
+// ScheduleCalendar is a set of dates or events such as public holidays or sales events,
+// all of dates, iCalendar and ConfigMap are merged.
+type ScheduleCalendar struct {
+	// Mode is how calendar narrows the schedule period, defaults to Include.
+	// +kubebuilder:validation:Enum=Include;Exclude
+	// +optional
+	Mode CalendarMode `json:"mode,omitempty"`
+	// Dates are whole days in `2006-01-02` format in timezone of schedule.
+	// +listType=atomic
+	// +optional
+	Dates []string `json:"dates,omitempty"`
+	// ICalendar is iCalendar(.ics) payload whose events are periods of calendar.
+	// Floating times are in timezone of schedule and recurring events are not supported.
+	// +optional
+	ICalendar string `json:"iCalendar,omitempty"`
+	// ConfigMapKeyRef refers to a key of ConfigMap in the same namespace,
+	// whose value is either iCalendar payload or dates line by line.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
 // Exhaust is the settings for exhaust checking
 type Exhaust struct {
 	// Type of exhaust mode, only `Pending` is currently supported.
@@ -181,6 +331,11 @@ type ReplicaAutoscalerStatus struct {
 	// +kubebuilder:default=0
 	DesiredReplicas int32 `json:"desiredReplicas"`
 
+	// selector is the label selector of pods managed by scale target, in serialized form.
+	// It's used to map pod events to this autoscaler.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
 	// targets indicates state of targets used by this autoscaler
 	// +listType=atomic
 	// +patchMergeKey=target
@@ -194,6 +349,150 @@ type ReplicaAutoscalerStatus struct {
 	// +patchStrategy=merge
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" listType:"map"`
+
+	// replicaPatch is the scaling range combined from replica patches working in the latest reconcile,
+	// nil means no patch works.
+	// +optional
+	ReplicaPatch *ReplicaPatchStatus `json:"replicaPatch,omitempty"`
+
+	// policy is the effective settings governed by ClusterReplicaAutoscalerPolicies in the latest reconcile,
+	// nil means no policy selects the autoscaler.
+	// +optional
+	Policy *AutoscalerPolicyStatus `json:"policy,omitempty"`
+
+	// replicaRamp is the ramp of scaling range between replica patch transitions in progress,
+	// nil means the scaling range applies at once.
+	// +optional
+	ReplicaRamp *ReplicaRampStatus `json:"replicaRamp,omitempty"`
+
+	// lastDecision explains how the desired replicas was decided in the latest reconcile
+	// +optional
+	LastDecision *ScalingDecision `json:"lastDecision,omitempty"`
+
+	// expiredSchedules are one-off date schedules of targets which have ended,
+	// they never work again and could be removed from spec.
+	// +listType=atomic
+	// +optional
+	ExpiredSchedules []ExpiredSchedule `json:"expiredSchedules,omitempty"`
+
+	// scalingHistory holds the latest scaling actions in order of newest first,
+	// it's bounded by controller's history limit and retention.
+	// +listType=atomic
+	// +optional
+	ScalingHistory []ScalingRecord `json:"scalingHistory,omitempty"`
+
+	// members are status of member scale targets in order of spec
+	// +listType=atomic
+	// +optional
+	Members []MemberStatus `json:"members,omitempty"`
+}
+
+// ExpiredSchedule represents an ended date schedule of target
+type ExpiredSchedule struct {
+	// Target is metric of the target which the schedule belongs to
+	Target string `json:"target"`
+	// Index is index of the schedule in target settings
+	Index int32 `json:"index"`
+	// End is the end of schedule period in its timezone
+	End string `json:"end"`
+}
+
+// ReplicaPatchStatus is the effective scaling range combined from working replica patches
+type ReplicaPatchStatus struct {
+	// Mode of combining working patches
+	Mode ReplicaPatchMode `json:"mode"`
+	// MinReplicas is the effective lower limit
+	MinReplicas int32 `json:"minReplicas"`
+	// MaxReplicas is the effective upper limit
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Sources are the combined patches in order of priority,
+	// which are `annotation[<index>]` or `ScalingWindow/<name>`.
+	// +listType=atomic
+	Sources []string `json:"sources"`
+	// Ramp of the patch of highest priority, which works on entering and exiting the patch
+	// +optional
+	Ramp *ReplicaPatchRamp `json:"ramp,omitempty"`
+}
+
+// AutoscalerPolicyStatus is the effective spec merged with ClusterReplicaAutoscalerPolicies
+type AutoscalerPolicyStatus struct {
+	// Policies are names of selecting policies in order of precedence
+	// +listType=atomic
+	Policies []string `json:"policies"`
+	// MaxReplicas is the effective upper limit after ceiling of policies
+	MaxReplicas int32 `json:"maxReplicas"`
+	// MaxReplicasCeiling is the lowest ceiling of policies, which limits replica patches as well
+	// +optional
+	MaxReplicasCeiling *int32 `json:"maxReplicasCeiling,omitempty"`
+	// ScalingColdDownSeconds is the effective cold-down between scaling
+	ScalingColdDownSeconds int32 `json:"scalingColdDownSeconds"`
+	// Strategy is the effective strategy
+	// +optional
+	Strategy *ReplicaAutoscalerStrategy `json:"strategy,omitempty"`
+	// Exhaust is the effective exhaust config
+	// +optional
+	Exhaust *Exhaust `json:"exhaust,omitempty"`
+	// ReplicatorSettings are the effective replicator settings
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	ReplicatorSettings *runtime.RawExtension `json:"replicatorSettings,omitempty"`
+	// DisallowedScalers are scalers of targets not allowed by policies, the autoscaler refuses to scale
+	// until they are removed.
+	// +listType=atomic
+	// +optional
+	DisallowedScalers []string `json:"disallowedScalers,omitempty"`
+}
+
+// MemberStatus is the observed state of a member scale target
+type MemberStatus struct {
+	// Kind of the member
+	Kind string `json:"kind"`
+	// Name of the member
+	Name string `json:"name"`
+	// CurrentReplicas is current replicas of the member as last seen by the autoscaler
+	CurrentReplicas int32 `json:"currentReplicas"`
+	// DesiredReplicas is the desired replicas of the member as last calculated by the autoscaler
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// LastScaleTime is the last time the member scaled
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// Message explains why the member failed to scale, empty if it works
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ReplicaRampStatus is the ramp of scaling range in progress
+type ReplicaRampStatus struct {
+	// FromMinReplicas is the lower limit ramping from
+	FromMinReplicas int32 `json:"fromMinReplicas"`
+	// FromMaxReplicas is the upper limit ramping from
+	FromMaxReplicas int32 `json:"fromMaxReplicas"`
+	// ToMinReplicas is the lower limit ramping to
+	ToMinReplicas int32 `json:"toMinReplicas"`
+	// ToMaxReplicas is the upper limit ramping to
+	ToMaxReplicas int32 `json:"toMaxReplicas"`
+	// StartTime of the ramp
+	StartTime metav1.Time `json:"startTime"`
+
+	ReplicaPatchRamp `json:",inline"`
+}
+
+// ScalingRecord represents a performed scaling action
+type ScalingRecord struct {
+	// Time is the time when the scaling action performed
+	Time metav1.Time `json:"time"`
+	// FromReplicas is the replicas before scaling
+	FromReplicas int32 `json:"fromReplicas"`
+	// ToReplicas is the replicas after scaling
+	ToReplicas int32 `json:"toReplicas"`
+	// Reason is the brief CamelCase reason of this scaling action
+	Reason string `json:"reason"`
+	// Target is the winning target which decided the desired replicas
+	// +optional
+	Target string `json:"target,omitempty"`
+	// PanicMode indicates whether the autoscaler was in panic mode
+	// +optional
+	PanicMode bool `json:"panicMode,omitempty"`
 }
 
 // TargetStatus represents the running status of scaling target
@@ -217,6 +516,7 @@ type TargetStatus struct {
 //+kubebuilder:printcolumn:name="Replicas",type=string,JSONPath=`.status.currentReplicas`
 //+kubebuilder:printcolumn:name="Scalers",type=string,JSONPath=`.status.targets[*].scaler`
 //+kubebuilder:printcolumn:name="LastScaleTime",type=string,JSONPath=`.status.lastScaleTime`
+//+kubebuilder:printcolumn:name="LastScaleReason",type=string,JSONPath=`.status.scalingHistory[0].reason`
 //+kubebuilder:printcolumn:name="ReplicaPatched",type=string,JSONPath=`.status.conditions[?(@.type=="ReplicaPatched")].status`
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 //+kubebuilder:printcolumn:name="PanicMode",type="string",JSONPath=".status.conditions[?(@.type==\"PanicMode\")].status"
@@ -249,23 +549,63 @@ const (
 // WARNING: If it's a static replica autoscaler, this patch will be ignored.
 type ReplicaPatch struct {
 	// Specified the working timezone of the patch.
+	// +kubebuilder:validation:MinLength=1
 	Timezone string `json:"timezone"`
 	// Start and End could be a cron expression or a time string.
 	// But can't be mixed.
-	Start string `json:"start"`
-	End   string `json:"end"`
+	// Start is required unless calendar works alone.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// End is required unless duration is set.
+	// +optional
+	End string `json:"end,omitempty"`
+	// Duration makes cron patch a window lasting for duration from each start, instead of ending at end.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Calendar narrows the period to or out of calendar dates.
+	// The calendar itself is the period without start.
+	// +optional
+	Calendar *ScheduleCalendar `json:"calendar,omitempty"`
+	// Priority of the patch, higher one takes precedence over overlapping patches.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// LeadTime activates the patch earlier than each start, so that replicas are warmed up before start.
+	// +optional
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+	// ScaleDownDelay keeps the patch working after each end for the delay before scaling down.
+	// Retention of date patch counts from the delayed end.
+	// +optional
+	ScaleDownDelay *metav1.Duration `json:"scaleDownDelay,omitempty"`
+	// Ramp moves scaling range gradually from the previous range on entering and exiting the patch,
+	// instead of jumping at once.
+	// +optional
+	Ramp *ReplicaPatchRamp `json:"ramp,omitempty"`
 	// When using specified time range, retention seconds is required.
 	// It's the time duration of the patch will be hold for after end time, then will be purge.
 	// Zero means will be deleted once found out of the time range.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
 	RetentionSeconds *int64 `json:"retentionSeconds,omitempty"`
 	// MinReplicas is the lower limit for the number of replicas to which the autoscaler can scale down.
+	// +kubebuilder:validation:Minimum=0
 	MinReplicas int32 `json:"minReplicas"`
 	// MaxReplicas is the upper limit for the number of replicas to which the autoscaler can scale up.
+	// +kubebuilder:validation:Minimum=0
 	MaxReplicas int32 `json:"maxReplicas"`
 }
 
 type ReplicaPatches []ReplicaPatch
 
+// ReplicaPatchRamp interpolates scaling range between the previous range and the target one
+type ReplicaPatchRamp struct {
+	// Duration of ramping from the previous range to the target one.
+	Duration metav1.Duration `json:"duration"`
+	// Steps ramps range stepwise in equal steps over the duration, zero means linearly.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Steps int32 `json:"steps,omitempty"`
+}
+
 const (
 	// DryRunAnnotation is used to indicate whether the scaling action should be performed.
 	// If it's set to true, the scaling action will be performed.
@@ -273,3 +613,30 @@ const (
 	// Will be performed by default in next release.
 	DryRunAnnotation = "wing.xscaling.dev/dry-run"
 )
+
+const (
+	// PausedUntilAnnotation freezes the autoscaler at current replicas until the given RFC3339 time,
+	// e.g. `2024-08-15T10:00:00Z`. It will be purged by controller after expiry.
+	PausedUntilAnnotation = "wing.xscaling.dev/paused-until"
+	// OverrideReplicasAnnotation pins the scale target to specified replicas until expiry,
+	// it's a json string of ReplicaOverride and will be purged by controller after expiry.
+	// WARNING: Paused autoscaler won't apply override replicas.
+	OverrideReplicasAnnotation = "wing.xscaling.dev/override-replicas"
+)
+
+const (
+	// HandoverStateAnnotation carries in-memory states of plugins from previous owner controller
+	// to the next one when autoscaler ownership moves between shards.
+	// It's a json object of states keyed by `endpoint/plugin` with the owner and ring version handed over to,
+	// and will be purged once observed by owner. Stale state handed over on other rings is dropped.
+	HandoverStateAnnotation = "wing.xscaling.dev/handover-state"
+)
+
+// ReplicaOverride is stored in annotation `wing.xscaling.dev/override-replicas`
+type ReplicaOverride struct {
+	// Replicas is the pinned replicas of scale target, min/max replicas and patches are ignored,
+	// but it's still limited by policy ceiling, replica budget and namespace quota.
+	Replicas int32 `json:"replicas"`
+	// Until is the expiry time of the override in RFC3339 format.
+	Until metav1.Time `json:"until"`
+}
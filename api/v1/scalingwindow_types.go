@@ -0,0 +1,107 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&ScalingWindow{}, &ScalingWindowList{})
+}
+
+// ScalingWindowSpec defines a replica patch applied to selected ReplicaAutoscalers in the same namespace
+type ScalingWindowSpec struct {
+	// AutoscalerNames selects ReplicaAutoscalers by name.
+	// +listType=set
+	// +optional
+	AutoscalerNames []string `json:"autoscalerNames,omitempty"`
+	// AutoscalerSelector selects ReplicaAutoscalers by labels.
+	// Autoscalers selected by either names or selector are patched.
+	// +optional
+	AutoscalerSelector *metav1.LabelSelector `json:"autoscalerSelector,omitempty"`
+
+	// ReplicaPatch is the period and scaling range working on selected autoscalers.
+	ReplicaPatch `json:",inline"`
+}
+
+// ScalingWindowPhase indicates whether the window is working
+type ScalingWindowPhase string
+
+const (
+	// ScalingWindowPending means the date window is not started yet
+	ScalingWindowPending ScalingWindowPhase = "Pending"
+	// ScalingWindowActive means the window is working now
+	ScalingWindowActive ScalingWindowPhase = "Active"
+	// ScalingWindowInactive means the cron window is out of period now
+	ScalingWindowInactive ScalingWindowPhase = "Inactive"
+	// ScalingWindowExpired means the date window is ended and will be deleted after retention
+	ScalingWindowExpired ScalingWindowPhase = "Expired"
+	// ScalingWindowInvalid means the window is broken and ignored by autoscalers
+	ScalingWindowInvalid ScalingWindowPhase = "Invalid"
+)
+
+// ScalingWindowStatus defines the observed state of ScalingWindow
+type ScalingWindowStatus struct {
+	// observedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase of the window at the time of last reconcile.
+	// +optional
+	Phase ScalingWindowPhase `json:"phase,omitempty"`
+	// Message explains why the window is invalid.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Autoscalers are names of ReplicaAutoscalers currently selected by this window.
+	// +listType=atomic
+	// +optional
+	Autoscalers []string `json:"autoscalers,omitempty"`
+	// ExpireTime is the time when the date window will be deleted, it's end time plus retention.
+	// +optional
+	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=sw
+//+kubebuilder:printcolumn:name="Min",type=integer,JSONPath=`.spec.minReplicas`
+//+kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.spec.maxReplicas`
+//+kubebuilder:printcolumn:name="Start",type=string,JSONPath=`.spec.start`
+//+kubebuilder:printcolumn:name="End",type=string,JSONPath=`.spec.end`
+//+kubebuilder:printcolumn:name="Timezone",type=string,JSONPath=`.spec.timezone`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Autoscalers",type=string,JSONPath=`.status.autoscalers`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ScalingWindow is the Schema for the scalingwindows API, it's the first-class replacement of
+// annotation `wing.xscaling.dev/replica-patches`.
+type ScalingWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScalingWindowSpec   `json:"spec,omitempty"`
+	Status ScalingWindowStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ScalingWindowList contains a list of ScalingWindow
+type ScalingWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScalingWindow `json:"items"`
+}
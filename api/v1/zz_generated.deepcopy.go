@@ -22,9 +22,149 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalerPolicyStatus) DeepCopyInto(out *AutoscalerPolicyStatus) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxReplicasCeiling != nil {
+		in, out := &in.MaxReplicasCeiling, &out.MaxReplicasCeiling
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ReplicaAutoscalerStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exhaust != nil {
+		in, out := &in.Exhaust, &out.Exhaust
+		*out = new(Exhaust)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicatorSettings != nil {
+		in, out := &in.ReplicatorSettings, &out.ReplicatorSettings
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisallowedScalers != nil {
+		in, out := &in.DisallowedScalers, &out.DisallowedScalers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalerPolicyStatus.
+func (in *AutoscalerPolicyStatus) DeepCopy() *AutoscalerPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalerPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReplicaAutoscalerPolicy) DeepCopyInto(out *ClusterReplicaAutoscalerPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReplicaAutoscalerPolicy.
+func (in *ClusterReplicaAutoscalerPolicy) DeepCopy() *ClusterReplicaAutoscalerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReplicaAutoscalerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterReplicaAutoscalerPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReplicaAutoscalerPolicyList) DeepCopyInto(out *ClusterReplicaAutoscalerPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterReplicaAutoscalerPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReplicaAutoscalerPolicyList.
+func (in *ClusterReplicaAutoscalerPolicyList) DeepCopy() *ClusterReplicaAutoscalerPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReplicaAutoscalerPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterReplicaAutoscalerPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReplicaAutoscalerPolicySpec) DeepCopyInto(out *ClusterReplicaAutoscalerPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoscalerSelector != nil {
+		in, out := &in.AutoscalerSelector, &out.AutoscalerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(ReplicaAutoscalerPolicyDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(ReplicaAutoscalerPolicyLimits)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReplicaAutoscalerPolicySpec.
+func (in *ClusterReplicaAutoscalerPolicySpec) DeepCopy() *ClusterReplicaAutoscalerPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReplicaAutoscalerPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -77,6 +217,21 @@ func (in *CrossVersionObjectReference) DeepCopy() *CrossVersionObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecisionStep) DeepCopyInto(out *DecisionStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecisionStep.
+func (in *DecisionStep) DeepCopy() *DecisionStep {
+	if in == nil {
+		return nil
+	}
+	out := new(DecisionStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Exhaust) DeepCopyInto(out *Exhaust) {
 	*out = *in
@@ -113,6 +268,21 @@ func (in *ExhaustPending) DeepCopy() *ExhaustPending {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpiredSchedule) DeepCopyInto(out *ExpiredSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpiredSchedule.
+func (in *ExpiredSchedule) DeepCopy() *ExpiredSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpiredSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupVersionKindResource) DeepCopyInto(out *GroupVersionKindResource) {
 	*out = *in
@@ -128,6 +298,25 @@ func (in *GroupVersionKindResource) DeepCopy() *GroupVersionKindResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberStatus) DeepCopyInto(out *MemberStatus) {
+	*out = *in
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberStatus.
+func (in *MemberStatus) DeepCopy() *MemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTarget) DeepCopyInto(out *MetricTarget) {
 	*out = *in
@@ -217,6 +406,93 @@ func (in *ReplicaAutoscalerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaAutoscalerPolicyDefaults) DeepCopyInto(out *ReplicaAutoscalerPolicyDefaults) {
+	*out = *in
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ReplicaAutoscalerStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exhaust != nil {
+		in, out := &in.Exhaust, &out.Exhaust
+		*out = new(Exhaust)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicatorSettings != nil {
+		in, out := &in.ReplicatorSettings, &out.ReplicatorSettings
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]ReplicaAutoscalerPolicyTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaAutoscalerPolicyDefaults.
+func (in *ReplicaAutoscalerPolicyDefaults) DeepCopy() *ReplicaAutoscalerPolicyDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaAutoscalerPolicyDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaAutoscalerPolicyLimits) DeepCopyInto(out *ReplicaAutoscalerPolicyLimits) {
+	*out = *in
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinScalingColdDownSeconds != nil {
+		in, out := &in.MinScalingColdDownSeconds, &out.MinScalingColdDownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AllowedScalers != nil {
+		in, out := &in.AllowedScalers, &out.AllowedScalers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaAutoscalerPolicyLimits.
+func (in *ReplicaAutoscalerPolicyLimits) DeepCopy() *ReplicaAutoscalerPolicyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaAutoscalerPolicyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaAutoscalerPolicyTarget) DeepCopyInto(out *ReplicaAutoscalerPolicyTarget) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaAutoscalerPolicyTarget.
+func (in *ReplicaAutoscalerPolicyTarget) DeepCopy() *ReplicaAutoscalerPolicyTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaAutoscalerPolicyTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicaAutoscalerSpec) DeepCopyInto(out *ReplicaAutoscalerSpec) {
 	*out = *in
@@ -253,6 +529,18 @@ func (in *ReplicaAutoscalerSpec) DeepCopyInto(out *ReplicaAutoscalerSpec) {
 		*out = new(Exhaust)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]ScaleTargetMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReplicaBudget != nil {
+		in, out := &in.ReplicaBudget, &out.ReplicaBudget
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaAutoscalerSpec.
@@ -291,6 +579,45 @@ func (in *ReplicaAutoscalerStatus) DeepCopyInto(out *ReplicaAutoscalerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReplicaPatch != nil {
+		in, out := &in.ReplicaPatch, &out.ReplicaPatch
+		*out = new(ReplicaPatchStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(AutoscalerPolicyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaRamp != nil {
+		in, out := &in.ReplicaRamp, &out.ReplicaRamp
+		*out = new(ReplicaRampStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDecision != nil {
+		in, out := &in.LastDecision, &out.LastDecision
+		*out = new(ScalingDecision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpiredSchedules != nil {
+		in, out := &in.ExpiredSchedules, &out.ExpiredSchedules
+		*out = make([]ExpiredSchedule, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScalingHistory != nil {
+		in, out := &in.ScalingHistory, &out.ScalingHistory
+		*out = make([]ScalingRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]MemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaAutoscalerStatus.
@@ -344,9 +671,50 @@ func (in *ReplicaAutoscalerTarget) DeepCopy() *ReplicaAutoscalerTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaOverride) DeepCopyInto(out *ReplicaOverride) {
+	*out = *in
+	in.Until.DeepCopyInto(&out.Until)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaOverride.
+func (in *ReplicaOverride) DeepCopy() *ReplicaOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicaPatch) DeepCopyInto(out *ReplicaPatch) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Calendar != nil {
+		in, out := &in.Calendar, &out.Calendar
+		*out = new(ScheduleCalendar)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeadTime != nil {
+		in, out := &in.LeadTime, &out.LeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ScaleDownDelay != nil {
+		in, out := &in.ScaleDownDelay, &out.ScaleDownDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(ReplicaPatchRamp)
+		**out = **in
+	}
 	if in.RetentionSeconds != nil {
 		in, out := &in.RetentionSeconds, &out.RetentionSeconds
 		*out = new(int64)
@@ -364,6 +732,47 @@ func (in *ReplicaPatch) DeepCopy() *ReplicaPatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaPatchRamp) DeepCopyInto(out *ReplicaPatchRamp) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaPatchRamp.
+func (in *ReplicaPatchRamp) DeepCopy() *ReplicaPatchRamp {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaPatchRamp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaPatchStatus) DeepCopyInto(out *ReplicaPatchStatus) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(ReplicaPatchRamp)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaPatchStatus.
+func (in *ReplicaPatchStatus) DeepCopy() *ReplicaPatchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaPatchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ReplicaPatches) DeepCopyInto(out *ReplicaPatches) {
 	{
@@ -385,9 +794,244 @@ func (in ReplicaPatches) DeepCopy() ReplicaPatches {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaRampStatus) DeepCopyInto(out *ReplicaRampStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	out.ReplicaPatchRamp = in.ReplicaPatchRamp
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaRampStatus.
+func (in *ReplicaRampStatus) DeepCopy() *ReplicaRampStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaRampStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleTargetMember) DeepCopyInto(out *ScaleTargetMember) {
+	*out = *in
+	out.ScaleTargetRef = in.ScaleTargetRef
+	out.Ratio = in.Ratio.DeepCopy()
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTargetMember.
+func (in *ScaleTargetMember) DeepCopy() *ScaleTargetMember {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleTargetMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingDecision) DeepCopyInto(out *ScalingDecision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]DecisionStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingDecision.
+func (in *ScalingDecision) DeepCopy() *ScalingDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingRecord) DeepCopyInto(out *ScalingRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRecord.
+func (in *ScalingRecord) DeepCopy() *ScalingRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingWindow) DeepCopyInto(out *ScalingWindow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingWindow.
+func (in *ScalingWindow) DeepCopy() *ScalingWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingWindowList) DeepCopyInto(out *ScalingWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScalingWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingWindowList.
+func (in *ScalingWindowList) DeepCopy() *ScalingWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingWindowSpec) DeepCopyInto(out *ScalingWindowSpec) {
+	*out = *in
+	if in.AutoscalerNames != nil {
+		in, out := &in.AutoscalerNames, &out.AutoscalerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoscalerSelector != nil {
+		in, out := &in.AutoscalerSelector, &out.AutoscalerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ReplicaPatch.DeepCopyInto(&out.ReplicaPatch)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingWindowSpec.
+func (in *ScalingWindowSpec) DeepCopy() *ScalingWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingWindowStatus) DeepCopyInto(out *ScalingWindowStatus) {
+	*out = *in
+	if in.Autoscalers != nil {
+		in, out := &in.Autoscalers, &out.Autoscalers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpireTime != nil {
+		in, out := &in.ExpireTime, &out.ExpireTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingWindowStatus.
+func (in *ScalingWindowStatus) DeepCopy() *ScalingWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleCalendar) DeepCopyInto(out *ScheduleCalendar) {
+	*out = *in
+	if in.Dates != nil {
+		in, out := &in.Dates, &out.Dates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleCalendar.
+func (in *ScheduleCalendar) DeepCopy() *ScheduleCalendar {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleCalendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduleTargetSettings) DeepCopyInto(out *ScheduleTargetSettings) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Calendar != nil {
+		in, out := &in.Calendar, &out.Calendar
+		*out = new(ScheduleCalendar)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeadTime != nil {
+		in, out := &in.LeadTime, &out.LeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ScaleDownDelay != nil {
+		in, out := &in.ScaleDownDelay, &out.ScaleDownDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Settings != nil {
 		in, out := &in.Settings, &out.Settings
 		*out = new(runtime.RawExtension)
@@ -0,0 +1,284 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/scheduling"
+	"github.com/xscaling/wing/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// targetExplanation is the scheduled settings of a target
+type targetExplanation struct {
+	Metric string
+	// ScheduleIndex is index of the hit schedule of highest priority, -1 means default settings are used
+	ScheduleIndex int
+	Schedule      *wingv1.ScheduleTargetSettings
+	// ScheduleIndexes are indexes of hit schedules in order of priority
+	ScheduleIndexes []int
+	// Merged indicates settings of all hit schedules are merged
+	Merged   bool
+	Settings string
+	Error    error
+}
+
+func (t targetExplanation) schedule() string {
+	switch {
+	case t.Error != nil:
+		return "<error: " + t.Error.Error() + ">"
+	case t.Schedule == nil:
+		return "<default>"
+	}
+	if t.Merged {
+		indexes := make([]string, 0, len(t.ScheduleIndexes))
+		for _, index := range t.ScheduleIndexes {
+			indexes = append(indexes, fmt.Sprintf("#%d", index))
+		}
+		return "merged " + strings.Join(indexes, ", ")
+	}
+	return fmt.Sprintf("#%d %s%s", t.ScheduleIndex, formatPeriod(t.Schedule.Start,
+		formatPeriodEnd(t.Schedule.End, t.Schedule.Duration), t.Schedule.Timezone),
+		formatPeriodShift(t.Schedule.LeadTime, t.Schedule.ScaleDownDelay))
+}
+
+// explanation tells what the controller would apply to the autoscaler at given time,
+// it's made by the same utils and scheduling functions of controller.
+type explanation struct {
+	When time.Time
+
+	PausedUntil     *time.Time
+	ReplicaOverride *wingv1.ReplicaOverride
+
+	// Static autoscaler scales to max replicas and ignores replica patches
+	Static      bool
+	MinReplicas int32
+	MaxReplicas int32
+	// ReplicaPatchIndex is index of the working replica patch of highest priority in annotation patches
+	// followed by patches of selecting windows, -1 means no patch works
+	ReplicaPatchIndex int
+	ReplicaPatch      *wingv1.ReplicaPatch
+	// EffectiveReplicaPatch is combined from working patches by mode as controller does
+	EffectiveReplicaPatch *wingv1.ReplicaPatchStatus
+	// ReplicaPatchError makes controller fallback to the default scaling range
+	ReplicaPatchError error
+
+	Targets []targetExplanation
+}
+
+func explain(autoscaler wingv1.ReplicaAutoscaler, windows []wingv1.ScalingWindow, when time.Time) *explanation {
+	e := &explanation{
+		When:              when,
+		MaxReplicas:       autoscaler.Spec.MaxReplicas,
+		MinReplicas:       wingv1.DefaultMinReplicas,
+		ReplicaPatchIndex: -1,
+	}
+	// Broken pause annotations are ignored by controller
+	if pausedUntil, err := utils.GetPausedUntil(autoscaler); err == nil &&
+		pausedUntil != nil && when.Before(*pausedUntil) {
+		e.PausedUntil = pausedUntil
+	}
+	if replicaOverride, err := utils.GetReplicaOverride(autoscaler); err == nil &&
+		replicaOverride != nil && when.Before(replicaOverride.Until.Time) {
+		e.ReplicaOverride = replicaOverride
+	}
+
+	if autoscaler.Spec.MinReplicas == nil {
+		e.Static = true
+		e.MinReplicas = autoscaler.Spec.MaxReplicas
+	} else {
+		e.MinReplicas = *autoscaler.Spec.MinReplicas
+		patches, sources, err := scheduling.GetAutoscalerReplicaPatches(autoscaler, windows)
+		if err == nil {
+			e.EffectiveReplicaPatch, err = scheduling.GetEffectiveReplicaPatch(when, patches, sources,
+				autoscaler.Spec.ReplicaPatchMode)
+		}
+		if err != nil {
+			e.ReplicaPatchError = err
+		} else if e.EffectiveReplicaPatch != nil {
+			// Patches are valid here
+			e.ReplicaPatchIndex, _ = scheduling.GetReplicaPatchIndex(when, patches)
+			e.ReplicaPatch = &patches[e.ReplicaPatchIndex]
+			e.MinReplicas, e.MaxReplicas = e.EffectiveReplicaPatch.MinReplicas, e.EffectiveReplicaPatch.MaxReplicas
+		}
+	}
+
+	for _, target := range autoscaler.Spec.Targets {
+		t := targetExplanation{Metric: target.Metric, ScheduleIndex: -1}
+		if t.ScheduleIndexes, t.Error = scheduling.GetScheduleIndexes(when, target.Settings.Schedules); t.Error == nil {
+			if len(t.ScheduleIndexes) > 0 {
+				t.ScheduleIndex = t.ScheduleIndexes[0]
+				t.Schedule = &target.Settings.Schedules[t.ScheduleIndex]
+			}
+			t.Merged = target.Settings.MergeMode == wingv1.ScheduleMerge && len(t.ScheduleIndexes) > 1
+			var settings []byte
+			if settings, t.Error = scheduling.GetScheduledSettingsRaw(when, target.Settings); t.Error == nil {
+				t.Settings = string(settings)
+			}
+		}
+		e.Targets = append(e.Targets, t)
+	}
+	return e
+}
+
+// explain explains autoscaler with ScalingWindows in namespace, calendar ConfigMaps are resolved
+// on copies as controller does.
+func (p *plugin) explain(ctx context.Context, autoscaler *wingv1.ReplicaAutoscaler, when time.Time) (*explanation, error) {
+	windows, err := p.listScalingWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Calendars failed to resolve are reported in explanation as scheduling errors
+	scheduledAutoscaler := autoscaler.DeepCopy()
+	_ = utils.ResolveAutoscalerCalendars(ctx, p.client, scheduledAutoscaler)
+	for i := range windows {
+		_ = utils.ResolveScalingWindowCalendar(ctx, p.client, &windows[i])
+	}
+	return explain(*scheduledAutoscaler, windows, when), nil
+}
+
+func (e *explanation) pause() string {
+	switch {
+	case e.PausedUntil != nil:
+		return "paused until " + e.PausedUntil.Format(time.RFC3339)
+	case e.ReplicaOverride != nil:
+		return fmt.Sprintf("replicas overridden to %d until %s",
+			e.ReplicaOverride.Replicas, e.ReplicaOverride.Until.Format(time.RFC3339))
+	}
+	return "no"
+}
+
+func (e *explanation) replicaPatch() string {
+	switch {
+	case e.Static:
+		return "<ignored by static replicas>"
+	case e.ReplicaPatchError != nil:
+		return "<error: " + e.ReplicaPatchError.Error() + ", fallback to default>"
+	case e.ReplicaPatch == nil:
+		return "<none>"
+	case len(e.EffectiveReplicaPatch.Sources) > 1:
+		return fmt.Sprintf("%s [%d, %d] of %s", e.EffectiveReplicaPatch.Mode, e.EffectiveReplicaPatch.MinReplicas,
+			e.EffectiveReplicaPatch.MaxReplicas, strings.Join(e.EffectiveReplicaPatch.Sources, ", "))
+	}
+	return fmt.Sprintf("%s %s", e.EffectiveReplicaPatch.Sources[0], formatReplicaPatch(*e.ReplicaPatch))
+}
+
+func (e *explanation) scalingRange() string {
+	if e.Static {
+		return fmt.Sprintf("static %d", e.MaxReplicas)
+	}
+	return fmt.Sprintf("[%d, %d]", e.MinReplicas, e.MaxReplicas)
+}
+
+func formatPeriod(start, end, timezone string) string {
+	return fmt.Sprintf("%s ~ %s (%s)", start, end, timezone)
+}
+
+// formatPeriodEnd shows duration of window as `+15m0s` if it's not ended at end spec
+func formatPeriodEnd(end string, duration *metav1.Duration) string {
+	if duration != nil {
+		return "+" + duration.Duration.String()
+	}
+	return end
+}
+
+// formatPeriodShift shows lead time and scale-down delay of period as ` lead 5m0s, delay 10m0s` if any
+func formatPeriodShift(leadTime, scaleDownDelay *metav1.Duration) string {
+	var parts []string
+	if leadTime != nil && leadTime.Duration != 0 {
+		parts = append(parts, "lead "+leadTime.Duration.String())
+	}
+	if scaleDownDelay != nil && scaleDownDelay.Duration != 0 {
+		parts = append(parts, "delay "+scaleDownDelay.Duration.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, ", ")
+}
+
+func formatReplicaPatch(patch wingv1.ReplicaPatch) string {
+	return fmt.Sprintf("[%d, %d] %s%s", patch.MinReplicas, patch.MaxReplicas,
+		formatPeriod(patch.Start, formatPeriodEnd(patch.End, patch.Duration), patch.Timezone),
+		formatPeriodShift(patch.LeadTime, patch.ScaleDownDelay))
+}
+
+func runExplain(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing explain <name> [--at <time> | --after <duration>] [options]\n\n"+
+			"Explain which schedule of targets and which replica patch will apply at given time.\n\n")
+		fs.PrintDefaults()
+	}
+	var (
+		at    string
+		after time.Duration
+	)
+	fs.StringVar(&at, "at", "", "Time to explain in RFC3339 format, e.g. 2024-08-15T10:00:00+08:00. Defaults to now.")
+	fs.DurationVar(&after, "after", 0, "Explain at the time after duration from now, e.g. 2h.")
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	when := p.now()
+	switch {
+	case at != "" && after != 0:
+		return fmt.Errorf("%w: --at and --after are mutually exclusive", errUsage)
+	case at != "":
+		if when, err = time.Parse(time.RFC3339, at); err != nil {
+			return fmt.Errorf("%w: invalid --at: %v", errUsage, err)
+		}
+	default:
+		when = when.Add(after)
+	}
+	autoscaler, err := p.getAutoscaler(context.TODO(), arguments[0])
+	if err != nil {
+		return err
+	}
+	e, err := p.explain(context.TODO(), autoscaler, when)
+	if err != nil {
+		return err
+	}
+	return writeExplanation(p.out, e)
+}
+
+func writeExplanation(w io.Writer, e *explanation) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Time:\t%s\n", e.When.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Paused:\t%s\n", e.pause())
+	fmt.Fprintf(tw, "Replica patch:\t%s\n", e.replicaPatch())
+	fmt.Fprintf(tw, "Scaling range:\t%s\n", e.scalingRange())
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tSCHEDULE\tSETTINGS")
+	for _, target := range e.Targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", target.Metric, target.schedule(), target.Settings)
+	}
+	return tw.Flush()
+}
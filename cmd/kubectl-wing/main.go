@@ -0,0 +1,230 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-wing is a kubectl plugin for inspecting and operating ReplicaAutoscalers,
+// install it into PATH and run as `kubectl wing <command>`.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const usage = `kubectl wing inspects and operates ReplicaAutoscalers.
+
+Usage:
+  kubectl wing status <name>                      Show targets, schedules, replica patch, pause and panic state
+  kubectl wing patch list <name>                  List replica patches
+  kubectl wing patch add <name> [options]         Add a replica patch
+  kubectl wing patch remove <name> <index>        Remove a replica patch by index
+  kubectl wing pause <name> (--for | --until)     Pause autoscaling at current replicas
+  kubectl wing resume <name>                      Resume paused autoscaling
+  kubectl wing explain <name> (--at | --after)    Explain which schedule and patch apply at given time
+
+Global options:
+  --kubeconfig    Path to the kubeconfig file
+  --context       The name of the kubeconfig context to use
+  -n, --namespace Namespace of the ReplicaAutoscaler
+
+Run 'kubectl wing <command> --help' for options of a command.
+`
+
+var (
+	errUsage = errors.New("invalid usage")
+)
+
+// globalOptions are flags shared by all commands
+type globalOptions struct {
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+func (o *globalOptions) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	fs.StringVar(&o.context, "context", "", "The name of the kubeconfig context to use.")
+	fs.StringVar(&o.namespace, "namespace", "", "Namespace of the ReplicaAutoscaler.")
+	fs.StringVar(&o.namespace, "n", "", "Namespace of the ReplicaAutoscaler (shorthand).")
+}
+
+// plugin holds the client and context of a command run
+type plugin struct {
+	options   globalOptions
+	client    client.Client
+	namespace string
+	out       io.Writer
+	now       func() time.Time
+}
+
+// connect creates client to cluster by global options
+func (p *plugin) connect() error {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = p.options.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: p.options.context})
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	p.namespace = p.options.namespace
+	if p.namespace == "" {
+		if p.namespace, _, err = clientConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	scheme := runtime.NewScheme()
+	if err = clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err = wingv1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	p.client, err = client.New(restConfig, client.Options{Scheme: scheme})
+	return err
+}
+
+func (p *plugin) getAutoscaler(ctx context.Context, name string) (*wingv1.ReplicaAutoscaler, error) {
+	autoscaler := new(wingv1.ReplicaAutoscaler)
+	if err := p.client.Get(ctx, types.NamespacedName{Namespace: p.namespace, Name: name}, autoscaler); err != nil {
+		return nil, err
+	}
+	return autoscaler, nil
+}
+
+// listScalingWindows lists windows in namespace, nothing returned if ScalingWindow CRD is not installed
+func (p *plugin) listScalingWindows(ctx context.Context) ([]wingv1.ScalingWindow, error) {
+	windows := &wingv1.ScalingWindowList{}
+	if err := p.client.List(ctx, windows, client.InNamespace(p.namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return windows.Items, nil
+}
+
+// updateAutoscaler patches the autoscaler mutated by given function with optimistic lock,
+// and retries on conflict with the latest autoscaler, so that concurrent editing won't be overwritten.
+func (p *plugin) updateAutoscaler(ctx context.Context, name string,
+	mutate func(autoscaler *wingv1.ReplicaAutoscaler) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		autoscaler, err := p.getAutoscaler(ctx, name)
+		if err != nil {
+			return err
+		}
+		original := autoscaler.DeepCopy()
+		if err = mutate(autoscaler); err != nil {
+			return err
+		}
+		return p.client.Patch(ctx, autoscaler,
+			client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+type command struct {
+	name string
+	// run parses args with flag set which global options are bound and executes command
+	run func(p *plugin, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{name: "status", run: runStatus},
+	{name: "patch", run: runPatch},
+	{name: "pause", run: runPause},
+	{name: "resume", run: runResume},
+	{name: "explain", run: runExplain},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes plugin command and returns exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet("kubectl wing "+cmd.name, flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		// Client is created after parsing flags
+		p := &plugin{out: stdout, now: time.Now}
+		p.options.bindFlags(fs)
+		err := cmd.run(p, fs, args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			fs.Usage()
+			return 2
+		default:
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stderr, "Unknown command `%s`\n\n%s", args[0], usage)
+	return 2
+}
+
+// parseArgs parses flags interspersed with positional arguments like kubectl does,
+// and connects to cluster with global options if plugin has no client yet.
+func (p *plugin) parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var arguments []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		arguments = append(arguments, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(arguments) != positional {
+		return nil, fmt.Errorf("%w: expects %d argument(s) but got %d", errUsage, positional, len(arguments))
+	}
+	if p.client == nil {
+		if err := p.connect(); err != nil {
+			return nil, fmt.Errorf("unable to connect to cluster: %w", err)
+		}
+	}
+	return arguments, nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/scheduling"
+	"github.com/xscaling/wing/utils"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testNow = time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC)
+
+func newTestAutoscaler() *wingv1.ReplicaAutoscaler {
+	return &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			MinReplicas:    pointer.Int32(2),
+			MaxReplicas:    10,
+			Targets: []wingv1.ReplicaAutoscalerTarget{{
+				Metric: "cpu",
+				Settings: wingv1.TargetSettings{
+					Default: &runtime.RawExtension{Raw: []byte(`{"utilization":60}`)},
+					Schedules: []wingv1.ScheduleTargetSettings{{
+						Timezone: "UTC",
+						Start:    "0 8 * * *",
+						End:      "0 10 * * *",
+						Settings: &runtime.RawExtension{Raw: []byte(`{"utilization":40}`)},
+					}},
+				},
+			}},
+		},
+	}
+}
+
+func newTestPlugin(t *testing.T, autoscaler *wingv1.ReplicaAutoscaler) (*plugin, *bytes.Buffer) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, wingv1.AddToScheme(scheme))
+	out := new(bytes.Buffer)
+	return &plugin{
+		client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(autoscaler).Build(),
+		namespace: autoscaler.Namespace,
+		out:       out,
+		now:       func() time.Time { return testNow },
+	}, out
+}
+
+func runTestCommand(p *plugin, run func(p *plugin, fs *flag.FlagSet, args []string) error, args ...string) error {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	p.options.bindFlags(fs)
+	return run(p, fs, args)
+}
+
+func TestReplicaPatchCommands(t *testing.T) {
+	p, out := newTestPlugin(t, newTestAutoscaler())
+
+	require.NoError(t, runTestCommand(p, runPatch, "add", "web", "--start", "0 8 * * *", "--end", "0 12 * * *",
+		"--min", "5", "--max", "20"))
+	// Flags after positional arguments are accepted like kubectl does
+	require.NoError(t, runTestCommand(p, runPatch, "add", "web", "-n", "default",
+		"--start", "2024-08-15 08:30", "--end", "2024-08-15 09:30", "--min", "8", "--max", "30", "--index", "0", "--priority", "10"))
+	for _, invalidArgs := range [][]string{
+		{"add", "web", "--start", "0 8 * * *", "--end", "0 8 * * *", "--min", "1", "--max", "2"},
+		{"add", "web", "--start", "0 8 * * *", "--end", "2024-08-15 09:30", "--min", "1", "--max", "2"},
+		{"add", "web", "--start", "0 8 * * *", "--end", "0 9 * * *", "--min", "3", "--max", "2"},
+		{"add", "web", "--start", "0 8 * * *", "--end", "0 9 * * *", "--min", "1", "--max", "2", "--timezone", "Mars/Base"},
+		{"add", "web", "--start", "0 8 * * *", "--end", "0 9 * * *", "--min", "1", "--max", "2", "--index", "3"},
+		{"add", "web", "--start", "0 */2 * * *", "--end", "0 9 * * *", "--duration", "15m", "--min", "1", "--max", "2"},
+		{"add", "web", "--start", "2024-08-15 08:30", "--duration", "15m", "--min", "1", "--max", "2"},
+		{"remove", "web", "2"},
+		{"remove", "web"},
+	} {
+		require.Error(t, runTestCommand(p, runPatch, invalidArgs...), "%v", invalidArgs)
+	}
+
+	autoscaler, err := p.getAutoscaler(context.TODO(), "web")
+	require.NoError(t, err)
+	patches, err := utils.GetReplicaPatches(*autoscaler)
+	require.NoError(t, err)
+	require.Len(t, patches, 2)
+	require.Equal(t, int32(8), patches[0].MinReplicas)
+	require.Equal(t, int32(10), patches[0].Priority)
+	require.Equal(t, int32(5), patches[1].MinReplicas)
+	// Date patch of higher priority wins, same as controller
+	workingPatch, err := scheduling.GetReplicaPatch(testNow, patches)
+	require.NoError(t, err)
+	require.Equal(t, patches[0], *workingPatch)
+
+	out.Reset()
+	require.NoError(t, runTestCommand(p, runPatch, "list", "web"))
+	require.Contains(t, out.String(), "0      UTC       2024-08-15 08:30  2024-08-15 09:30  8    30   10        -          true")
+	require.Contains(t, out.String(), "1      UTC       0 8 * * *         0 12 * * *        5    20   0         -          false")
+
+	// Every patch combined is working
+	require.NoError(t, p.updateAutoscaler(context.TODO(), "web", func(autoscaler *wingv1.ReplicaAutoscaler) error {
+		autoscaler.Spec.ReplicaPatchMode = wingv1.ReplicaPatchWidest
+		return nil
+	}))
+	out.Reset()
+	require.NoError(t, runTestCommand(p, runPatch, "list", "web"))
+	require.Contains(t, out.String(), "0      UTC       2024-08-15 08:30  2024-08-15 09:30  8    30   10        -          true")
+	require.Contains(t, out.String(), "1      UTC       0 8 * * *         0 12 * * *        5    20   0         -          true")
+
+	require.NoError(t, runTestCommand(p, runPatch, "remove", "web", "0"))
+	require.NoError(t, runTestCommand(p, runPatch, "remove", "web", "0"))
+	autoscaler, err = p.getAutoscaler(context.TODO(), "web")
+	require.NoError(t, err)
+	require.NotContains(t, autoscaler.Annotations, wingv1.ReplicaPatchesAnnotation)
+}
+
+func TestReplicaPatchRefuseBrokenAnnotation(t *testing.T) {
+	autoscaler := newTestAutoscaler()
+	autoscaler.Annotations = map[string]string{wingv1.ReplicaPatchesAnnotation: "[{"}
+	p, _ := newTestPlugin(t, autoscaler)
+	require.Error(t, runTestCommand(p, runPatch, "add", "web", "--start", "0 8 * * *", "--end", "0 12 * * *",
+		"--min", "5", "--max", "20"))
+	autoscaler, err := p.getAutoscaler(context.TODO(), "web")
+	require.NoError(t, err)
+	require.Equal(t, "[{", autoscaler.Annotations[wingv1.ReplicaPatchesAnnotation])
+}
+
+func TestPauseAndResume(t *testing.T) {
+	p, _ := newTestPlugin(t, newTestAutoscaler())
+	require.Error(t, runTestCommand(p, runPause, "web"))
+	require.Error(t, runTestCommand(p, runPause, "web", "--until", "2024-08-15T08:00:00Z"))
+	require.NoError(t, runTestCommand(p, runPause, "web", "--for", "2h"))
+
+	autoscaler, err := p.getAutoscaler(context.TODO(), "web")
+	require.NoError(t, err)
+	pausedUntil, err := utils.GetPausedUntil(*autoscaler)
+	require.NoError(t, err)
+	require.True(t, testNow.Add(2*time.Hour).Equal(*pausedUntil))
+	require.Equal(t, "paused until 2024-08-15T11:00:00Z", explain(*autoscaler, nil, testNow).pause())
+	require.Equal(t, "no", explain(*autoscaler, nil, testNow.Add(2*time.Hour)).pause())
+
+	require.NoError(t, runTestCommand(p, runResume, "web"))
+	autoscaler, err = p.getAutoscaler(context.TODO(), "web")
+	require.NoError(t, err)
+	require.NotContains(t, autoscaler.Annotations, wingv1.PausedUntilAnnotation)
+}
+
+func TestExplain(t *testing.T) {
+	autoscaler := newTestAutoscaler()
+	require.NoError(t, utils.SetReplicaPatches(autoscaler, wingv1.ReplicaPatches{{
+		Timezone: "UTC", Start: "0 9 * * *", End: "0 11 * * *", MinReplicas: 4, MaxReplicas: 40,
+	}}))
+	for _, testCase := range []struct {
+		when time.Time
+
+		expectedScheduleIndex     int
+		expectedSettings          string
+		expectedReplicaPatchIndex int
+		expectedScalingRange      string
+	}{
+		{testNow.Add(-2 * time.Hour), -1, `{"utilization":60}`, -1, "[2, 10]"},
+		{testNow.Add(-30 * time.Minute), 0, `{"utilization":40}`, -1, "[2, 10]"},
+		{testNow, 0, `{"utilization":40}`, 0, "[4, 40]"},
+		{testNow.Add(90 * time.Minute), -1, `{"utilization":60}`, 0, "[4, 40]"},
+	} {
+		e := explain(*autoscaler, nil, testCase.when)
+		require.Len(t, e.Targets, 1)
+		require.NoError(t, e.Targets[0].Error)
+		require.Equal(t, testCase.expectedScheduleIndex, e.Targets[0].ScheduleIndex, "%s", testCase.when)
+		require.Equal(t, testCase.expectedSettings, e.Targets[0].Settings, "%s", testCase.when)
+		require.Equal(t, testCase.expectedReplicaPatchIndex, e.ReplicaPatchIndex, "%s", testCase.when)
+		require.Equal(t, testCase.expectedScalingRange, e.scalingRange(), "%s", testCase.when)
+	}
+
+	// Windows are applied after annotation patches
+	windows := []wingv1.ScalingWindow{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "promotion"},
+		Spec: wingv1.ScalingWindowSpec{
+			AutoscalerNames: []string{"web"},
+			ReplicaPatch: wingv1.ReplicaPatch{
+				Timezone: "UTC", Start: "2024-08-15 07:00", End: "2024-08-15 10:00", MinReplicas: 6, MaxReplicas: 60,
+			},
+		},
+	}}
+	e := explain(*autoscaler, windows, testNow)
+	require.Equal(t, 0, e.ReplicaPatchIndex)
+	require.Equal(t, []string{"annotation[0]"}, e.EffectiveReplicaPatch.Sources)
+	e = explain(*autoscaler, windows, testNow.Add(-time.Hour))
+	require.Equal(t, 1, e.ReplicaPatchIndex)
+	require.Equal(t, []string{"ScalingWindow/promotion"}, e.EffectiveReplicaPatch.Sources)
+	require.Equal(t, "[6, 60]", e.scalingRange())
+	require.Contains(t, e.replicaPatch(), "ScalingWindow/promotion")
+
+	// Broken patches fallback to default as controller does
+	autoscaler.Annotations[wingv1.ReplicaPatchesAnnotation] = `[{"timezone":"UTC","start":"0 9 * * *","end":"0 9 * * *"}]`
+	e = explain(*autoscaler, nil, testNow)
+	require.Error(t, e.ReplicaPatchError)
+	require.Equal(t, "[2, 10]", e.scalingRange())
+
+	// Static replicas ignore patches
+	autoscaler.Spec.MinReplicas = nil
+	require.Equal(t, "static 10", explain(*autoscaler, nil, testNow).scalingRange())
+
+	p, out := newTestPlugin(t, newTestAutoscaler())
+	require.NoError(t, runTestCommand(p, runExplain, "web", "--after", "3h"))
+	require.Contains(t, out.String(), "Time:           2024-08-15T12:00:00Z")
+	require.Contains(t, out.String(), `cpu     <default>  {"utilization":60}`)
+	require.Error(t, runTestCommand(p, runExplain, "web", "--after", "3h", "--at", "2024-08-15T12:00:00Z"))
+}
+
+func TestStatus(t *testing.T) {
+	autoscaler := newTestAutoscaler()
+	autoscaler.Status = wingv1.ReplicaAutoscalerStatus{
+		CurrentReplicas: 3,
+		DesiredReplicas: 4,
+		Targets: []wingv1.TargetStatus{{
+			Target: "cpu", Scaler: "cpu", DesiredReplicas: 4,
+			Metric: wingv1.MetricTarget{Type: wingv1.UtilizationMetricType, AverageUtilization: pointer.Int32(53)},
+		}},
+		Conditions: wingv1.SetConditionAt(nil, wingv1.Condition{
+			Type: wingv1.ConditionPanicMode, Status: metav1.ConditionTrue,
+		}, testNow.Add(-time.Minute)),
+	}
+	p, out := newTestPlugin(t, autoscaler)
+	require.NoError(t, runTestCommand(p, runStatus, "web"))
+	for _, expected := range []string{
+		"Name:             default/web",
+		"Replicas:         current 3, desired 4",
+		"Scaling range:    [2, 10]",
+		"Replica patch:    <none>",
+		"Panic mode:       True, since 2024-08-15T08:59:00Z (1m0s ago)",
+		`cpu     #0 0 8 * * * ~ 0 10 * * * (UTC)  {"utilization":40}`,
+		"cpu            cpu     Utilization=53%  4",
+	} {
+		require.Contains(t, out.String(), expected)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/scheduling"
+	"github.com/xscaling/wing/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func runPatch(p *plugin, fs *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: patch requires sub command add, list or remove", errUsage)
+	}
+	switch args[0] {
+	case "add":
+		return runPatchAdd(p, fs, args[1:])
+	case "list":
+		return runPatchList(p, fs, args[1:])
+	case "remove":
+		return runPatchRemove(p, fs, args[1:])
+	}
+	return fmt.Errorf("%w: unknown patch sub command `%s`", errUsage, args[0])
+}
+
+func runPatchList(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing patch list <name> [options]\n\n"+
+			"List replica patches in annotation and which one is working now. "+
+			"Patches of ScalingWindows are shown by `kubectl get scalingwindows`.\n\n")
+		fs.PrintDefaults()
+	}
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	autoscaler, err := p.getAutoscaler(context.TODO(), arguments[0])
+	if err != nil {
+		return err
+	}
+	patches, err := utils.GetReplicaPatches(*autoscaler)
+	if err != nil {
+		return fmt.Errorf("broken annotation %s: %w", wingv1.ReplicaPatchesAnnotation, err)
+	}
+	e, err := p.explain(context.TODO(), autoscaler, p.now())
+	if err != nil {
+		return err
+	}
+	// Several patches may take effect together depending on replica patch mode
+	workingSources := make(map[string]bool)
+	if e.EffectiveReplicaPatch != nil {
+		for _, source := range e.EffectiveReplicaPatch.Sources {
+			workingSources[source] = true
+		}
+	}
+
+	tw := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tTIMEZONE\tSTART\tEND\tMIN\tMAX\tPRIORITY\tRETENTION\tWORKING")
+	for index, patch := range patches {
+		retention := "-"
+		if patch.RetentionSeconds != nil {
+			retention = fmt.Sprintf("%ds", *patch.RetentionSeconds)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%d\t%s\t%v\n", index, patch.Timezone, patch.Start,
+			formatPeriodEnd(patch.End, patch.Duration), patch.MinReplicas, patch.MaxReplicas, patch.Priority,
+			retention, workingSources[fmt.Sprintf("annotation[%d]", index)])
+	}
+	return tw.Flush()
+}
+
+func runPatchAdd(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing patch add <name> --start <start> (--end <end> | --duration <duration>) "+
+			"--min <replicas> --max <replicas> [options]\n\n"+
+			"Add a replica patch which changes scaling range within the period. "+
+			"Start and end are both cron expressions or both dates in `2006-01-02 15:04` format, "+
+			"or the period lasts for duration from each start of cron expression.\n"+
+			"Among working patches the one with higher priority wins, or the earlier one for the same priority, "+
+			"unless replicaPatchMode of the autoscaler combines them. "+
+			"The new patch is appended unless --index is given.\n\n")
+		fs.PrintDefaults()
+	}
+	var (
+		patch            wingv1.ReplicaPatch
+		minReplicas      int
+		maxReplicas      int
+		retentionSeconds int64
+		index            int
+		priority         int
+		duration         time.Duration
+		leadTime         time.Duration
+		scaleDownDelay   time.Duration
+		rampDuration     time.Duration
+		rampSteps        int
+	)
+	fs.StringVar(&patch.Timezone, "timezone", "UTC", "Working timezone of the patch, e.g. Asia/Shanghai.")
+	fs.StringVar(&patch.Start, "start", "", "Start of the patch period.")
+	fs.StringVar(&patch.End, "end", "", "End of the patch period.")
+	fs.DurationVar(&duration, "duration", 0, "Duration of the patch period from each cron start, e.g. 15m.")
+	fs.DurationVar(&leadTime, "lead-time", 0, "Activate the patch earlier than each start for warming up, e.g. 5m.")
+	fs.DurationVar(&scaleDownDelay, "scale-down-delay", 0, "Keep the patch working after each end for the delay.")
+	fs.DurationVar(&rampDuration, "ramp", 0, "Ramp scaling range gradually over the duration on entering and exiting.")
+	fs.IntVar(&rampSteps, "ramp-steps", 0, "Ramp in equal steps rather than linearly.")
+	fs.IntVar(&minReplicas, "min", -1, "Min replicas within the period.")
+	fs.IntVar(&maxReplicas, "max", -1, "Max replicas within the period.")
+	fs.Int64Var(&retentionSeconds, "retention-seconds", -1,
+		"Seconds to hold a date patch after its end before purged by controller.")
+	fs.IntVar(&index, "index", -1, "Insert the patch at given index rather than append.")
+	fs.IntVar(&priority, "priority", 0, "Priority of the patch, the higher one wins among working patches.")
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	patch.MinReplicas, patch.MaxReplicas = int32(minReplicas), int32(maxReplicas)
+	patch.Priority = int32(priority)
+	if retentionSeconds >= 0 {
+		patch.RetentionSeconds = &retentionSeconds
+	}
+	if duration != 0 {
+		patch.Duration = &metav1.Duration{Duration: duration}
+	}
+	if leadTime != 0 {
+		patch.LeadTime = &metav1.Duration{Duration: leadTime}
+	}
+	if scaleDownDelay != 0 {
+		patch.ScaleDownDelay = &metav1.Duration{Duration: scaleDownDelay}
+	}
+	if rampDuration != 0 || rampSteps != 0 {
+		patch.Ramp = &wingv1.ReplicaPatchRamp{
+			Duration: metav1.Duration{Duration: rampDuration},
+			Steps:    int32(rampSteps),
+		}
+	}
+	if err = scheduling.ValidateReplicaPatch(patch); err != nil {
+		return fmt.Errorf("invalid replica patch: %w", err)
+	}
+
+	var insertAt int
+	if err = p.updateAutoscaler(context.TODO(), arguments[0], func(autoscaler *wingv1.ReplicaAutoscaler) error {
+		patches, err := utils.GetReplicaPatches(*autoscaler)
+		if err != nil {
+			return fmt.Errorf("refuse to overwrite broken annotation %s: %w", wingv1.ReplicaPatchesAnnotation, err)
+		}
+		insertAt = len(patches)
+		if index >= 0 {
+			if index > len(patches) {
+				return fmt.Errorf("index %d out of range [0, %d]", index, len(patches))
+			}
+			insertAt = index
+		}
+		patches = append(patches[:insertAt], append(wingv1.ReplicaPatches{patch}, patches[insertAt:]...)...)
+		return utils.SetReplicaPatches(autoscaler, patches)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "Replica patch #%d %s added to %s/%s\n",
+		insertAt, formatReplicaPatch(patch), p.namespace, arguments[0])
+	return nil
+}
+
+func runPatchRemove(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing patch remove <name> <index> [options]\n\n"+
+			"Remove a replica patch by index shown in `kubectl wing patch list`.\n\n")
+		fs.PrintDefaults()
+	}
+	arguments, err := p.parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	index, err := strconv.Atoi(arguments[1])
+	if err != nil {
+		return fmt.Errorf("%w: invalid index `%s`", errUsage, arguments[1])
+	}
+
+	var removed wingv1.ReplicaPatch
+	if err = p.updateAutoscaler(context.TODO(), arguments[0], func(autoscaler *wingv1.ReplicaAutoscaler) error {
+		patches, err := utils.GetReplicaPatches(*autoscaler)
+		if err != nil {
+			return fmt.Errorf("refuse to overwrite broken annotation %s: %w", wingv1.ReplicaPatchesAnnotation, err)
+		}
+		if index < 0 || index >= len(patches) {
+			return fmt.Errorf("index %d out of range, %d replica patch(es) found", index, len(patches))
+		}
+		removed = patches[index]
+		return utils.SetReplicaPatches(autoscaler, append(patches[:index], patches[index+1:]...))
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "Replica patch #%d %s removed from %s/%s\n",
+		index, formatReplicaPatch(removed), p.namespace, arguments[0])
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+)
+
+func runPause(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing pause <name> (--for <duration> | --until <time>) [options]\n\n"+
+			"Freeze the autoscaler at current replicas until given time, it resumes automatically after expiry.\n\n")
+		fs.PrintDefaults()
+	}
+	var (
+		until    string
+		duration time.Duration
+	)
+	fs.DurationVar(&duration, "for", 0, "Pause for the duration from now, e.g. 2h.")
+	fs.StringVar(&until, "until", "", "Pause until the time in RFC3339 format, e.g. 2024-08-15T10:00:00+08:00.")
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	var pausedUntil time.Time
+	switch {
+	case (until == "") == (duration == 0):
+		return fmt.Errorf("%w: exactly one of --for and --until is required", errUsage)
+	case until != "":
+		if pausedUntil, err = time.Parse(time.RFC3339, until); err != nil {
+			return fmt.Errorf("%w: invalid --until: %v", errUsage, err)
+		}
+	default:
+		pausedUntil = p.now().Add(duration)
+	}
+	if !pausedUntil.After(p.now()) {
+		return fmt.Errorf("%w: pause time %s is not in the future", errUsage, pausedUntil.Format(time.RFC3339))
+	}
+
+	if err = p.updateAutoscaler(context.TODO(), arguments[0], func(autoscaler *wingv1.ReplicaAutoscaler) error {
+		if autoscaler.Annotations == nil {
+			autoscaler.Annotations = make(map[string]string)
+		}
+		autoscaler.Annotations[wingv1.PausedUntilAnnotation] = pausedUntil.Format(time.RFC3339)
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "%s/%s paused until %s\n", p.namespace, arguments[0], pausedUntil.Format(time.RFC3339))
+	return nil
+}
+
+func runResume(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing resume <name> [options]\n\n"+
+			"Resume paused autoscaler. Replica override is kept unless --clear-override is set.\n\n")
+		fs.PrintDefaults()
+	}
+	var clearOverride bool
+	fs.BoolVar(&clearOverride, "clear-override", false, "Also remove replica override annotation.")
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err = p.updateAutoscaler(context.TODO(), arguments[0], func(autoscaler *wingv1.ReplicaAutoscaler) error {
+		delete(autoscaler.Annotations, wingv1.PausedUntilAnnotation)
+		if clearOverride {
+			delete(autoscaler.Annotations, wingv1.OverrideReplicasAnnotation)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "%s/%s resumed\n", p.namespace, arguments[0])
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/scheduling"
+)
+
+func runStatus(p *plugin, fs *flag.FlagSet, args []string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl wing status <name> [options]\n\n"+
+			"Show per-target metrics, desired replicas, active schedule, active replica patch, "+
+			"pause, panic and exhaust state of a ReplicaAutoscaler.\n\n")
+		fs.PrintDefaults()
+	}
+	arguments, err := p.parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	autoscaler, err := p.getAutoscaler(context.TODO(), arguments[0])
+	if err != nil {
+		return err
+	}
+	now := p.now()
+	e, err := p.explain(context.TODO(), autoscaler, now)
+	if err != nil {
+		return err
+	}
+	return writeStatus(p.out, *autoscaler, e, now)
+}
+
+func writeStatus(w io.Writer, autoscaler wingv1.ReplicaAutoscaler, explanation *explanation, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	ref := autoscaler.Spec.ScaleTargetRef
+	fmt.Fprintf(tw, "Name:\t%s/%s\n", autoscaler.Namespace, autoscaler.Name)
+	fmt.Fprintf(tw, "Scale target:\t%s/%s\n", ref.Kind, ref.Name)
+	for _, member := range autoscaler.Status.Members {
+		fmt.Fprintf(tw, "Member:\t%s\n", formatMember(member))
+	}
+	if budget := autoscaler.Spec.ReplicaBudget; budget != nil {
+		fmt.Fprintf(tw, "Replica budget:\t%d\n", *budget)
+	}
+	fmt.Fprintf(tw, "Replicas:\tcurrent %d, desired %d\n",
+		autoscaler.Status.CurrentReplicas, autoscaler.Status.DesiredReplicas)
+	fmt.Fprintf(tw, "Scaling range:\t%s\n", explanation.scalingRange())
+	fmt.Fprintf(tw, "Replica patch:\t%s\n", explanation.replicaPatch())
+	if ramp := autoscaler.Status.ReplicaRamp; ramp != nil {
+		minReplicas, maxReplicas := scheduling.GetRampedReplicaRange(now, *ramp)
+		fmt.Fprintf(tw, "Replica ramp:\t[%d, %d] towards [%d, %d] until %s\n", minReplicas, maxReplicas,
+			ramp.ToMinReplicas, ramp.ToMaxReplicas, ramp.StartTime.Add(ramp.Duration.Duration).Format(time.RFC3339))
+	}
+	if policy := autoscaler.Status.Policy; policy != nil {
+		fmt.Fprintf(tw, "Policies:\t%s\n", formatPolicy(*policy))
+	}
+	fmt.Fprintf(tw, "Paused:\t%s\n", explanation.pause())
+	fmt.Fprintf(tw, "Panic mode:\t%s\n",
+		formatCondition(wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionPanicMode), now))
+	fmt.Fprintf(tw, "Exhausted:\t%s\n",
+		formatCondition(wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionExhausted), now))
+	fmt.Fprintf(tw, "Ready:\t%s\n",
+		formatCondition(wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionReady), now))
+	lastScaleTime := "<none>"
+	if autoscaler.Status.LastScaleTime != nil {
+		lastScaleTime = formatTimeSince(autoscaler.Status.LastScaleTime.Time, now)
+	}
+	fmt.Fprintf(tw, "Last scale time:\t%s\n", lastScaleTime)
+	if decision := autoscaler.Status.LastDecision; decision != nil {
+		fmt.Fprintf(tw, "Last decision:\t%d -> %d, %s\n",
+			decision.CurrentReplicas, decision.DesiredReplicas, engine.SummarizeDecisionSteps(decision.Steps))
+	}
+	for _, schedule := range autoscaler.Status.ExpiredSchedules {
+		fmt.Fprintf(tw, "Expired schedule:\t%s #%d ended at %s\n", schedule.Target, schedule.Index, schedule.End)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tSCHEDULE\tSETTINGS")
+	for _, target := range explanation.Targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", target.Metric, target.schedule(), target.Settings)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET STATUS\tSCALER\tMETRIC\tDESIRED")
+	for _, status := range autoscaler.Status.Targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", status.Target, status.Scaler,
+			formatMetricTarget(status.Metric), status.DesiredReplicas)
+	}
+	return tw.Flush()
+}
+
+func formatCondition(condition wingv1.Condition, now time.Time) string {
+	if condition.Type == "" {
+		return "<unknown>"
+	}
+	parts := []string{string(condition.Status)}
+	if !condition.LastTransitionTime.IsZero() {
+		parts = append(parts, "since "+formatTimeSince(condition.LastTransitionTime.Time, now))
+	}
+	if condition.Reason != "" {
+		parts = append(parts, condition.Reason)
+	}
+	if condition.Message != "" {
+		parts = append(parts, condition.Message)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatTimeSince(t, now time.Time) string {
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), now.Sub(t).Truncate(time.Second))
+}
+
+func formatMetricTarget(metric wingv1.MetricTarget) string {
+	switch {
+	case metric.AverageUtilization != nil:
+		return fmt.Sprintf("%s=%d%%", metric.Type, *metric.AverageUtilization)
+	case metric.AverageValue != nil:
+		return fmt.Sprintf("%s=%s", metric.Type, metric.AverageValue.String())
+	case metric.Value != nil:
+		return fmt.Sprintf("%s=%s", metric.Type, metric.Value.String())
+	}
+	return "<unknown>"
+}
+
+func formatPolicy(policy wingv1.AutoscalerPolicyStatus) string {
+	limits := []string{fmt.Sprintf("cold-down %ds", policy.ScalingColdDownSeconds)}
+	if policy.MaxReplicasCeiling != nil {
+		limits = append(limits, fmt.Sprintf("max replicas ceiling %d", *policy.MaxReplicasCeiling))
+	}
+	if len(policy.DisallowedScalers) > 0 {
+		limits = append(limits, fmt.Sprintf("disallowed scalers %s", strings.Join(policy.DisallowedScalers, ",")))
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(policy.Policies, ","), strings.Join(limits, ", "))
+}
+
+func formatMember(member wingv1.MemberStatus) string {
+	formatted := fmt.Sprintf("%s/%s current %d, desired %d", member.Kind, member.Name,
+		member.CurrentReplicas, member.DesiredReplicas)
+	if member.Message != "" {
+		formatted += " (" + member.Message + ")"
+	}
+	return formatted
+}
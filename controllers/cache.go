@@ -0,0 +1,191 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xscaling/wing/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewCache returns the constructor of manager cache which restricts all objects to watch namespaces
+// and scopes pods by config, other objects are cached with options given by manager.
+// The cache is shared by controller and engine, so there is only one watch per resource.
+func NewCache(watchNamespaces []string, podCacheConfig PodCacheConfig) (cache.NewCacheFunc, error) {
+	podSelector := cache.ObjectSelector{}
+	if podCacheConfig.LabelSelector != "" {
+		selector, err := labels.Parse(podCacheConfig.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod label selector `%s`: %w", podCacheConfig.LabelSelector, err)
+		}
+		podSelector.Label = selector
+	}
+	var fieldSelectors []fields.Selector
+	for _, namespace := range podCacheConfig.ExcludedNamespaces {
+		fieldSelectors = append(fieldSelectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+	if len(fieldSelectors) > 0 {
+		podSelector.Field = fields.AndSelectors(fieldSelectors...)
+	}
+	podNamespaces, err := getPodNamespaces(watchNamespaces, podCacheConfig.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		selectorsByObject := cache.SelectorsByObject{&corev1.Pod{}: podSelector}
+		for object, selector := range opts.SelectorsByObject {
+			selectorsByObject[object] = selector
+		}
+		opts.SelectorsByObject = selectorsByObject
+		if podCacheConfig.StripUnusedFields {
+			transformByObject := cache.TransformByObject{&corev1.Pod{}: utils.StripPod}
+			for object, transform := range opts.TransformByObject {
+				transformByObject[object] = transform
+			}
+			opts.TransformByObject = transformByObject
+		}
+
+		defaultCache, err := newNamespacedCache(watchNamespaces)(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		if podNamespaces == nil {
+			return defaultCache, nil
+		}
+		podCache, err := newNamespacedCache(podNamespaces)(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &podScopedCache{Cache: defaultCache, pods: podCache}, nil
+	}, nil
+}
+
+// getPodNamespaces returns allowed pod namespaces within watch namespaces,
+// nil means pods share the same namespaces with other objects.
+func getPodNamespaces(watchNamespaces, allowedNamespaces []string) ([]string, error) {
+	if len(allowedNamespaces) == 0 {
+		return nil, nil
+	}
+	if len(watchNamespaces) == 0 {
+		return allowedNamespaces, nil
+	}
+	watching := sets.NewString(watchNamespaces...)
+	podNamespaces := watching.Intersection(sets.NewString(allowedNamespaces...))
+	if podNamespaces.Len() == 0 {
+		return nil, fmt.Errorf("none of pod cache namespaces %v is watched", allowedNamespaces)
+	}
+	if podNamespaces.Equal(watching) {
+		return nil, nil
+	}
+	return podNamespaces.List(), nil
+}
+
+func newNamespacedCache(namespaces []string) cache.NewCacheFunc {
+	switch len(namespaces) {
+	case 0:
+		return cache.New
+	case 1:
+		return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+			opts.Namespace = namespaces[0]
+			return cache.New(config, opts)
+		}
+	}
+	return cache.MultiNamespacedCacheBuilder(namespaces)
+}
+
+var podGVK = corev1.SchemeGroupVersion.WithKind("Pod")
+
+// podScopedCache serves pods from a dedicated cache restricted to allowed namespaces,
+// since field selectors are not able to match a set of namespaces.
+type podScopedCache struct {
+	cache.Cache
+	pods cache.Cache
+}
+
+var _ cache.Cache = &podScopedCache{}
+
+func isPodObject(object runtime.Object) bool {
+	switch object.(type) {
+	case *corev1.Pod, *corev1.PodList:
+		return true
+	}
+	return false
+}
+
+func (c *podScopedCache) Get(ctx context.Context, key runtimeclient.ObjectKey,
+	object runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+	if isPodObject(object) {
+		return c.pods.Get(ctx, key, object, opts...)
+	}
+	return c.Cache.Get(ctx, key, object, opts...)
+}
+
+func (c *podScopedCache) List(ctx context.Context, list runtimeclient.ObjectList, opts ...runtimeclient.ListOption) error {
+	if isPodObject(list) {
+		return c.pods.List(ctx, list, opts...)
+	}
+	return c.Cache.List(ctx, list, opts...)
+}
+
+func (c *podScopedCache) GetInformer(ctx context.Context, object runtimeclient.Object) (cache.Informer, error) {
+	if isPodObject(object) {
+		return c.pods.GetInformer(ctx, object)
+	}
+	return c.Cache.GetInformer(ctx, object)
+}
+
+func (c *podScopedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	if gvk == podGVK {
+		return c.pods.GetInformerForKind(ctx, gvk)
+	}
+	return c.Cache.GetInformerForKind(ctx, gvk)
+}
+
+func (c *podScopedCache) IndexField(ctx context.Context, object runtimeclient.Object,
+	field string, extractValue runtimeclient.IndexerFunc) error {
+	if isPodObject(object) {
+		return c.pods.IndexField(ctx, object, field, extractValue)
+	}
+	return c.Cache.IndexField(ctx, object, field, extractValue)
+}
+
+func (c *podScopedCache) Start(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.pods.Start(ctx)
+	}()
+	if err := c.Cache.Start(ctx); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func (c *podScopedCache) WaitForCacheSync(ctx context.Context) bool {
+	return c.Cache.WaitForCacheSync(ctx) && c.pods.WaitForCacheSync(ctx)
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package controllers
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/xscaling/wing/utils"
 )
 
@@ -25,15 +28,210 @@ type Config struct {
 }
 
 type ReplicaAutoscalerControllerConfig struct {
-	Workers int                             `yaml:"workers"`
-	Plugins map[string]utils.YamlRawMessage `yaml:"plugins"`
+	Workers        int                             `yaml:"workers"`
+	Plugins        map[string]utils.YamlRawMessage `yaml:"plugins"`
+	ScalingHistory ScalingHistoryConfig            `yaml:"scalingHistory"`
+	Conflict       ConflictConfig                  `yaml:"conflict"`
+	Watch          WatchConfig                     `yaml:"watch"`
+	PodCache       PodCacheConfig                  `yaml:"podCache"`
+	Quota          QuotaConfig                     `yaml:"quota"`
+	Sharding       ShardingConfig                  `yaml:"sharding"`
+	PluginHealth   PluginHealthConfig              `yaml:"pluginHealth"`
+	Metrics        MetricsConfig                   `yaml:"metrics"`
+	Tracing        TracingConfig                   `yaml:"tracing"`
+}
+
+// TracingConfig exports OpenTelemetry traces of reconciles via OTLP over HTTP
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint is host and port of OTLP/HTTP receiver, e.g. `otel-collector:4318`
+	Endpoint string `yaml:"endpoint"`
+	// URLPath overrides the default `/v1/traces`
+	URLPath string `yaml:"urlPath"`
+	// Insecure sends traces over HTTP instead of HTTPS
+	Insecure bool `yaml:"insecure"`
+	// Headers are sent along with traces, e.g. authentication
+	Headers map[string]string `yaml:"headers"`
+	// SampleRatio is the ratio of reconciles to sample
+	SampleRatio float64 `yaml:"sampleRatio"`
+	// ServiceName identifies controller in traces
+	ServiceName string `yaml:"serviceName"`
+}
+
+type MetricsLabelLevel string
+
+const (
+	// MetricsLabelLevelAutoscaler labels metrics with namespace and name of autoscaler
+	MetricsLabelLevelAutoscaler MetricsLabelLevel = "autoscaler"
+	// MetricsLabelLevelNamespace labels metrics with namespace of autoscaler only
+	MetricsLabelLevelNamespace MetricsLabelLevel = "namespace"
+	// MetricsLabelLevelNone aggregates metrics of all autoscalers
+	MetricsLabelLevelNone MetricsLabelLevel = "none"
+)
+
+// MetricsConfig controls label cardinality of controller metrics
+type MetricsConfig struct {
+	// LabelLevel decides autoscaler labels of metrics, per autoscaler gauges are exported on `autoscaler` level only
+	LabelLevel MetricsLabelLevel `yaml:"labelLevel"`
+	// TargetMetrics exports metric values of targets mirroring `.status.targets`
+	TargetMetrics bool `yaml:"targetMetrics"`
+}
+
+func (c MetricsConfig) Validate() error {
+	switch c.LabelLevel {
+	case MetricsLabelLevelAutoscaler, MetricsLabelLevelNamespace, MetricsLabelLevelNone:
+		return nil
+	}
+	return fmt.Errorf("unknown metrics label level `%s`", c.LabelLevel)
+}
+
+// PluginHealthConfig is the config of checking health of plugins implementing engine.HealthChecker,
+// controller is not ready while any plugin is unhealthy.
+type PluginHealthConfig struct {
+	// Interval of checking plugins, zero disables checking
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of checking each plugin
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ShardingConfig enables sharding autoscalers among controller replicas by hash ranges coordinated through leases.
+// Leader election is disabled when sharding is enabled.
+type ShardingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Group is the name of sharding group, members of the same group share autoscalers
+	Group string `yaml:"group"`
+	// Namespace of member leases, defaults to namespace of controller pod
+	Namespace string `yaml:"namespace"`
+	// LeaseDuration is how long a member is regarded as alive since its last renewal
+	LeaseDuration time.Duration `yaml:"leaseDuration"`
+	// RenewInterval is the interval of renewing lease and refreshing members
+	RenewInterval time.Duration `yaml:"renewInterval"`
+	// HandoverDelay is the delay before working on newly acquired autoscalers,
+	// it should be longer than RenewInterval to make sure previous owner has handed over.
+	HandoverDelay time.Duration `yaml:"handoverDelay"`
+}
+
+// QuotaConfig limits autoscalers and replicas managed by Wing per namespace
+type QuotaConfig struct {
+	// Default applies to namespaces not listed in Namespaces
+	Default NamespaceQuota `yaml:"default"`
+	// Namespaces overrides quota of specific namespaces
+	Namespaces map[string]NamespaceQuota `yaml:"namespaces"`
+}
+
+type NamespaceQuota struct {
+	// MaxAutoscalers is the max count of working autoscalers, earliest created ones are working.
+	// Zero means unlimited.
+	MaxAutoscalers int `yaml:"maxAutoscalers"`
+	// MaxReplicas is the max total replicas of all autoscalers to scale up to. Zero means unlimited.
+	MaxReplicas int32 `yaml:"maxReplicas"`
 }
 
+func (c QuotaConfig) Get(namespace string) NamespaceQuota {
+	if quota, ok := c.Namespaces[namespace]; ok {
+		return quota
+	}
+	return c.Default
+}
+
+// PodCacheConfig scopes pods cached by controller, pods out of scope are invisible to scalers and exhaust detection
+type PodCacheConfig struct {
+	// Namespaces is the allow list of namespaces, empty means all namespaces
+	Namespaces []string `yaml:"namespaces"`
+	// ExcludedNamespaces is the deny list of namespaces
+	ExcludedNamespaces []string `yaml:"excludedNamespaces"`
+	// LabelSelector restricts cached pods by labels
+	LabelSelector string `yaml:"labelSelector"`
+	// StripUnusedFields strips pod fields not used by Wing before caching
+	StripUnusedFields bool `yaml:"stripUnusedFields"`
+}
+
+// WatchConfig is the config of watching scale targets and pods to trigger reconciles on change,
+// changes out of watching are noticed on next requeue.
+type WatchConfig struct {
+	// ScaleTargets are kinds of scale target watched by metadata-only informers
+	ScaleTargets []WatchedKind `yaml:"scaleTargets"`
+	// Pods enables watching pods of targets for exhaust detection
+	Pods bool `yaml:"pods"`
+	// EnqueueDelay debounces bursts of changes into one reconcile
+	EnqueueDelay time.Duration `yaml:"enqueueDelay"`
+}
+
+type WatchedKind struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+}
+
+// ConflictConfig is the config of detecting other autoscalers working on the same scale target
+type ConflictConfig struct {
+	// DetectScaledObjects enables detecting KEDA ScaledObjects, which requires listing them from API server.
+	DetectScaledObjects bool `yaml:"detectScaledObjects"`
+}
+
+// ScalingHistoryConfig is the garbage collection policy of `.status.scalingHistory`
+type ScalingHistoryConfig struct {
+	// Limit is the max count of records kept, zero or negative means disabled
+	Limit int `yaml:"limit"`
+	// Retention is the max age of records kept, zero means records never expire
+	Retention time.Duration `yaml:"retention"`
+}
+
+const (
+	DefaultScalingHistoryLimit     = 10
+	DefaultScalingHistoryRetention = 24 * time.Hour
+
+	DefaultWatchEnqueueDelay = 2 * time.Second
+
+	DefaultShardingGroup         = "wing"
+	DefaultShardingLeaseDuration = 15 * time.Second
+	DefaultShardingRenewInterval = 5 * time.Second
+	DefaultShardingHandoverDelay = 10 * time.Second
+
+	DefaultPluginHealthInterval = 30 * time.Second
+	DefaultPluginHealthTimeout  = 5 * time.Second
+
+	DefaultTracingSampleRatio = 0.1
+	DefaultTracingServiceName = "wing"
+)
+
 func NewDefaultConfig() *Config {
 	return &Config{
 		ReplicaAutoscalerControllerConfig: ReplicaAutoscalerControllerConfig{
 			Workers: 3,
 			Plugins: make(map[string]utils.YamlRawMessage),
+			ScalingHistory: ScalingHistoryConfig{
+				Limit:     DefaultScalingHistoryLimit,
+				Retention: DefaultScalingHistoryRetention,
+			},
+			Watch: WatchConfig{
+				ScaleTargets: []WatchedKind{
+					{APIVersion: "apps/v1", Kind: "Deployment"},
+					{APIVersion: "apps/v1", Kind: "StatefulSet"},
+				},
+				Pods:         true,
+				EnqueueDelay: DefaultWatchEnqueueDelay,
+			},
+			PodCache: PodCacheConfig{
+				StripUnusedFields: true,
+			},
+			Sharding: ShardingConfig{
+				Group:         DefaultShardingGroup,
+				LeaseDuration: DefaultShardingLeaseDuration,
+				RenewInterval: DefaultShardingRenewInterval,
+				HandoverDelay: DefaultShardingHandoverDelay,
+			},
+			PluginHealth: PluginHealthConfig{
+				Interval: DefaultPluginHealthInterval,
+				Timeout:  DefaultPluginHealthTimeout,
+			},
+			Metrics: MetricsConfig{
+				LabelLevel:    MetricsLabelLevelAutoscaler,
+				TargetMetrics: true,
+			},
+			Tracing: TracingConfig{
+				SampleRatio: DefaultTracingSampleRatio,
+				ServiceName: DefaultTracingServiceName,
+			},
 		},
 	}
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/xscaling/wing/core/engine"
+
+	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ConfigReloader polls the config file and reloads plugins of engine when it changes.
+// Polling content instead of watching file events also works with ConfigMap volumes,
+// which are updated by swapping symlinks.
+type ConfigReloader struct {
+	ConfigFile string
+	Interval   time.Duration
+	Engine     *engine.Engine
+
+	logger   logr.Logger
+	checksum [sha256.Size]byte
+	config   *Config
+}
+
+var (
+	_ manager.Runnable               = &ConfigReloader{}
+	_ manager.LeaderElectionRunnable = &ConfigReloader{}
+)
+
+// NewConfigReloader returns reloader of config loaded at startup
+func NewConfigReloader(options *Options, config *Config, engine *engine.Engine) (*ConfigReloader, error) {
+	configContent, err := os.ReadFile(options.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigReloader{
+		ConfigFile: options.Config,
+		Interval:   options.ConfigReloadInterval,
+		Engine:     engine,
+		logger:     log.Log.WithName("config-reloader").WithValues("config", options.Config),
+		checksum:   sha256.Sum256(configContent),
+		config:     config,
+	}, nil
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, plugins of all replicas should be reloaded.
+func (r *ConfigReloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements Runnable, it blocks until context done.
+func (r *ConfigReloader) Start(ctx context.Context) error {
+	r.logger.Info("Starting config reloader", "interval", r.Interval)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		r.reload()
+	}, r.Interval)
+	return nil
+}
+
+func (r *ConfigReloader) reload() {
+	configContent, err := os.ReadFile(r.ConfigFile)
+	if err != nil {
+		r.logger.Error(err, "Failed to read config")
+		return
+	}
+	checksum := sha256.Sum256(configContent)
+	if checksum == r.checksum {
+		return
+	}
+	// Config is marked as seen even if invalid, so that errors are reported once per change
+	r.checksum = checksum
+	config := NewDefaultConfig()
+	if err = yaml.Unmarshal(configContent, config); err != nil {
+		r.logger.Error(err, "Invalid config, keep previous one")
+		return
+	}
+	r.logger.Info("Config changed, reloading plugins")
+	if err = r.Engine.Reload(config.Plugins); err != nil {
+		r.logger.Error(err, "Plugins failed to reload, previous ones are kept")
+	}
+	if !isOnlyPluginsChanged(r.config, config) {
+		r.logger.Info("Config other than plugins changed, which takes effect after restart")
+	}
+	r.config = config
+}
+
+func isOnlyPluginsChanged(previous, current *Config) bool {
+	previousWithoutPlugins, currentWithoutPlugins := *previous, *current
+	previousWithoutPlugins.Plugins, currentWithoutPlugins.Plugins = nil, nil
+	return reflect.DeepEqual(previousWithoutPlugins, currentWithoutPlugins)
+}
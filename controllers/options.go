@@ -19,22 +19,41 @@ package controllers
 import (
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Options struct {
 	Config string
+	// WatchNamespaces restricts controller to work on given namespaces only, empty means all namespaces
+	WatchNamespaces []string
+	// ConfigReloadInterval is the interval of checking config changes, zero disables reloading
+	ConfigReloadInterval time.Duration
 }
 
 func NewDefaultOptions() *Options {
 	return &Options{
-		Config: "wing.yaml",
+		Config:               "wing.yaml",
+		ConfigReloadInterval: 10 * time.Second,
 	}
 }
 
 func (o *Options) BindFlags(fs *flag.FlagSet) {
 	fs.StringVar(&o.Config, "config", o.Config, "Wing config file path")
+	fs.DurationVar(&o.ConfigReloadInterval, "config-reload-interval", o.ConfigReloadInterval,
+		"Interval of checking config file changes to reload plugins, 0 disables reloading.")
+	fs.Func("watch-namespaces", "Comma separated namespaces to watch, "+
+		"all namespaces are watched by default which requires cluster-wide permissions.", func(value string) error {
+		o.WatchNamespaces = nil
+		for _, namespace := range strings.Split(value, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				o.WatchNamespaces = append(o.WatchNamespaces, namespace)
+			}
+		}
+		return nil
+	})
 }
 
 func (o *Options) LoadConfig(conf *Config) error {
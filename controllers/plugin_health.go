@@ -0,0 +1,123 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/xscaling/wing/core/engine"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// PluginHealthReporter checks health of plugins periodically, results are reported by
+// readiness check, `/plugins` endpoint and `plugin_healthy` gauges.
+type PluginHealthReporter struct {
+	Config PluginHealthConfig
+	Engine *engine.Engine
+
+	logger  logr.Logger
+	mu      sync.RWMutex
+	results []engine.PluginHealth
+}
+
+var (
+	_ manager.Runnable               = &PluginHealthReporter{}
+	_ manager.LeaderElectionRunnable = &PluginHealthReporter{}
+	_ http.Handler                   = &PluginHealthReporter{}
+	_ healthz.Checker                = (&PluginHealthReporter{}).ReadyzCheck
+)
+
+func NewPluginHealthReporter(config PluginHealthConfig, engine *engine.Engine) *PluginHealthReporter {
+	return &PluginHealthReporter{
+		Config: config,
+		Engine: engine,
+		logger: log.Log.WithName("plugin-health"),
+	}
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, readiness of all replicas depends on plugins.
+func (r *PluginHealthReporter) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements Runnable, it blocks until context done.
+func (r *PluginHealthReporter) Start(ctx context.Context) error {
+	r.logger.Info("Starting plugin health reporter", "interval", r.Config.Interval)
+	wait.UntilWithContext(ctx, r.check, r.Config.Interval)
+	return nil
+}
+
+func (r *PluginHealthReporter) check(ctx context.Context) {
+	results := r.Engine.CheckPluginsHealth(ctx, r.Config.Timeout)
+	metricPluginHealthy.Reset()
+	for _, result := range results {
+		healthy := 0.0
+		if result.Healthy {
+			healthy = 1
+		} else {
+			r.logger.Info("Plugin is unhealthy", "endpoint", result.Endpoint, "plugin", result.Name, "error", result.Error)
+		}
+		metricPluginHealthy.WithLabelValues(result.Name, result.Endpoint).Set(healthy)
+	}
+	r.mu.Lock()
+	r.results = results
+	r.mu.Unlock()
+}
+
+// ReadyzCheck is a healthz.Checker which fails before first check or when any plugin is unhealthy.
+func (r *PluginHealthReporter) ReadyzCheck(_ *http.Request) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.results == nil {
+		return errors.New("plugins are not checked yet")
+	}
+	var unhealthy []string
+	for _, result := range r.results {
+		if !result.Healthy {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s/%s: %s", result.Endpoint, result.Name, result.Error))
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("unhealthy plugins: %s", strings.Join(unhealthy, "; "))
+	}
+	return nil
+}
+
+// ServeHTTP serves results of last check as JSON
+func (r *PluginHealthReporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.RLock()
+	results := r.results
+	r.mu.RUnlock()
+	if results == nil {
+		results = []engine.PluginHealth{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		r.logger.Error(err, "Failed to write plugin health")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/utils"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ScaleTargetIndexField indexes ReplicaAutoscaler by `group/kind/name` of scale target and members
+	ScaleTargetIndexField = ".spec.scaleTargetRef"
+)
+
+var (
+	scaledObjectGVK = schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObjectList"}
+)
+
+func indexScaleTarget(object runtimeclient.Object) []string {
+	autoscaler, ok := object.(*wingv1.ReplicaAutoscaler)
+	if !ok {
+		return nil
+	}
+	return getScaleTargetKeys(autoscaler)
+}
+
+// getScaleTargetKeys returns keys of scale target followed by members
+func getScaleTargetKeys(autoscaler *wingv1.ReplicaAutoscaler) []string {
+	var keys []string
+	refs := []wingv1.CrossVersionObjectReference{autoscaler.Spec.ScaleTargetRef}
+	for _, member := range autoscaler.Spec.Members {
+		refs = append(refs, member.ScaleTargetRef)
+	}
+	for _, ref := range refs {
+		if ref.Name != "" {
+			keys = append(keys, utils.GetScaleTargetKey(ref.APIVersion, ref.Kind, ref.Name))
+		}
+	}
+	return keys
+}
+
+// reconcileConflict checks whether there is any other autoscaler working on the same scale target or members.
+// HPAs are taken over by conflict policy only if they are the last owners and not dry run.
+// Returns true if scaling should be refused.
+func (r *ReplicaAutoscalerReconciler) reconcileConflict(logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler) bool {
+	targetKeys := sets.NewString(getScaleTargetKeys(autoscaler)...)
+
+	// Owners might be missing if any check failed, so HPAs won't be taken over
+	checkFailed := false
+	owners := sets.NewString()
+	for _, targetKey := range targetKeys.List() {
+		autoscalerOwner, err := r.getConflictedReplicaAutoscaler(autoscaler, targetKey)
+		if err != nil {
+			logger.Error(err, "Failed to check conflicted ReplicaAutoscaler")
+			checkFailed = true
+		} else if autoscalerOwner != "" {
+			owners.Insert(autoscalerOwner)
+		}
+	}
+
+	if r.Config.Conflict.DetectScaledObjects {
+		scaledObjectOwners, err := r.getConflictedScaledObjects(autoscaler.Namespace, targetKeys)
+		if err != nil {
+			logger.Error(err, "Failed to check conflicted ScaledObject")
+			checkFailed = true
+		}
+		owners.Insert(scaledObjectOwners...)
+	}
+
+	hpas, err := r.getConflictedHPAs(autoscaler.Namespace, targetKeys)
+	if err != nil {
+		logger.Error(err, "Failed to check conflicted HorizontalPodAutoscaler")
+	}
+	takeOverHPAs := len(owners) == 0 && !checkFailed && !r.isDryRun(autoscaler)
+	for _, hpa := range hpas {
+		if takeOverHPAs {
+			if taken, err := r.takeOverHPA(autoscaler, hpa); err != nil {
+				logger.Error(err, "Failed to take over HorizontalPodAutoscaler", "hpa", hpa.Name)
+			} else if taken {
+				continue
+			}
+		}
+		owners.Insert("HorizontalPodAutoscaler/" + hpa.Name)
+	}
+
+	if len(owners) == 0 {
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:   wingv1.ConditionConflict,
+			Status: metav1.ConditionFalse,
+		})
+		return false
+	}
+
+	message := fmt.Sprintf("Scale target is also managed by %s", strings.Join(owners.List(), ", "))
+	if lastCondition := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionConflict); lastCondition.Status != metav1.ConditionTrue ||
+		lastCondition.Message != message {
+		logger.Info("Found conflicted autoscaler(s)", "owners", owners.List())
+		r.EventRecorder.Event(autoscaler, wingv1.EventTypeWarning, wingv1.EventReasonConflict, message)
+	}
+	autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+		Type:    wingv1.ConditionConflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ScaleTargetConflicted",
+		Message: message,
+	})
+	return true
+}
+
+// getConflictedReplicaAutoscaler returns the earliest created ReplicaAutoscaler on the same target
+// if it's not the given one, which means the earliest one owns the target.
+func (r *ReplicaAutoscalerReconciler) getConflictedReplicaAutoscaler(
+	autoscaler *wingv1.ReplicaAutoscaler, targetKey string) (string, error) {
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers,
+		runtimeclient.InNamespace(autoscaler.Namespace),
+		runtimeclient.MatchingFields{ScaleTargetIndexField: targetKey}); err != nil {
+		return "", err
+	}
+	var owner *wingv1.ReplicaAutoscaler
+	for i := range autoscalers.Items {
+		candidate := &autoscalers.Items[i]
+		if candidate.DeletionTimestamp != nil {
+			continue
+		}
+		if owner == nil || candidate.CreationTimestamp.Before(&owner.CreationTimestamp) ||
+			(candidate.CreationTimestamp.Equal(&owner.CreationTimestamp) && candidate.Name < owner.Name) {
+			owner = candidate
+		}
+	}
+	if owner == nil || owner.Name == autoscaler.Name {
+		return "", nil
+	}
+	return "ReplicaAutoscaler/" + owner.Name, nil
+}
+
+// getConflictedHPAs returns HPAs working on the scale target or members
+func (r *ReplicaAutoscalerReconciler) getConflictedHPAs(namespace string,
+	targetKeys sets.String) ([]*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.Client.List(context.TODO(), hpas, runtimeclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var conflicted []*autoscalingv2.HorizontalPodAutoscaler
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		ref := hpa.Spec.ScaleTargetRef
+		if targetKeys.Has(utils.GetScaleTargetKey(ref.APIVersion, ref.Kind, ref.Name)) && !isHPASuspended(hpa) {
+			conflicted = append(conflicted, hpa)
+		}
+	}
+	return conflicted, nil
+}
+
+func isHPASuspended(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	isDisabled := func(rules *autoscalingv2.HPAScalingRules) bool {
+		return rules != nil && rules.SelectPolicy != nil && *rules.SelectPolicy == autoscalingv2.DisabledPolicySelect
+	}
+	return hpa.Spec.Behavior != nil && isDisabled(hpa.Spec.Behavior.ScaleUp) && isDisabled(hpa.Spec.Behavior.ScaleDown)
+}
+
+// takeOverHPA deletes or suspends the HPA according to the conflict policy
+func (r *ReplicaAutoscalerReconciler) takeOverHPA(autoscaler *wingv1.ReplicaAutoscaler,
+	hpa *autoscalingv2.HorizontalPodAutoscaler) (bool, error) {
+	switch autoscaler.Spec.ConflictPolicy {
+	case wingv1.ConflictPolicyDeleteHPA:
+		if err := r.Client.Delete(context.TODO(), hpa); err != nil {
+			return false, err
+		}
+		r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeWarning, wingv1.EventReasonConflict,
+			"Deleted HorizontalPodAutoscaler/%s to take over scale target", hpa.Name)
+		return true, nil
+	case wingv1.ConflictPolicySuspendHPA:
+		patch := runtimeclient.MergeFrom(hpa.DeepCopy())
+		disabled := autoscalingv2.DisabledPolicySelect
+		if hpa.Spec.Behavior == nil {
+			hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		}
+		if hpa.Spec.Behavior.ScaleUp == nil {
+			hpa.Spec.Behavior.ScaleUp = &autoscalingv2.HPAScalingRules{}
+		}
+		if hpa.Spec.Behavior.ScaleDown == nil {
+			hpa.Spec.Behavior.ScaleDown = &autoscalingv2.HPAScalingRules{}
+		}
+		hpa.Spec.Behavior.ScaleUp.SelectPolicy = &disabled
+		hpa.Spec.Behavior.ScaleDown.SelectPolicy = &disabled
+		if err := r.Client.Patch(context.TODO(), hpa, patch); err != nil {
+			return false, err
+		}
+		r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeWarning, wingv1.EventReasonConflict,
+			"Suspended HorizontalPodAutoscaler/%s to take over scale target", hpa.Name)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *ReplicaAutoscalerReconciler) getConflictedScaledObjects(namespace string,
+	targetKeys sets.String) ([]string, error) {
+	scaledObjects := &unstructured.UnstructuredList{}
+	scaledObjects.SetGroupVersionKind(scaledObjectGVK)
+	if err := r.Client.List(context.TODO(), scaledObjects, runtimeclient.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			// KEDA is not installed
+			return nil, nil
+		}
+		return nil, err
+	}
+	var owners []string
+	for _, scaledObject := range scaledObjects.Items {
+		ref, _, _ := unstructured.NestedStringMap(scaledObject.Object, "spec", "scaleTargetRef")
+		if targetKeys.Has(utils.GetScaleTargetKey(ref["apiVersion"], ref["kind"], ref["name"])) {
+			owners = append(owners, "ScaledObject/"+scaledObject.GetName())
+		}
+	}
+	return owners, nil
+}
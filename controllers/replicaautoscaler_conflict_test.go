@@ -0,0 +1,226 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestHPA(name, target string, suspended bool) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: target,
+			},
+			MaxReplicas: 10,
+		},
+	}
+	if suspended {
+		disabled := autoscalingv2.DisabledPolicySelect
+		hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleUp:   &autoscalingv2.HPAScalingRules{SelectPolicy: &disabled},
+			ScaleDown: &autoscalingv2.HPAScalingRules{SelectPolicy: &disabled},
+		}
+	}
+	return hpa
+}
+
+func newTestScaledObject(name, target string) *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+		},
+	}}
+	scaledObject.SetGroupVersionKind(scaledObjectGVK.GroupVersion().WithKind("ScaledObject"))
+	scaledObject.SetNamespace("default")
+	scaledObject.SetName(name)
+	return scaledObject
+}
+
+func newConflictTestAutoscaler(name string, createdAt time.Time, policy wingv1.ConflictPolicy) *wingv1.ReplicaAutoscaler {
+	return &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name, CreationTimestamp: metav1.NewTime(createdAt),
+		},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			Members: []wingv1.ScaleTargetMember{{
+				ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "worker"},
+			}},
+			MaxReplicas:    10,
+			ConflictPolicy: policy,
+		},
+	}
+}
+
+func TestReconcileConflict(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, testCase := range []struct {
+		description string
+		policy      wingv1.ConflictPolicy
+		dryRun      bool
+		objects     []runtimeclient.Object
+
+		expectedRefused bool
+		expectedMessage string
+		// expectedHPAs are HPAs left untouched
+		expectedHPAs          []string
+		expectedSuspendedHPAs []string
+	}{
+		{
+			description: "no conflict",
+			objects: []runtimeclient.Object{
+				newTestHPA("other", "other", false),
+				newTestHPA("suspended", "web", true),
+				// Younger autoscaler on the same target doesn't own it
+				newConflictTestAutoscaler("younger", createdAt.Add(time.Hour), ""),
+			},
+			expectedHPAs:          []string{"other"},
+			expectedSuspendedHPAs: []string{"suspended"},
+		},
+		{
+			description:     "refuse HPA",
+			objects:         []runtimeclient.Object{newTestHPA("web", "web", false)},
+			expectedRefused: true,
+			expectedMessage: "Scale target is also managed by HorizontalPodAutoscaler/web",
+			expectedHPAs:    []string{"web"},
+		},
+		{
+			description: "delete HPAs",
+			policy:      wingv1.ConflictPolicyDeleteHPA,
+			objects:     []runtimeclient.Object{newTestHPA("web", "web", false), newTestHPA("worker", "worker", false)},
+		},
+		{
+			description:           "suspend HPA of member",
+			policy:                wingv1.ConflictPolicySuspendHPA,
+			objects:               []runtimeclient.Object{newTestHPA("worker", "worker", false)},
+			expectedSuspendedHPAs: []string{"worker"},
+		},
+		{
+			description: "HPA not taken over as older autoscaler owns the target",
+			policy:      wingv1.ConflictPolicyDeleteHPA,
+			objects: []runtimeclient.Object{
+				newTestHPA("web", "web", false),
+				newConflictTestAutoscaler("older", createdAt.Add(-time.Hour), ""),
+			},
+			expectedRefused: true,
+			expectedMessage: "Scale target is also managed by HorizontalPodAutoscaler/web, ReplicaAutoscaler/older",
+			expectedHPAs:    []string{"web"},
+		},
+		{
+			description: "HPA not taken over as ScaledObject owns the target",
+			policy:      wingv1.ConflictPolicySuspendHPA,
+			objects: []runtimeclient.Object{
+				newTestHPA("web", "web", false),
+				newTestScaledObject("web", "web"),
+				newTestScaledObject("other", "other"),
+			},
+			expectedRefused: true,
+			expectedMessage: "Scale target is also managed by HorizontalPodAutoscaler/web, ScaledObject/web",
+			expectedHPAs:    []string{"web"},
+		},
+		{
+			description:     "HPA not taken over under dry run",
+			policy:          wingv1.ConflictPolicySuspendHPA,
+			dryRun:          true,
+			objects:         []runtimeclient.Object{newTestHPA("web", "web", false)},
+			expectedRefused: true,
+			expectedMessage: "Scale target is also managed by HorizontalPodAutoscaler/web",
+			expectedHPAs:    []string{"web"},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			autoscaler := newConflictTestAutoscaler("sample", createdAt, testCase.policy)
+			client := newTestClient(t, append(testCase.objects, autoscaler)...)
+			r := &ReplicaAutoscalerReconciler{
+				Client:        client,
+				Cache:         &fakeCache{FakeInformers: &informertest.FakeInformers{}, reader: client},
+				EventRecorder: record.NewFakeRecorder(1024),
+				DryRun:        testCase.dryRun,
+			}
+			r.Config.Conflict.DetectScaledObjects = true
+
+			require.Equal(t, testCase.expectedRefused, r.reconcileConflict(log.Log, autoscaler))
+			condition := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionConflict)
+			require.Equal(t, testCase.expectedRefused, condition.Status == metav1.ConditionTrue)
+			require.Equal(t, testCase.expectedMessage, condition.Message)
+
+			hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+			require.NoError(t, client.List(context.TODO(), hpas))
+			var untouched, suspended []string
+			for i := range hpas.Items {
+				if isHPASuspended(&hpas.Items[i]) {
+					suspended = append(suspended, hpas.Items[i].Name)
+				} else {
+					untouched = append(untouched, hpas.Items[i].Name)
+				}
+			}
+			require.Equal(t, testCase.expectedHPAs, untouched)
+			require.Equal(t, testCase.expectedSuspendedHPAs, suspended)
+		})
+	}
+}
+
+func TestTakeOverHPA(t *testing.T) {
+	hpa := newTestHPA("web", "web", false)
+	client := newTestClient(t, hpa)
+	recorder := record.NewFakeRecorder(1024)
+	r := &ReplicaAutoscalerReconciler{Client: client, EventRecorder: recorder}
+	autoscaler := newConflictTestAutoscaler("sample", time.Now(), wingv1.ConflictPolicyRefuse)
+	key := types.NamespacedName{Namespace: hpa.Namespace, Name: hpa.Name}
+
+	taken, err := r.takeOverHPA(autoscaler, hpa.DeepCopy())
+	require.NoError(t, err)
+	require.False(t, taken)
+
+	autoscaler.Spec.ConflictPolicy = wingv1.ConflictPolicySuspendHPA
+	taken, err = r.takeOverHPA(autoscaler, hpa.DeepCopy())
+	require.NoError(t, err)
+	require.True(t, taken)
+	suspendedHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+	require.NoError(t, client.Get(context.TODO(), key, suspendedHPA))
+	require.True(t, isHPASuspended(suspendedHPA))
+	require.Equal(t, int32(10), suspendedHPA.Spec.MaxReplicas)
+	require.Contains(t, <-recorder.Events, "Suspended HorizontalPodAutoscaler/web")
+
+	autoscaler.Spec.ConflictPolicy = wingv1.ConflictPolicyDeleteHPA
+	taken, err = r.takeOverHPA(autoscaler, suspendedHPA)
+	require.NoError(t, err)
+	require.True(t, taken)
+	require.True(t, errors.IsNotFound(client.Get(context.TODO(), key, &autoscalingv2.HorizontalPodAutoscaler{})))
+	require.Contains(t, <-recorder.Events, "Deleted HorizontalPodAutoscaler/web")
+
+	// HPA already gone
+	_, err = r.takeOverHPA(autoscaler, suspendedHPA)
+	require.Error(t, err)
+}
@@ -18,12 +18,18 @@ package controllers
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/sharding"
 	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
@@ -35,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -42,6 +49,8 @@ import (
 type ReplicaAutoscalerReconciler struct {
 	runtimeclient.Client
 	cache.Cache
+	// APIReader reads objects not worth caching cluster wide, e.g. calendar ConfigMaps
+	APIReader runtimeclient.Reader
 
 	EventRecorder record.EventRecorder
 
@@ -50,9 +59,18 @@ type ReplicaAutoscalerReconciler struct {
 	Scheme           *runtime.Scheme
 	Engine           *engine.Engine
 	DryRun           bool
+	// Sharding is nil if sharding is disabled
+	Sharding *sharding.Coordinator
 
 	restMapper  meta.RESTMapper
 	scaleClient scale.ScalesGetter
+
+	// shardingEvents triggers reconciling autoscalers acquired on rebalance
+	shardingEvents chan event.GenericEvent
+	// workingAutoscalers are autoscalers whose states are held by this controller under sharding
+	workingAutoscalers sync.Map
+	// exportedTargetMetrics are target metrics exported per autoscaler
+	exportedTargetMetrics sync.Map
 }
 
 //+kubebuilder:rbac:groups=wing.xscaling.dev,resources=replicaautoscalers,verbs=get;list;watch;create;update;patch;delete
@@ -60,30 +78,54 @@ type ReplicaAutoscalerReconciler struct {
 //+kubebuilder:rbac:groups=wing.xscaling.dev,resources=replicaautoscalers/finalizers,verbs=update
 //+kubebuilder:rbac:groups=*,resources=*/scale,verbs=*
 //+kubebuilder:rbac:groups="core",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="core",resources=configmaps,verbs=get
+//+kubebuilder:rbac:groups="apps",resources=deployments;statefulsets,verbs=get;list;watch
 //+kubebuilder:rbac:groups="core",resources=events,verbs="*"
 //+kubebuilder:rbac:groups="metrics.k8s.io",resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups="keda.sh",resources=scaledobjects,verbs=get;list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.0/pkg/reconcile
-func (r *ReplicaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *ReplicaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Reconcile",
+		attribute.String("namespace", req.Namespace), attribute.String("replicaAutoscaler", req.Name))
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
 	logger := log.FromContext(ctx)
 	logger.V(4).Info("Reconciling")
+	if owned, wait := r.ownsAutoscaler(req.NamespacedName); !owned || wait > 0 {
+		logger.V(4).Info("Autoscaler is not owned by this shard currently", "owned", owned, "wait", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
 	replicaAutoscaler := &wingv1.ReplicaAutoscaler{}
 
 	if err := r.Cache.Get(ctx, req.NamespacedName, replicaAutoscaler); err != nil {
+		if errors.IsNotFound(err) {
+			logger.V(4).Info("ReplicaAutoscaler is gone")
+			r.forgetAutoscaler(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
 		logger.Error(err, "Unable to get ReplicaAutoscaler")
 		return ctrl.Result{}, err
 	}
 	observedAutoscaler := replicaAutoscaler.DeepCopy()
+	r.takeOverAutoscaler(logger, replicaAutoscaler)
 
-	if err := utils.PurgeUnusedReplicaPatches(replicaAutoscaler); err != nil {
+	now := r.now()
+	if err := utils.PurgeUnusedReplicaPatches(now, replicaAutoscaler); err != nil {
 		logger.Error(err, "Failed to purge unused replica patches")
 	}
+	if err := utils.PurgeExpiredPauseAnnotations(now, replicaAutoscaler); err != nil {
+		logger.Error(err, "Failed to purge expired pause annotations")
+	}
 
-	requeueDelay := r.reconcile(logger, replicaAutoscaler)
+	requeueDelay := r.reconcile(ctx, logger, replicaAutoscaler)
+	r.recordAutoscalerMetrics(replicaAutoscaler)
 
 	// Patch the autoscaler if needed
 	if updateRequeueDelay, err := r.updateAutoscalerIfNeeded(ctx, observedAutoscaler, replicaAutoscaler); err != nil {
@@ -103,6 +145,9 @@ func (r *ReplicaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Re
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReplicaAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger()
+	if err := r.Config.Metrics.Validate(); err != nil {
+		return err
+	}
 	clientset, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
 	if err != nil {
 		logger.Error(err, "Not able to create Discovery clientset")
@@ -115,13 +160,19 @@ func (r *ReplicaAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		scaleKindResolver,
 	)
 	r.restMapper = mgr.GetRESTMapper()
-	logger.Info("Setting up controller with manager", "reconcileConcurrent", r.Config.Workers)
-	return ctrl.NewControllerManagedBy(mgr).
+	if err = mgr.GetFieldIndexer().IndexField(context.Background(), &wingv1.ReplicaAutoscaler{},
+		ScaleTargetIndexField, indexScaleTarget); err != nil {
+		logger.Error(err, "Not able to index ReplicaAutoscaler by scale target")
+		return err
+	}
+	logger.Info("Setting up controller with manager", "reconcileConcurrent", r.Config.Workers,
+		"watchScaleTargets", r.Config.Watch.ScaleTargets, "watchPods", r.Config.Watch.Pods)
+	blder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.Config.Workers,
 		}).
-		For(&wingv1.ReplicaAutoscaler{}).
-		Complete(r)
+		For(&wingv1.ReplicaAutoscaler{})
+	return r.setupSharding(r.setupWatches(blder)).Complete(r)
 }
 
 func (r *ReplicaAutoscalerReconciler) updateAutoscalerIfNeeded(ctx context.Context,
@@ -137,6 +188,12 @@ func (r *ReplicaAutoscalerReconciler) updateAutoscalerIfNeeded(ctx context.Conte
 		return 0, nil
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "Autoscaler.Patch",
+		attribute.Bool("annotations", !annotationsEqual), attribute.Bool("status", !statusEqual))
+	var err error
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
 	patch := runtimeclient.MergeFrom(observedAutoscaler.DeepCopy())
 	observedAutoscaler.Annotations = make(map[string]string)
 	for k, v := range replicaAutoscaler.Annotations {
@@ -147,7 +204,7 @@ func (r *ReplicaAutoscalerReconciler) updateAutoscalerIfNeeded(ctx context.Conte
 	if annotationsEqual && !statusEqual {
 		// patch status only
 		logger.V(4).Info("Patching status only")
-		err := r.Client.Status().Patch(ctx, observedAutoscaler, patch)
+		err = r.Client.Status().Patch(ctx, observedAutoscaler, patch)
 		if err != nil {
 			logger.Error(err, "Failed to update autoscaler status")
 			return RequeueDelayOnErrorState, err
@@ -155,7 +212,7 @@ func (r *ReplicaAutoscalerReconciler) updateAutoscalerIfNeeded(ctx context.Conte
 	} else {
 		// patch both annotations and status
 		logger.V(4).Info("Patching both annotations and status")
-		err := r.Client.Patch(ctx, observedAutoscaler, patch)
+		err = r.Client.Patch(ctx, observedAutoscaler, patch)
 		if err != nil {
 			logger.Error(err, "Failed to update autoscaler object")
 			return RequeueDelayOnErrorState, err
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isDryRun allows setting dry-run by ReplicaAutoscaler or global dry-run by controller
+func (r *ReplicaAutoscalerReconciler) isDryRun(autoscaler *wingv1.ReplicaAutoscaler) bool {
+	return autoscaler.Annotations[wingv1.DryRunAnnotation] == "true" || r.DryRun
+}
+
+// setLastDecision stores the decision into status, decision time is kept if nothing changed
+// to avoid refreshing status on every reconcile.
+func setLastDecision(status *wingv1.ReplicaAutoscalerStatus,
+	currentReplicas, desiredReplicas int32, panicMode, dryRun bool, trace *engine.DecisionTrace) {
+	decision := &wingv1.ScalingDecision{
+		Time:            metav1.Now(),
+		CurrentReplicas: currentReplicas,
+		DesiredReplicas: desiredReplicas,
+		PanicMode:       panicMode,
+		DryRun:          dryRun,
+		Steps:           trace.Steps(),
+	}
+	if last := status.LastDecision; last != nil {
+		decision.Time = last.Time
+		if utils.DeepEqual(last, decision) {
+			return
+		}
+		decision.Time = metav1.Now()
+	}
+	status.LastDecision = decision
+}
+
+// getWinningTarget returns the target whose scaler output is adopted by replicator
+func getWinningTarget(steps []wingv1.DecisionStep) string {
+	var (
+		replicatorReplicas int32
+		found              bool
+	)
+	for _, step := range steps {
+		if step.Stage == wingv1.DecisionStageReplicator {
+			replicatorReplicas, found = step.Replicas, true
+			break
+		}
+	}
+	if !found {
+		return ""
+	}
+	for _, step := range steps {
+		if step.Stage == wingv1.DecisionStageScaler && step.Replicas == replicatorReplicas {
+			return step.Source
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/group"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// limitReplicasByBudget lowers desired replicas so that total replicas of scale target and members
+// fit in replica budget of the group
+func (r *ReplicaAutoscalerReconciler) limitReplicasByBudget(logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler, desiredReplicas, minReplicas int32,
+	trace *engine.DecisionTrace) (replicas int32, limited bool) {
+	budget := autoscaler.Spec.ReplicaBudget
+	if budget == nil {
+		return desiredReplicas, false
+	}
+	replicas, limited = group.LimitReplicasByBudget(desiredReplicas, minReplicas, autoscaler.Spec.Members, *budget)
+	if limited {
+		trace.Record(wingv1.DecisionStageLimit, "budget", replicas,
+			"limited by replica budget %d shared with %d member(s)", *budget, len(autoscaler.Spec.Members))
+		logger.V(4).Info("Desired replicas exceed replica budget",
+			"desiredReplicas", desiredReplicas, "replicaBudget", *budget)
+	}
+	return replicas, limited
+}
+
+// scaleMembers scales members following replicas of scale target and reports their status,
+// a member failed to scale doesn't block the others.
+func (r *ReplicaAutoscalerReconciler) scaleMembers(ctx context.Context, logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler, replicas int32) error {
+	if len(autoscaler.Spec.Members) == 0 {
+		autoscaler.Status.Members = nil
+		return nil
+	}
+	lastScaleTimes := make(map[string]*metav1.Time, len(autoscaler.Status.Members))
+	for _, member := range autoscaler.Status.Members {
+		lastScaleTimes[member.Kind+"/"+member.Name] = member.LastScaleTime
+	}
+
+	var errs []error
+	members := make([]wingv1.MemberStatus, 0, len(autoscaler.Spec.Members))
+	for _, member := range autoscaler.Spec.Members {
+		ref := member.ScaleTargetRef
+		status := wingv1.MemberStatus{
+			Kind:            ref.Kind,
+			Name:            ref.Name,
+			DesiredReplicas: group.GetMemberReplicas(replicas, member),
+			LastScaleTime:   lastScaleTimes[ref.Kind+"/"+ref.Name],
+		}
+		memberLogger := logger.WithValues("member", ref.Kind+"/"+ref.Name)
+		gvkr, scale, err := r.getScaleTarget(memberLogger, autoscaler.Namespace, ref)
+		if err != nil {
+			status.Message = fmt.Sprintf("Failed to get scale target: %v", err)
+			errs = append(errs, fmt.Errorf("member %s/%s: %w", ref.Kind, ref.Name, err))
+			members = append(members, status)
+			continue
+		}
+		status.CurrentReplicas = scale.Status.Replicas
+		scaled, err := r.updateScale(ctx, memberLogger, autoscaler, gvkr, scale.DeepCopy(), status.DesiredReplicas)
+		if err != nil {
+			memberLogger.Error(err, "Failed to scale member")
+			status.Message = fmt.Sprintf("Failed to scale: %v", err)
+			errs = append(errs, fmt.Errorf("member %s/%s: %w", ref.Kind, ref.Name, err))
+		} else if scaled && !r.isDryRun(autoscaler) {
+			now := metav1.NewTime(r.now())
+			status.LastScaleTime = &now
+		}
+		members = append(members, status)
+	}
+	autoscaler.Status.Members = members
+	return utilerrors.NewAggregate(errs)
+}
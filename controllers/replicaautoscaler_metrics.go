@@ -17,17 +17,247 @@ limitations under the License.
 package controllers
 
 import (
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
 	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	runtimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+const (
+	scalerErrorReasonInvalidSettings = "InvalidSettings"
+	scalerErrorReasonNotExists       = "ScalerNotExists"
+	scalerErrorReasonFailed          = "ScalerFailed"
+)
+
 var (
+	// Deprecated: use scaler_duration_seconds and replicator_duration_seconds instead.
+	// Labels `namespace` and `replicaautoscaler` are left empty by metrics label level as well.
 	metricPluginElapsed = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "plugin_elapsed",
-		Help: "The time elapsed for the scaler/replicator plugin to run",
+		Help: "The time elapsed for the scaler/replicator plugin to run, deprecated",
 	}, []string{"namespace", "replicaautoscaler", "plugin", "kind"})
+	metricPluginHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "plugin_healthy",
+		Help: "Whether the scaler/replicator plugin is healthy, plugins not implementing health check are always healthy",
+	}, []string{"plugin", "kind"})
+
+	// Labels `namespace` and `replicaautoscaler` of following metrics are left empty by metrics label level
+	metricScalerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scaler_duration_seconds",
+		Help:    "Latency of scaler calculating desired replicas",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "replicaautoscaler", "scaler"})
+	metricReplicatorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "replicator_duration_seconds",
+		Help:    "Latency of replicator deciding desired replicas",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "replicaautoscaler", "replicator"})
+	metricScalerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scaler_errors_total",
+		Help: "Count of scaler errors by reason",
+	}, []string{"namespace", "replicaautoscaler", "scaler", "reason"})
+	metricScalingActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scaling_actions_total",
+		Help: "Count of scaling actions performed on scale targets",
+	}, []string{"namespace", "replicaautoscaler", "direction", "reason"})
+
+	// Following metrics are per autoscaler, which are only exported on `autoscaler` metrics label level
+	metricAutoscalerReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replicaautoscaler_replicas",
+		Help: "Replicas of autoscaler by type, current/desired from status and min/max from spec",
+	}, []string{"namespace", "replicaautoscaler", "type"})
+	metricAutoscalerPanicMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replicaautoscaler_panic_mode",
+		Help: "Whether autoscaler is in panic mode",
+	}, []string{"namespace", "replicaautoscaler"})
+	metricAutoscalerExhausted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replicaautoscaler_exhausted",
+		Help: "Whether autoscaler is exhausted",
+	}, []string{"namespace", "replicaautoscaler"})
+	metricTargetMetricValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replicaautoscaler_target_metric",
+		Help: "Metric value of autoscaler target mirroring `.status.targets`",
+	}, []string{"namespace", "replicaautoscaler", "target", "scaler", "type"})
+	metricTargetDesiredReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replicaautoscaler_target_desired_replicas",
+		Help: "Desired replicas calculated by autoscaler target mirroring `.status.targets`",
+	}, []string{"namespace", "replicaautoscaler", "target", "scaler"})
 )
 
 func init() {
-	runtimemetrics.Registry.MustRegister(metricPluginElapsed)
+	runtimemetrics.Registry.MustRegister(
+		metricPluginElapsed,
+		metricPluginHealthy,
+		metricScalerDuration,
+		metricReplicatorDuration,
+		metricScalerErrors,
+		metricScalingActions,
+		metricAutoscalerReplicas,
+		metricAutoscalerPanicMode,
+		metricAutoscalerExhausted,
+		metricTargetMetricValue,
+		metricTargetDesiredReplicas,
+	)
+}
+
+// getMetricsLabels returns autoscaler labels reduced by metrics label level
+func (r *ReplicaAutoscalerReconciler) getMetricsLabels(autoscaler *wingv1.ReplicaAutoscaler) (namespace, name string) {
+	switch r.Config.Metrics.LabelLevel {
+	case MetricsLabelLevelNone:
+		return "", ""
+	case MetricsLabelLevelNamespace:
+		return autoscaler.Namespace, ""
+	}
+	return autoscaler.Namespace, autoscaler.Name
+}
+
+func (r *ReplicaAutoscalerReconciler) observeScalerDuration(autoscaler *wingv1.ReplicaAutoscaler,
+	scaler string, startAt time.Time) {
+	namespace, name := r.getMetricsLabels(autoscaler)
+	elapsed := time.Since(startAt).Seconds()
+	metricScalerDuration.WithLabelValues(namespace, name, scaler).Observe(elapsed)
+	metricPluginElapsed.WithLabelValues(namespace, name, scaler, "scaler").Add(elapsed)
+}
+
+func (r *ReplicaAutoscalerReconciler) observeReplicatorDuration(autoscaler *wingv1.ReplicaAutoscaler,
+	replicator string, startAt time.Time) {
+	namespace, name := r.getMetricsLabels(autoscaler)
+	elapsed := time.Since(startAt).Seconds()
+	metricReplicatorDuration.WithLabelValues(namespace, name, replicator).Observe(elapsed)
+	metricPluginElapsed.WithLabelValues(namespace, name, replicator, "replicator").Add(elapsed)
+}
+
+func (r *ReplicaAutoscalerReconciler) countScalerError(autoscaler *wingv1.ReplicaAutoscaler, scaler, reason string) {
+	namespace, name := r.getMetricsLabels(autoscaler)
+	metricScalerErrors.WithLabelValues(namespace, name, scaler, reason).Inc()
+}
+
+func (r *ReplicaAutoscalerReconciler) countScalingAction(autoscaler *wingv1.ReplicaAutoscaler,
+	fromReplicas, toReplicas int32, reason string) {
+	namespace, name := r.getMetricsLabels(autoscaler)
+	direction := "up"
+	if toReplicas < fromReplicas {
+		direction = "down"
+	}
+	metricScalingActions.WithLabelValues(namespace, name, direction, reason).Inc()
+}
+
+// exportedTargetMetrics is label values of target metrics exported per autoscaler,
+// which are deleted when targets are gone.
+type exportedTargetMetrics struct {
+	// targets are label values of target and scaler
+	targets [][2]string
+	// metrics are label values of target, scaler and metric type
+	metrics [][3]string
+}
+
+// recordAutoscalerMetrics sets per autoscaler gauges from status of reconciled autoscaler
+func (r *ReplicaAutoscalerReconciler) recordAutoscalerMetrics(autoscaler *wingv1.ReplicaAutoscaler) {
+	if r.Config.Metrics.LabelLevel != MetricsLabelLevelAutoscaler {
+		return
+	}
+	namespace, name := autoscaler.Namespace, autoscaler.Name
+	minReplicas := autoscaler.Spec.MaxReplicas
+	if autoscaler.Spec.MinReplicas != nil {
+		minReplicas = *autoscaler.Spec.MinReplicas
+	}
+	metricAutoscalerReplicas.WithLabelValues(namespace, name, "current").Set(float64(autoscaler.Status.CurrentReplicas))
+	metricAutoscalerReplicas.WithLabelValues(namespace, name, "desired").Set(float64(autoscaler.Status.DesiredReplicas))
+	metricAutoscalerReplicas.WithLabelValues(namespace, name, "min").Set(float64(minReplicas))
+	metricAutoscalerReplicas.WithLabelValues(namespace, name, "max").Set(float64(autoscaler.Spec.MaxReplicas))
+	metricAutoscalerPanicMode.WithLabelValues(namespace, name).Set(
+		conditionValue(autoscaler.Status.Conditions, wingv1.ConditionPanicMode))
+	metricAutoscalerExhausted.WithLabelValues(namespace, name).Set(
+		conditionValue(autoscaler.Status.Conditions, wingv1.ConditionExhausted))
+
+	if !r.Config.Metrics.TargetMetrics {
+		return
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var exported exportedTargetMetrics
+	for _, target := range autoscaler.Status.Targets {
+		metricTargetDesiredReplicas.WithLabelValues(namespace, name, target.Target, target.Scaler).
+			Set(float64(target.DesiredReplicas))
+		exported.targets = append(exported.targets, [2]string{target.Target, target.Scaler})
+		for metricType, value := range getTargetMetricValues(target.Metric) {
+			metricTargetMetricValue.WithLabelValues(namespace, name, target.Target, target.Scaler, metricType).Set(value)
+			exported.metrics = append(exported.metrics, [3]string{target.Target, target.Scaler, metricType})
+		}
+	}
+	if previous, ok := r.exportedTargetMetrics.Load(key); ok {
+		deleteTargetMetrics(key, previous.(exportedTargetMetrics), exported)
+	}
+	r.exportedTargetMetrics.Store(key, exported)
+}
+
+// perAutoscalerMetrics are metrics labeled by `namespace` and `replicaautoscaler`
+var perAutoscalerMetrics = []interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+}{
+	metricPluginElapsed,
+	metricScalerDuration,
+	metricReplicatorDuration,
+	metricScalerErrors,
+	metricScalingActions,
+	metricAutoscalerReplicas,
+	metricAutoscalerPanicMode,
+	metricAutoscalerExhausted,
+	metricTargetMetricValue,
+	metricTargetDesiredReplicas,
+}
+
+// forgetAutoscalerMetrics deletes series of autoscaler when autoscaler is deleted or handed over,
+// series aggregated by metrics label level are kept as autoscaler name is empty in them.
+func (r *ReplicaAutoscalerReconciler) forgetAutoscalerMetrics(key types.NamespacedName) {
+	r.exportedTargetMetrics.Delete(key)
+	labels := prometheus.Labels{"namespace": key.Namespace, "replicaautoscaler": key.Name}
+	for _, metric := range perAutoscalerMetrics {
+		metric.DeletePartialMatch(labels)
+	}
+}
+
+func deleteTargetMetrics(key types.NamespacedName, previous, current exportedTargetMetrics) {
+	keepingTargets := make(map[[2]string]bool, len(current.targets))
+	for _, labels := range current.targets {
+		keepingTargets[labels] = true
+	}
+	keepingMetrics := make(map[[3]string]bool, len(current.metrics))
+	for _, labels := range current.metrics {
+		keepingMetrics[labels] = true
+	}
+	for _, labels := range previous.targets {
+		if !keepingTargets[labels] {
+			metricTargetDesiredReplicas.DeleteLabelValues(key.Namespace, key.Name, labels[0], labels[1])
+		}
+	}
+	for _, labels := range previous.metrics {
+		if !keepingMetrics[labels] {
+			metricTargetMetricValue.DeleteLabelValues(key.Namespace, key.Name, labels[0], labels[1], labels[2])
+		}
+	}
+}
+
+func getTargetMetricValues(metric wingv1.MetricTarget) map[string]float64 {
+	values := make(map[string]float64)
+	if metric.Value != nil {
+		values["value"] = metric.Value.AsApproximateFloat64()
+	}
+	if metric.AverageValue != nil {
+		values["averageValue"] = metric.AverageValue.AsApproximateFloat64()
+	}
+	if metric.AverageUtilization != nil {
+		values["averageUtilization"] = float64(*metric.AverageUtilization)
+	}
+	return values
+}
+
+func conditionValue(conditions wingv1.Conditions, conditionType wingv1.ConditionType) float64 {
+	if wingv1.GetCondition(conditions, conditionType).Status == metav1.ConditionTrue {
+		return 1
+	}
+	return 0
 }
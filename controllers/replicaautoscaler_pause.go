@@ -0,0 +1,125 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/utils"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcilePause handles `paused-until` and `override-replicas` annotations.
+// Returns paused as true if autoscaling should be skipped.
+func (r *ReplicaAutoscalerReconciler) reconcilePause(ctx context.Context, logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler,
+	gvkr wingv1.GroupVersionKindResource, scale *autoscalingv1.Scale) (requeueDelay time.Duration, paused bool) {
+	now := r.now()
+	lastCondition := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionPaused)
+	wasPaused := lastCondition.Status == metav1.ConditionTrue
+
+	pausedUntil, err := utils.GetPausedUntil(*autoscaler)
+	if err != nil {
+		logger.Error(err, "Failed to get paused until, ignore pause")
+	}
+	if pausedUntil != nil && now.Before(*pausedUntil) {
+		message := fmt.Sprintf("Paused at replicas %d until %s", scale.Spec.Replicas, pausedUntil.Format(time.RFC3339))
+		if !wasPaused || lastCondition.Reason != "Paused" {
+			logger.Info("Enter pause", "until", pausedUntil)
+			r.EventRecorder.Event(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonPaused, message)
+		}
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:    wingv1.ConditionPaused,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Paused",
+			Message: message,
+		})
+		autoscaler.Status.DesiredReplicas = scale.Spec.Replicas
+		return getPauseRequeueDelay(now, *pausedUntil), true
+	}
+
+	replicaOverride, err := utils.GetReplicaOverride(*autoscaler)
+	if err != nil {
+		logger.Error(err, "Failed to get replica override, ignore override")
+	}
+	if replicaOverride != nil && now.Before(replicaOverride.Until.Time) {
+		message := fmt.Sprintf("Replicas overridden to %d until %s",
+			replicaOverride.Replicas, replicaOverride.Until.Format(time.RFC3339))
+		// Switching from pause to override is announced as well
+		if !wasPaused || lastCondition.Reason != "ReplicasOverridden" {
+			logger.Info("Enter replica override", "replicas", replicaOverride.Replicas, "until", replicaOverride.Until)
+			r.EventRecorder.Event(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonPaused, message)
+		}
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:    wingv1.ConditionPaused,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ReplicasOverridden",
+			Message: message,
+		})
+		trace := engine.NewDecisionTrace()
+		trace.Record(wingv1.DecisionStageLimit, "override", replicaOverride.Replicas, "%s", message)
+		overrideReplicas := r.limitOverrideReplicas(logger, autoscaler, scale.Spec.Replicas, replicaOverride.Replicas, trace)
+		setLastDecision(&autoscaler.Status, scale.Spec.Replicas, overrideReplicas, false,
+			r.isDryRun(autoscaler) && scale.Spec.Replicas != overrideReplicas, trace)
+		if err = r.scaleReplicas(ctx, logger, autoscaler, gvkr,
+			scale.DeepCopy(), overrideReplicas, "ReplicasOverridden"); err != nil {
+			return RequeueDelayOnErrorState, true
+		}
+		return getPauseRequeueDelay(now, replicaOverride.Until.Time), true
+	}
+
+	if wasPaused {
+		logger.Info("Exit pause")
+		r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonPaused,
+			"Resumed autoscaling at replicas %d", scale.Spec.Replicas)
+	}
+	autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+		Type:   wingv1.ConditionPaused,
+		Status: metav1.ConditionFalse,
+	})
+	return NotRequeue, false
+}
+
+// limitOverrideReplicas limits override replicas by policy ceiling, replica budget and namespace quota,
+// which are hard limits that can't be bypassed by override.
+func (r *ReplicaAutoscalerReconciler) limitOverrideReplicas(logger logr.Logger, autoscaler *wingv1.ReplicaAutoscaler,
+	currentReplicas, overrideReplicas int32, trace *engine.DecisionTrace) int32 {
+	if policyStatus := autoscaler.Status.Policy; policyStatus != nil && policyStatus.MaxReplicasCeiling != nil &&
+		overrideReplicas > *policyStatus.MaxReplicasCeiling {
+		overrideReplicas = *policyStatus.MaxReplicasCeiling
+		trace.Record(wingv1.DecisionStageLimit, "policy", overrideReplicas,
+			"limited by policy ceiling %d", overrideReplicas)
+	}
+	overrideReplicas, _ = r.limitReplicasByBudget(logger, autoscaler, overrideReplicas, 0, trace)
+	overrideReplicas, _ = r.limitReplicasByQuota(logger, autoscaler, currentReplicas, overrideReplicas, trace)
+	return overrideReplicas
+}
+
+// getPauseRequeueDelay requeues right after expiry to resume autoscaling in time
+func getPauseRequeueDelay(now, until time.Time) time.Duration {
+	if untilExpiry := until.Sub(now) + time.Second; untilExpiry < DefaultRequeueDelay {
+		return untilExpiry
+	}
+	return DefaultRequeueDelay
+}
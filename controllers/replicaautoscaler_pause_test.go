@@ -0,0 +1,181 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakescale "k8s.io/client-go/scale/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestReconcilePauseOverrideLimits(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	until := clock.Now().Add(time.Hour).Format(time.RFC3339)
+	for _, testCase := range []struct {
+		description      string
+		overrideReplicas int32
+		ceiling          *int32
+		budget           *int32
+
+		expectedReplicas int32
+		expectedSources  []string
+	}{
+		{
+			description:      "not limited",
+			overrideReplicas: 8,
+			ceiling:          pointer.Int32(10),
+			expectedReplicas: 8,
+			expectedSources:  []string{"override"},
+		},
+		{
+			description:      "limited by policy ceiling",
+			overrideReplicas: 20,
+			ceiling:          pointer.Int32(10),
+			expectedReplicas: 10,
+			expectedSources:  []string{"override", "policy"},
+		},
+		{
+			description:      "limited by replica budget",
+			overrideReplicas: 20,
+			ceiling:          pointer.Int32(10),
+			budget:           pointer.Int32(6),
+			expectedReplicas: 6,
+			expectedSources:  []string{"override", "policy", "budget"},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			replicas := int32(2)
+			scaleClient := &fakescale.FakeScaleClient{}
+			scaleClient.AddReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				scale := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+				replicas = scale.Spec.Replicas
+				return true, scale, nil
+			})
+			r := &ReplicaAutoscalerReconciler{
+				Config:        NewDefaultConfig().ReplicaAutoscalerControllerConfig,
+				EventRecorder: record.NewFakeRecorder(1024),
+				Cache:         &informertest.FakeInformers{},
+				Engine:        newTestEngine(t, clock, "http://127.0.0.1:1", true),
+				scaleClient:   scaleClient,
+			}
+			autoscaler := &wingv1.ReplicaAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sample", Annotations: map[string]string{
+					wingv1.OverrideReplicasAnnotation: fmt.Sprintf(`{"replicas":%d,"until":"%s"}`,
+						testCase.overrideReplicas, until),
+				}},
+				Spec: wingv1.ReplicaAutoscalerSpec{
+					ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test"},
+					MaxReplicas:    20,
+					ReplicaBudget:  testCase.budget,
+				},
+				Status: wingv1.ReplicaAutoscalerStatus{
+					Policy: &wingv1.AutoscalerPolicyStatus{MaxReplicasCeiling: testCase.ceiling},
+				},
+			}
+			gvkr := wingv1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+			scale := &autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+			}
+
+			_, paused := r.reconcilePause(context.TODO(), log.Log, autoscaler, gvkr, scale)
+			require.True(t, paused)
+			require.Equal(t, testCase.expectedReplicas, replicas)
+			require.Equal(t, testCase.expectedReplicas, autoscaler.Status.DesiredReplicas)
+			require.Equal(t, testCase.expectedReplicas, autoscaler.Status.LastDecision.DesiredReplicas)
+			var sources []string
+			for _, step := range autoscaler.Status.LastDecision.Steps {
+				sources = append(sources, step.Source)
+			}
+			require.Equal(t, testCase.expectedSources, sources)
+		})
+	}
+}
+
+func TestReconcilePauseEvents(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	until := clock.Now().Add(time.Hour).Format(time.RFC3339)
+	recorder := record.NewFakeRecorder(1024)
+	r := &ReplicaAutoscalerReconciler{
+		Config:        NewDefaultConfig().ReplicaAutoscalerControllerConfig,
+		EventRecorder: recorder,
+		Cache:         &informertest.FakeInformers{},
+		Engine:        newTestEngine(t, clock, "http://127.0.0.1:1", true),
+		scaleClient:   &fakescale.FakeScaleClient{},
+	}
+	autoscaler := &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sample", Annotations: map[string]string{}},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test"},
+			MaxReplicas:    20,
+		},
+	}
+	gvkr := wingv1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: 2},
+	}
+
+	for i, step := range []struct {
+		pausedUntil    string
+		override       string
+		expectedPaused bool
+		expectedEvent  string
+	}{
+		{pausedUntil: until, expectedPaused: true, expectedEvent: "Paused at replicas 2"},
+		// No duplicated event
+		{pausedUntil: until, expectedPaused: true},
+		// Switching from pause to override directly
+		{override: `{"replicas":2,"until":"` + until + `"}`, expectedPaused: true,
+			expectedEvent: "Replicas overridden to 2"},
+		{override: `{"replicas":2,"until":"` + until + `"}`, expectedPaused: true},
+		{pausedUntil: until, expectedPaused: true, expectedEvent: "Paused at replicas 2"},
+		{expectedEvent: "Resumed autoscaling at replicas 2"},
+	} {
+		delete(autoscaler.Annotations, wingv1.PausedUntilAnnotation)
+		delete(autoscaler.Annotations, wingv1.OverrideReplicasAnnotation)
+		if step.pausedUntil != "" {
+			autoscaler.Annotations[wingv1.PausedUntilAnnotation] = step.pausedUntil
+		}
+		if step.override != "" {
+			autoscaler.Annotations[wingv1.OverrideReplicasAnnotation] = step.override
+		}
+		_, paused := r.reconcilePause(context.TODO(), log.Log, autoscaler, gvkr, scale)
+		require.Equal(t, step.expectedPaused, paused, "step %d", i)
+		if step.expectedEvent == "" {
+			require.Len(t, recorder.Events, 0, "step %d", i)
+		} else {
+			require.Len(t, recorder.Events, 1, "step %d", i)
+			require.Contains(t, <-recorder.Events, step.expectedEvent, "step %d", i)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/policy"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=wing.xscaling.dev,resources=clusterreplicaautoscalerpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="core",resources=namespaces,verbs=get;list;watch
+
+// reconcilePolicies applies ClusterReplicaAutoscalerPolicies selecting autoscaler to its spec, which is
+// never written back since only annotations and status are patched, and reports effective settings in status.
+func (r *ReplicaAutoscalerReconciler) reconcilePolicies(ctx context.Context, logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler) {
+	policies := &wingv1.ClusterReplicaAutoscalerPolicyList{}
+	if err := r.Cache.List(ctx, policies); err != nil {
+		logger.Error(err, "Failed to list ClusterReplicaAutoscalerPolicies, ignore policies")
+		autoscaler.Status.Policy = nil
+		return
+	}
+	var namespaceLabels map[string]string
+	if policy.RequiresNamespaceLabels(policies.Items) {
+		namespace := &corev1.Namespace{}
+		if err := r.Cache.Get(ctx, types.NamespacedName{Name: autoscaler.Namespace}, namespace); err != nil {
+			logger.Error(err, "Failed to get namespace for policies, ignore namespace selectors")
+		}
+		namespaceLabels = namespace.Labels
+	}
+
+	selecting := policy.GetSelectingPolicies(policies.Items, namespaceLabels, *autoscaler)
+	policyStatus, err := policy.ApplyPolicies(autoscaler, selecting, DefaultScalingColdDown)
+	if err != nil {
+		logger.Error(err, "Failed to apply defaults of policies")
+	}
+	autoscaler.Status.Policy = policyStatus
+}
+
+// getScalingColdDown returns cold-down between scaling, which could be raised by policies
+func getScalingColdDown(autoscaler *wingv1.ReplicaAutoscaler) time.Duration {
+	if autoscaler.Status.Policy != nil {
+		return time.Duration(autoscaler.Status.Policy.ScalingColdDownSeconds) * time.Second
+	}
+	return DefaultScalingColdDown
+}
+
+// isScalerDisallowed returns whether scaler of target is not allowed by policies
+func isScalerDisallowed(autoscaler *wingv1.ReplicaAutoscaler, scaler string) bool {
+	if autoscaler.Status.Policy == nil {
+		return false
+	}
+	for _, disallowed := range autoscaler.Status.Policy.DisallowedScalers {
+		if disallowed == scaler {
+			return true
+		}
+	}
+	return false
+}
+
+// mapPolicyToAutoscalers enqueues autoscalers selected by labels, namespace selector is left to reconcile
+func (r *ReplicaAutoscalerReconciler) mapPolicyToAutoscalers(object runtimeclient.Object) []reconcile.Request {
+	p, ok := object.(*wingv1.ClusterReplicaAutoscalerPolicy)
+	if !ok {
+		return nil
+	}
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers); err != nil {
+		log.Log.Error(err, "Failed to list ReplicaAutoscaler for policy", "name", p.Name)
+		return nil
+	}
+	// Namespace selector is ignored here
+	policyWithoutNamespaceSelector := p.DeepCopy()
+	policyWithoutNamespaceSelector.Spec.NamespaceSelector = nil
+	return getAutoscalerRequests(autoscalers.Items, func(autoscaler *wingv1.ReplicaAutoscaler) bool {
+		selected, err := policy.IsPolicySelecting(*policyWithoutNamespaceSelector, nil, *autoscaler)
+		return err == nil && selected
+	})
+}
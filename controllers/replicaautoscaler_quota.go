@@ -0,0 +1,92 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/utils"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileAutoscalerQuota checks whether the autoscaler is within namespace autoscaler quota.
+// Returns true if scaling should be refused.
+func (r *ReplicaAutoscalerReconciler) reconcileAutoscalerQuota(logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler) bool {
+	quota := r.Config.Quota.Get(autoscaler.Namespace)
+	if quota.MaxAutoscalers <= 0 {
+		return false
+	}
+	autoscalers, err := r.listNamespaceAutoscalers(autoscaler.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to list autoscalers for quota, ignore quota")
+		return false
+	}
+	if utils.IsWithinAutoscalerQuota(autoscalers, autoscaler, quota.MaxAutoscalers) {
+		return false
+	}
+	message := fmt.Sprintf("Namespace autoscaler quota %d exceeded", quota.MaxAutoscalers)
+	if lastCondition := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionReady); lastCondition.Reason != "QuotaExceeded" {
+		logger.Info("Namespace autoscaler quota exceeded", "maxAutoscalers", quota.MaxAutoscalers)
+		r.EventRecorder.Event(autoscaler, wingv1.EventTypeWarning, wingv1.EventReasonQuotaExceeded, message)
+	}
+	autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+		Type:    wingv1.ConditionReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "QuotaExceeded",
+		Message: message,
+	})
+	return true
+}
+
+// limitReplicasByQuota prevents scaling up over namespace replica quota
+func (r *ReplicaAutoscalerReconciler) limitReplicasByQuota(logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler, currentReplicas, desiredReplicas int32,
+	trace *engine.DecisionTrace) (replicas int32, limited bool) {
+	quota := r.Config.Quota.Get(autoscaler.Namespace)
+	if quota.MaxReplicas <= 0 || desiredReplicas <= currentReplicas {
+		return desiredReplicas, false
+	}
+	autoscalers, err := r.listNamespaceAutoscalers(autoscaler.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to list autoscalers for quota, ignore quota")
+		return desiredReplicas, false
+	}
+	budget := utils.GetReplicaQuotaBudget(autoscalers, autoscaler, quota.MaxReplicas)
+	replicas, limited = utils.LimitReplicasByQuota(currentReplicas, desiredReplicas, autoscaler.Spec.Members, budget)
+	if limited {
+		trace.Record(wingv1.DecisionStageLimit, "quota", replicas,
+			"limited by namespace replica quota %d with budget %d", quota.MaxReplicas, budget)
+		logger.V(4).Info("Desired replicas exceed namespace replica quota",
+			"desiredReplicas", desiredReplicas, "maxReplicas", quota.MaxReplicas, "budget", budget)
+	}
+	return replicas, limited
+}
+
+func (r *ReplicaAutoscalerReconciler) listNamespaceAutoscalers(namespace string) ([]wingv1.ReplicaAutoscaler, error) {
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers, runtimeclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	return autoscalers.Items, nil
+}
@@ -19,14 +19,18 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/group"
 	"github.com/xscaling/wing/core/scheduling"
 	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/tracing"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -34,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -42,20 +47,28 @@ const (
 	RequeueDelayOnErrorState  = time.Second * 30
 	RequeueDelayOnNormalState = time.Second * 60
 	RequeueDelayOnPanicState  = time.Second * 15
+	RequeueDelayOnRampState   = time.Second * 15
 
 	DefaultScalingColdDown = time.Second * 30
 
 	DefaultReplicator = "simple"
 )
 
-func (r *ReplicaAutoscalerReconciler) reconcile(logger logr.Logger,
+// now returns time of reconciling from clock of engine, so that scheduling, panic mode
+// and tuners work on the same clock which could be faked.
+func (r *ReplicaAutoscalerReconciler) now() time.Time {
+	return r.Engine.GetClock().Now()
+}
+
+func (r *ReplicaAutoscalerReconciler) reconcile(ctx context.Context, logger logr.Logger,
 	autoscaler *wingv1.ReplicaAutoscaler) (requeueDelay time.Duration) {
 	if autoscaler.DeletionTimestamp != nil {
 		logger.V(2).Info("Found terminating autoscaler turn finalizer")
 		return r.finalizeAutoscaler(logger, autoscaler)
 	}
+	r.reconcilePolicies(ctx, logger, autoscaler)
 
-	gvkr, scale, err := r.getScaleTarget(logger, autoscaler)
+	gvkr, scale, err := r.getScaleTarget(logger, autoscaler.Namespace, autoscaler.Spec.ScaleTargetRef)
 	if err != nil {
 		logger.Error(err, "Unable to get scale target")
 		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
@@ -67,6 +80,30 @@ func (r *ReplicaAutoscalerReconciler) reconcile(logger logr.Logger,
 
 		return NotRequeue
 	}
+	if err = group.ValidateMembers(autoscaler.Spec.Members); err != nil {
+		logger.Error(err, "Invalid members")
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:    wingv1.ConditionReady,
+			Reason:  "InvalidMembers",
+			Message: err.Error(),
+			Status:  metav1.ConditionFalse,
+		})
+		return NotRequeue
+	}
+
+	if r.reconcileConflict(logger, autoscaler) {
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:    wingv1.ConditionReady,
+			Reason:  "ScaleTargetConflicted",
+			Message: wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionConflict).Message,
+			Status:  metav1.ConditionFalse,
+		})
+		return DefaultRequeueDelay
+	}
+
+	if r.reconcileAutoscalerQuota(logger, autoscaler) {
+		return DefaultRequeueDelay
+	}
 
 	requeueDelay, err = r.updateExhaustedAutoscaler(logger, autoscaler, scale)
 	if err != nil {
@@ -76,18 +113,51 @@ func (r *ReplicaAutoscalerReconciler) reconcile(logger logr.Logger,
 
 	autoscaler.Status.ObservedGeneration = &autoscaler.Generation
 	autoscaler.Status.CurrentReplicas = scale.Status.Replicas
+	autoscaler.Status.Selector = scale.Status.Selector
+	autoscaler.Status.ExpiredSchedules = getExpiredSchedules(r.now(), autoscaler.Spec.Targets)
 	// TODO(@oif): Init various
 
+	if pauseRequeueDelay, paused := r.reconcilePause(ctx, logger, autoscaler, gvkr, scale); paused {
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:   wingv1.ConditionReady,
+			Status: metav1.ConditionTrue,
+		})
+		return pauseRequeueDelay
+	}
+
 	// A static replicas setting
 	if autoscaler.Spec.MinReplicas == nil {
 		logger.V(2).Info("Setting static replicas")
-		if err = r.scaleReplicas(logger, autoscaler, gvkr,
-			scale.DeepCopy(), autoscaler.Spec.MaxReplicas); err != nil {
+		// Static replicas ignore replica patches
+		autoscaler.Status.ReplicaPatch = nil
+		autoscaler.Status.ReplicaRamp = nil
+		trace := engine.NewDecisionTrace()
+		trace.Record(wingv1.DecisionStageLimit, "static", autoscaler.Spec.MaxReplicas,
+			"static replicas without autoscaling")
+		staticReplicas, budgetLimited := r.limitReplicasByBudget(logger, autoscaler,
+			autoscaler.Spec.MaxReplicas, wingv1.DefaultMinReplicas, trace)
+		staticReplicas, quotaLimited := r.limitReplicasByQuota(logger, autoscaler,
+			scale.Spec.Replicas, staticReplicas, trace)
+		scalingReason := "StaticReplicas"
+		if budgetLimited {
+			scalingReason = "ReachReplicaBudget"
+		}
+		if quotaLimited {
+			scalingReason = "ReachNamespaceQuota"
+		}
+		dryRun := r.isDryRun(autoscaler) && scale.Spec.Replicas != staticReplicas
+		if dryRun {
+			trace.Record(wingv1.DecisionStageDryRun, "", scale.Spec.Replicas,
+				"scaling %d -> %d is skipped", scale.Spec.Replicas, staticReplicas)
+		}
+		setLastDecision(&autoscaler.Status, scale.Spec.Replicas, staticReplicas, false, dryRun, trace)
+		if err = r.scaleReplicas(ctx, logger, autoscaler, gvkr,
+			scale.DeepCopy(), staticReplicas, scalingReason); err != nil {
 			requeueDelay = RequeueDelayOnErrorState
 		}
 	} else {
 		// Working on autoscaling flow
-		requeueDelay = r.reconcileAutoscaling(logger, autoscaler, gvkr, scale)
+		requeueDelay = r.reconcileAutoscaling(ctx, logger, autoscaler, gvkr, scale)
 	}
 
 	autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
@@ -97,21 +167,35 @@ func (r *ReplicaAutoscalerReconciler) reconcile(logger logr.Logger,
 	return requeueDelay
 }
 
+// getExpiredSchedules returns ended date schedules of targets to report in status
+func getExpiredSchedules(now time.Time, targets []wingv1.ReplicaAutoscalerTarget) []wingv1.ExpiredSchedule {
+	var expiredSchedules []wingv1.ExpiredSchedule
+	for _, target := range targets {
+		for _, index := range scheduling.GetExpiredScheduleIndexes(now, target.Settings.Schedules) {
+			expiredSchedules = append(expiredSchedules, wingv1.ExpiredSchedule{
+				Target: target.Metric,
+				Index:  int32(index),
+				End:    target.Settings.Schedules[index].End,
+			})
+		}
+	}
+	return expiredSchedules
+}
+
 func (r *ReplicaAutoscalerReconciler) getScaleTarget(logger logr.Logger,
-	autoscaler *wingv1.ReplicaAutoscaler) (wingv1.GroupVersionKindResource, *autoscalingv1.Scale, error) {
+	namespace string, ref wingv1.CrossVersionObjectReference) (wingv1.GroupVersionKindResource, *autoscalingv1.Scale, error) {
 	// Check is target ref is a scalable object
-	if autoscaler.Spec.ScaleTargetRef.Name == "" || autoscaler.Spec.ScaleTargetRef.Kind == "" {
-		logger.Info("autoscaler.Spec.ScaleTargetRef.Name or autoscaler.Spec.ScaleTargetRef.Kind missing")
+	if ref.Name == "" || ref.Kind == "" {
+		logger.Info("ScaleTargetRef.Name or ScaleTargetRef.Kind missing")
 		return wingv1.GroupVersionKindResource{}, nil, fmt.Errorf(
-			"autoscaler.Spec.ScaleTargetRef.Name or autoscaler.Spec.ScaleTargetRef.Kind missing")
+			"ScaleTargetRef.Name or ScaleTargetRef.Kind missing")
 	}
-	gvkr, err := utils.ParseGVKR(r.restMapper,
-		autoscaler.Spec.ScaleTargetRef.APIVersion, autoscaler.Spec.ScaleTargetRef.Kind)
+	gvkr, err := utils.ParseGVKR(r.restMapper, ref.APIVersion, ref.Kind)
 	if err != nil {
 		logger.Error(err, "Failed to parse GVKR")
 		return wingv1.GroupVersionKindResource{}, nil, err
 	}
-	scale, err := r.isTargetScalable(gvkr, autoscaler.Namespace, autoscaler.Spec.ScaleTargetRef.Name)
+	scale, err := r.isTargetScalable(gvkr, namespace, ref.Name)
 	if err != nil {
 		logger.Error(err, "Target is unscalable", "target", gvkr.GVKString())
 		return wingv1.GroupVersionKindResource{}, nil, err
@@ -147,44 +231,87 @@ func (r *ReplicaAutoscalerReconciler) isTargetScalable(gvkr wingv1.GroupVersionK
 	return scale, nil
 }
 
-func (r *ReplicaAutoscalerReconciler) scaleReplicas(logger logr.Logger,
+// scaleReplicas scales target to desired replicas, then members following it
+func (r *ReplicaAutoscalerReconciler) scaleReplicas(ctx context.Context, logger logr.Logger,
 	autoscaler *wingv1.ReplicaAutoscaler,
-	gvkr wingv1.GroupVersionKindResource, scale *autoscalingv1.Scale, desiredReplicas int32) error {
+	gvkr wingv1.GroupVersionKindResource, scale *autoscalingv1.Scale, desiredReplicas int32, reason string) error {
 	autoscaler.Status.DesiredReplicas = desiredReplicas
 
+	fromReplicas := scale.Spec.Replicas
+	scaled, err := r.updateScale(ctx, logger, autoscaler, gvkr, scale, desiredReplicas)
+	if err != nil {
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:    wingv1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "FailedToScale",
+			Message: fmt.Sprintf("Failed to scale target: %s", err),
+		})
+		logger.Error(err, "Failed to scale target")
+		return err
+	}
+	// Scale time is not stamped under dry run as nothing happens, neither for members,
+	// so dry run doesn't enter cold-down.
+	if scaled && !r.isDryRun(autoscaler) {
+		r.recordScalingHistory(autoscaler, fromReplicas, desiredReplicas, reason)
+		r.countScalingAction(autoscaler, fromReplicas, desiredReplicas, reason)
+		now := metav1.NewTime(r.now())
+		autoscaler.Status.LastScaleTime = &now
+	}
+	return r.scaleMembers(ctx, logger, autoscaler, desiredReplicas)
+}
+
+// updateScale updates replicas of scale target if it's not desired, returns whether scaling is performed,
+// which is skipped under dry run.
+func (r *ReplicaAutoscalerReconciler) updateScale(ctx context.Context, logger logr.Logger,
+	autoscaler *wingv1.ReplicaAutoscaler,
+	gvkr wingv1.GroupVersionKindResource, scale *autoscalingv1.Scale, desiredReplicas int32) (bool, error) {
 	if scale.Spec.Replicas == desiredReplicas {
-		logger.V(8).Info("Current replicas is expected, nothing todo")
-		return nil
+		logger.V(8).Info("Current replicas is expected, nothing todo", "target", scale.Name)
+		return false, nil
 	}
-	logger.V(2).Info("Scaling replicas",
+	logger.V(2).Info("Scaling replicas", "target", scale.Name,
 		"currentReplicas", scale.Spec.Replicas, "desireReplicas", desiredReplicas)
-	// Allowing setting dry-run by ReplicaAutoscaler or global dry-run by controller
-	if autoscaler.Annotations[wingv1.DryRunAnnotation] == "true" || r.DryRun {
-		logger.V(4).Info("Dry run scaling replicas",
+	if r.isDryRun(autoscaler) {
+		logger.V(4).Info("Dry run scaling replicas", "target", scale.Name,
 			"currentReplicas", scale.Spec.Replicas, "desireReplicas", desiredReplicas)
-	} else {
-		logger.V(4).Info("Performing scaling action")
-		scale.Spec.Replicas = desiredReplicas
-		_, err := r.scaleClient.Scales(scale.Namespace).Update(
-			context.TODO(), gvkr.GroupResource(), scale.DeepCopy(), metav1.UpdateOptions{})
-		if err != nil {
-			autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
-				Type:    wingv1.ConditionReady,
-				Status:  metav1.ConditionFalse,
-				Reason:  "FailedToScale",
-				Message: fmt.Sprintf("Failed to scale target: %s", err),
-			})
-			logger.Error(err, "Failed to scale target")
-			return err
-		}
+		return true, nil
+	}
+	logger.V(4).Info("Performing scaling action")
+	fromReplicas := scale.Spec.Replicas
+	scale.Spec.Replicas = desiredReplicas
+	updateCtx, span := tracing.StartSpan(ctx, "ScaleTarget.Update",
+		attribute.String("resource", gvkr.GroupResource().String()),
+		attribute.Int("fromReplicas", int(fromReplicas)), attribute.Int("toReplicas", int(desiredReplicas)))
+	_, err := r.scaleClient.Scales(scale.Namespace).Update(
+		updateCtx, gvkr.GroupResource(), scale.DeepCopy(), metav1.UpdateOptions{})
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	now := metav1.NewTime(time.Now())
-	autoscaler.Status.LastScaleTime = &now
-	return nil
+func (r *ReplicaAutoscalerReconciler) recordScalingHistory(autoscaler *wingv1.ReplicaAutoscaler,
+	fromReplicas, toReplicas int32, reason string) {
+	if r.Config.ScalingHistory.Limit <= 0 {
+		autoscaler.Status.ScalingHistory = nil
+		return
+	}
+	record := wingv1.ScalingRecord{
+		Time:         metav1.NewTime(r.now()),
+		FromReplicas: fromReplicas,
+		ToReplicas:   toReplicas,
+		Reason:       reason,
+	}
+	if decision := autoscaler.Status.LastDecision; decision != nil {
+		record.Target = getWinningTarget(decision.Steps)
+		record.PanicMode = decision.PanicMode
+	}
+	utils.AddScalingHistory(&autoscaler.Status, record,
+		r.Config.ScalingHistory.Limit, r.Config.ScalingHistory.Retention)
 }
 
-func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
+func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(ctx context.Context, logger logr.Logger,
 	autoscaler *wingv1.ReplicaAutoscaler,
 	gvkr wingv1.GroupVersionKindResource, scale *autoscalingv1.Scale) (requeueDelay time.Duration) {
 	scaledObjectSelector, err := labels.Parse(scale.Status.Selector)
@@ -193,34 +320,61 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 		return RequeueDelayOnErrorState
 	}
 
+	now := r.now()
 	// Checking cold-down
-	underPanicModeCurrently := utils.StillInPanicMode(autoscaler.Status, autoscaler.Spec.Strategy)
+	underPanicModeCurrently := utils.StillInPanicMode(now, autoscaler.Status, autoscaler.Spec.Strategy)
 	if autoscaler.Status.LastScaleTime != nil &&
-		time.Since(autoscaler.Status.LastScaleTime.Time) < DefaultScalingColdDown &&
+		now.Sub(autoscaler.Status.LastScaleTime.Time) < getScalingColdDown(autoscaler) &&
 		// Not in panic mode
 		!underPanicModeCurrently {
 		logger.V(8).Info("Still in scaling cold-down period")
-		return DefaultRequeueDelay
+		// Reconcile right after cold-down or upcoming transition, whichever comes first
+		requeueDelay = DefaultRequeueDelay
+		if untilColdDownEnd := autoscaler.Status.LastScaleTime.Add(getScalingColdDown(autoscaler)).Sub(now); untilColdDownEnd < requeueDelay {
+			requeueDelay = untilColdDownEnd
+		}
+		// Calendars referring ConfigMaps are left unresolved to save API calls within cold-down,
+		// periods of theirs are ignored as cold-down ends within the requeue delay anyway.
+		replicaPatches, _, _ := r.getReplicaPatches(ctx, autoscaler, false)
+		return getRequeueDelayTillTransition(now, requeueDelay,
+			getNextTransition(now, autoscaler, autoscaler.Spec.Targets, replicaPatches))
 	}
 
-	now := time.Now()
+	// Calendars of schedules and replica patches referring ConfigMaps are resolved on a copy for scheduling
+	scheduledAutoscaler := autoscaler.DeepCopy()
+	if err = utils.ResolveAutoscalerCalendars(ctx, r.APIReader, scheduledAutoscaler); err != nil {
+		logger.Error(err, "Failed to resolve schedule calendars")
+	}
+	replicaPatches, replicaPatchSources, replicaPatchesErr := r.getReplicaPatches(ctx, scheduledAutoscaler, true)
 
-	replicatorContext := engine.NewReplicatorContext(autoscaler, scale)
+	trace := engine.NewDecisionTrace()
+	replicatorContext := engine.NewReplicatorContext(ctx, r.Engine.GetClock(), autoscaler, scale, trace)
 
 	var managedTargetStatus []string
 
-	for _, target := range autoscaler.Spec.Targets {
+	for _, target := range scheduledAutoscaler.Spec.Targets {
 		scalerStartAt := time.Now()
 		scheduledTargetSettings, err := scheduling.GetScheduledSettingsRaw(now, target.Settings)
 		if err != nil {
 			logger.Error(err, "Failed to get scheduled target settings", "targetMetric", target.Metric)
+			r.countScalerError(autoscaler, target.Metric, scalerErrorReasonInvalidSettings)
 			return RequeueDelayOnErrorState
 		}
 		logger.V(8).Info("Get scheduled target settings",
 			"settings", string(scheduledTargetSettings), "metric", target.Metric)
 
+		if isScalerDisallowed(autoscaler, target.Metric) {
+			autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+				Type:    wingv1.ConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "ScalerNotAllowed",
+				Message: fmt.Sprintf("Scaler `%s` is not allowed by policies", target.Metric),
+			})
+			return DefaultRequeueDelay
+		}
 		scaler, ok := r.Engine.GetScaler(target.Metric)
 		if !ok {
+			r.countScalerError(autoscaler, target.Metric, scalerErrorReasonNotExists)
 			autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
 				Type:    wingv1.ConditionReady,
 				Status:  metav1.ConditionFalse,
@@ -230,7 +384,10 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 			return DefaultRequeueDelay
 		}
 		// Getting desired replicas from scaler
+		scalerCtx, span := tracing.StartSpan(ctx, "Scaler.Get", attribute.String("scaler", target.Metric))
 		scalerOutput, err := scaler.Get(engine.ScalerContext{
+			Context:              scalerCtx,
+			Clock:                r.Engine.GetClock(),
 			InformerFactory:      r.Engine.InformerFactory,
 			RawSettings:          scheduledTargetSettings,
 			Namespace:            autoscaler.Namespace,
@@ -238,13 +395,17 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 			CurrentReplicas:      scale.Spec.Replicas,
 			AutoscalerStatus:     &autoscaler.Status,
 		})
+		tracing.EndSpan(span, err)
+		r.observeScalerDuration(autoscaler, target.Metric, scalerStartAt)
 		if err != nil {
 			logger.Error(err, "Failed to get result from scaler", "scaler", target.Metric)
+			r.countScalerError(autoscaler, target.Metric, scalerErrorReasonFailed)
 			return RequeueDelayOnErrorState
 		}
 		replicatorContext.ScalersOutput[target.Metric] = *scalerOutput
+		trace.Record(wingv1.DecisionStageScaler, target.Metric, scalerOutput.DesiredReplicas,
+			"calculated by scaler with %d target status", len(scalerOutput.ManagedTargetStatus))
 		managedTargetStatus = append(managedTargetStatus, scalerOutput.ManagedTargetStatus...)
-		metricPluginElapsed.WithLabelValues(autoscaler.Namespace, autoscaler.Name, target.Metric, "scaler").Add(time.Since(scalerStartAt).Seconds())
 	}
 
 	// Purge unused scaler targetStatus
@@ -254,9 +415,7 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 	if autoscaler.Spec.Replicator != nil {
 		selectedReplicator = *autoscaler.Spec.Replicator
 	}
-	replicatorStartAt := time.Now()
 	replicator, ok := r.Engine.GetReplicator(selectedReplicator)
-	metricPluginElapsed.WithLabelValues(autoscaler.Namespace, autoscaler.Name, selectedReplicator, "replicator").Add(time.Since(replicatorStartAt).Seconds())
 	if !ok {
 		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
 			Type:    wingv1.ConditionReady,
@@ -268,7 +427,13 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 		return NotRequeue
 	}
 
+	replicatorStartAt := time.Now()
+	replicatorCtx, span := tracing.StartSpan(ctx, "Replicator.GetDesiredReplicas",
+		attribute.String("replicator", selectedReplicator))
+	replicatorContext.Context = replicatorCtx
 	desiredReplicas, err := replicator.GetDesiredReplicas(replicatorContext)
+	tracing.EndSpan(span, err)
+	r.observeReplicatorDuration(autoscaler, selectedReplicator, replicatorStartAt)
 	if err != nil {
 		logger.Error(err, "Failed to get desired replicas from replicator", "replicator", selectedReplicator)
 		return RequeueDelayOnErrorState
@@ -292,7 +457,17 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 	})
 
 	// Trying replica patch
-	workingReplicaPatch, err := getWorkingReplicaPatch(autoscaler)
+	err = replicaPatchesErr
+	var workingReplicaPatch *wingv1.ReplicaPatchStatus
+	if err == nil {
+		workingReplicaPatch, err = scheduling.GetEffectiveReplicaPatch(now, replicaPatches, replicaPatchSources,
+			autoscaler.Spec.ReplicaPatchMode)
+	}
+	previousReplicaPatch := autoscaler.Status.ReplicaPatch
+	autoscaler.Status.ReplicaPatch = workingReplicaPatch
+	// Ramp starts from the range applied previously on entering or exiting patch
+	autoscaler.Status.ReplicaRamp = scheduling.GetReplicaRamp(now, autoscaler.Status.ReplicaRamp,
+		previousReplicaPatch, workingReplicaPatch, minReplicas, maxReplicas)
 	if err != nil {
 		logger.Error(err, "Failed to get working replica patch, fallback to default")
 	} else if workingReplicaPatch == nil {
@@ -306,25 +481,68 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 			Status: metav1.ConditionTrue,
 			Reason: fmt.Sprintf("Applied replica patch [%d, %d]", minReplicas, maxReplicas),
 		})
+		trace.Record(wingv1.DecisionStageReplicaPatch, "", desiredReplicas,
+			"scaling range patched to [%d, %d] by %s of %s", minReplicas, maxReplicas,
+			workingReplicaPatch.Mode, strings.Join(workingReplicaPatch.Sources, ", "))
+	}
+	if ramp := autoscaler.Status.ReplicaRamp; ramp != nil {
+		minReplicas, maxReplicas = scheduling.GetRampedReplicaRange(now, *ramp)
+		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+			Type:   wingv1.ConditionReplicaPatched,
+			Status: metav1.ConditionTrue,
+			Reason: fmt.Sprintf("Ramping replica patch [%d, %d] towards [%d, %d]",
+				minReplicas, maxReplicas, ramp.ToMinReplicas, ramp.ToMaxReplicas),
+		})
+		trace.Record(wingv1.DecisionStageReplicaPatch, "", desiredReplicas,
+			"scaling range ramped to [%d, %d] towards [%d, %d]", minReplicas, maxReplicas,
+			ramp.ToMinReplicas, ramp.ToMaxReplicas)
 	}
 
+	if policyStatus := autoscaler.Status.Policy; policyStatus != nil && policyStatus.MaxReplicasCeiling != nil &&
+		maxReplicas > *policyStatus.MaxReplicasCeiling {
+		maxReplicas = *policyStatus.MaxReplicasCeiling
+		if minReplicas > maxReplicas {
+			minReplicas = maxReplicas
+		}
+		trace.Record(wingv1.DecisionStageLimit, "policy", desiredReplicas,
+			"scaling range limited by policy ceiling %d", maxReplicas)
+	}
 	if desiredReplicas > maxReplicas {
 		desiredReplicas = maxReplicas
 		scalingLimitedReason = "ReachMaxReplicas"
+		trace.Record(wingv1.DecisionStageLimit, "max", desiredReplicas, "limited by max replicas %d", maxReplicas)
 		logger.V(4).Info("Desired replicas exceed max replicas", "desiredReplicas", desiredReplicas, "maxReplicas", maxReplicas)
 	}
 	if desiredReplicas < minReplicas {
 		desiredReplicas = minReplicas
 		scalingLimitedReason = "ReachMinimalReplicas"
+		trace.Record(wingv1.DecisionStageLimit, "min", desiredReplicas, "limited by min replicas %d", minReplicas)
 		logger.V(4).Info("Desired replicas below min replicas", "desiredReplicas", desiredReplicas, "minReplicas", minReplicas)
 	}
+	if budgetReplicas, limited := r.limitReplicasByBudget(logger, autoscaler,
+		desiredReplicas, minReplicas, trace); limited {
+		desiredReplicas = budgetReplicas
+		scalingLimitedReason = "ReachReplicaBudget"
+	}
+	if quotaReplicas, limited := r.limitReplicasByQuota(logger, autoscaler,
+		scale.Spec.Replicas, desiredReplicas, trace); limited {
+		desiredReplicas = quotaReplicas
+		scalingLimitedReason = "ReachNamespaceQuota"
+	}
+	dryRun := r.isDryRun(autoscaler) && scale.Spec.Replicas != desiredReplicas
+	if dryRun {
+		trace.Record(wingv1.DecisionStageDryRun, "", scale.Spec.Replicas,
+			"scaling %d -> %d is skipped", scale.Spec.Replicas, desiredReplicas)
+	}
 	if scale.Spec.Replicas != desiredReplicas {
 		if scale.Spec.Replicas < desiredReplicas {
 			// ScaleUp
-			r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonScaling, "New replica %d; resource(s) are requiring scale-up", desiredReplicas)
+			r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonScaling,
+				"New replica %d; resource(s) are requiring scale-up; decision: %s", desiredReplicas, trace.Summary())
 		} else {
 			// ScaleDown
-			r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonScaling, "New replica %d; all resources are below target trying to scale-down", desiredReplicas)
+			r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeNormal, wingv1.EventReasonScaling,
+				"New replica %d; all resources are below target trying to scale-down; decision: %s", desiredReplicas, trace.Summary())
 		}
 		logger.Info("Decide to scale target replicas", "from", scale.Spec.Replicas, "to", desiredReplicas)
 	}
@@ -341,13 +559,24 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 			Status: metav1.ConditionFalse,
 		})
 	}
-	if err := r.scaleReplicas(logger, autoscaler, gvkr, scale.DeepCopy(), desiredReplicas); err != nil {
+	// Checking should enter panic mode or not(ReplicaPatch aware)
+	shouldEnterPanicMode := utils.ShouldEnterPanicMode(desiredReplicas, scale.Spec.Replicas, autoscaler.Spec.Strategy)
+	setLastDecision(&autoscaler.Status, scale.Spec.Replicas, desiredReplicas,
+		shouldEnterPanicMode || underPanicModeCurrently, dryRun, trace)
+
+	scalingReason := scalingLimitedReason
+	if scalingReason == "" {
+		scalingReason = "ScaleUp"
+		if desiredReplicas < scale.Spec.Replicas {
+			scalingReason = "ScaleDown"
+		}
+	}
+	if err := r.scaleReplicas(ctx, logger, autoscaler, gvkr, scale.DeepCopy(), desiredReplicas, scalingReason); err != nil {
 		logger.Error(err, "Failed to scale replicas")
 		return RequeueDelayOnErrorState
 	}
+	nextTransition := getNextTransition(now, autoscaler, scheduledAutoscaler.Spec.Targets, replicaPatches)
 
-	// Checking should enter panic mode or not(ReplicaPatch aware)
-	shouldEnterPanicMode := utils.ShouldEnterPanicMode(desiredReplicas, scale.Spec.Replicas, autoscaler.Spec.Strategy)
 	if shouldEnterPanicMode {
 		if underPanicModeCurrently {
 			logger.V(4).Info("Still in panic mode")
@@ -358,14 +587,14 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 				scale.Spec.Replicas, desiredReplicas, autoscaler.Spec.Strategy.PanicThreshold.AsApproximateFloat64(),
 				time.Duration(*autoscaler.Spec.Strategy.PanicWindowSeconds)*time.Second)
 		}
-		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+		autoscaler.Status.Conditions = wingv1.SetConditionAt(autoscaler.Status.Conditions, wingv1.Condition{
 			Type:   wingv1.ConditionPanicMode,
 			Status: metav1.ConditionTrue,
-		})
-		return RequeueDelayOnPanicState
+		}, now)
+		return getRequeueDelayTillTransition(now, RequeueDelayOnPanicState, nextTransition)
 	}
 	// out of Panic Mode period
-	if !utils.StillInPanicMode(autoscaler.Status, autoscaler.Spec.Strategy) {
+	if !utils.StillInPanicMode(now, autoscaler.Status, autoscaler.Spec.Strategy) {
 		// Just exit panic mode
 		if wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionPanicMode).Status == metav1.ConditionTrue {
 			// Exit panic mode
@@ -373,32 +602,70 @@ func (r *ReplicaAutoscalerReconciler) reconcileAutoscaling(logger logr.Logger,
 			r.EventRecorder.Eventf(autoscaler, wingv1.EventTypeWarning, wingv1.EventReasonPanicMode,
 				"Exit panic mode: %d -> %d.", scale.Spec.Replicas, desiredReplicas)
 		}
-		autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, wingv1.Condition{
+		autoscaler.Status.Conditions = wingv1.SetConditionAt(autoscaler.Status.Conditions, wingv1.Condition{
 			Type:   wingv1.ConditionPanicMode,
 			Status: metav1.ConditionFalse,
-		})
+		}, now)
 	}
-	return DefaultRequeueDelay
+	return getRequeueDelayTillTransition(now, DefaultRequeueDelay, nextTransition)
 }
 
-func getWorkingReplicaPatch(autoscaler *wingv1.ReplicaAutoscaler) (*wingv1.ReplicaPatch, error) {
-	replicaPatches, err := utils.GetReplicaPatches(*autoscaler)
-	if err != nil {
-		return nil, err
+// getReplicaPatches returns annotation patches followed by patches of ScalingWindows selecting autoscaler,
+// with their sources. Calendars of windows referring ConfigMaps are resolved only if resolveCalendars is set.
+func (r *ReplicaAutoscalerReconciler) getReplicaPatches(ctx context.Context,
+	autoscaler *wingv1.ReplicaAutoscaler, resolveCalendars bool) (wingv1.ReplicaPatches, []string, error) {
+	windows := &wingv1.ScalingWindowList{}
+	if err := r.Cache.List(ctx, windows, runtimeclient.InNamespace(autoscaler.Namespace)); err != nil {
+		return nil, nil, err
 	}
+	if resolveCalendars {
+		for i := range windows.Items {
+			// Windows failed to resolve calendar are skipped as invalid ones, which are reported in their status
+			_ = utils.ResolveScalingWindowCalendar(ctx, r.APIReader, &windows.Items[i])
+		}
+	}
+	return scheduling.GetAutoscalerReplicaPatches(*autoscaler, windows.Items)
+}
+
+// getNextTransition returns upcoming transition of schedules, replica patches and replica ramp in progress
+func getNextTransition(now time.Time, autoscaler *wingv1.ReplicaAutoscaler,
+	targets []wingv1.ReplicaAutoscalerTarget, replicaPatches wingv1.ReplicaPatches) time.Time {
+	nextTransition := scheduling.GetNextTransition(now, targets, replicaPatches)
+	if ramp := autoscaler.Status.ReplicaRamp; ramp != nil {
+		rampTransition := scheduling.GetReplicaRampNextTransition(now, *ramp)
+		// Linear ramp moves on every reconcile
+		if linearTransition := now.Add(RequeueDelayOnRampState); ramp.Steps == 0 &&
+			linearTransition.Before(rampTransition) {
+			rampTransition = linearTransition
+		}
+		if !rampTransition.IsZero() && (nextTransition.IsZero() || rampTransition.Before(nextTransition)) {
+			nextTransition = rampTransition
+		}
+	}
+	return nextTransition
+}
 
-	return scheduling.GetReplicaPatch(time.Now(), replicaPatches)
+// getRequeueDelayTillTransition shortens requeue delay to reconcile right after next schedule transition,
+// so that schedules and replica patches apply on time.
+func getRequeueDelayTillTransition(now time.Time, requeueDelay time.Duration, nextTransition time.Time) time.Duration {
+	if nextTransition.IsZero() {
+		return requeueDelay
+	}
+	if delay := nextTransition.Sub(now) + time.Second; delay < requeueDelay {
+		return delay
+	}
+	return requeueDelay
 }
 
 func (r *ReplicaAutoscalerReconciler) updateExhaustedAutoscaler(
 	logger logr.Logger,
 	autoscaler *wingv1.ReplicaAutoscaler,
 	scale *autoscalingv1.Scale) (requeueDelay time.Duration, err error) {
+	now := r.now()
 	isExhaust := false
 	exhaustedCondition := wingv1.Condition{
-		Type:               wingv1.ConditionExhausted,
-		Status:             metav1.ConditionFalse,
-		LastTransitionTime: metav1.Now(),
+		Type:   wingv1.ConditionExhausted,
+		Status: metav1.ConditionFalse,
 	}
 	if exhaust := autoscaler.Spec.Exhaust; exhaust != nil && exhaust.Type == wingv1.ExhaustOnPending {
 		scaledObjectSelector, err := labels.Parse(scale.Status.Selector)
@@ -411,7 +678,7 @@ func (r *ReplicaAutoscalerReconciler) updateExhaustedAutoscaler(
 			return RequeueDelayOnErrorState, err
 		}
 		pendingCount := 0
-		oldestPendingBornAt := time.Now()
+		oldestPendingBornAt := now
 		for _, pod := range pods {
 			if pod.Status.Phase != corev1.PodPending {
 				continue
@@ -427,7 +694,7 @@ func (r *ReplicaAutoscalerReconciler) updateExhaustedAutoscaler(
 			return RequeueDelayOnErrorState, err
 		}
 		isExhaust = pendingCount > numberThreshold &&
-			time.Since(oldestPendingBornAt) > time.Duration(exhaust.Pending.TimeoutSeconds)*time.Second
+			now.Sub(oldestPendingBornAt) > time.Duration(exhaust.Pending.TimeoutSeconds)*time.Second
 		if isExhaust {
 			exhaustedCondition.Status = metav1.ConditionTrue
 			exhaustedCondition.Reason = "ExhaustedOnPending"
@@ -438,6 +705,6 @@ func (r *ReplicaAutoscalerReconciler) updateExhaustedAutoscaler(
 		}
 	}
 
-	autoscaler.Status.Conditions = wingv1.SetCondition(autoscaler.Status.Conditions, exhaustedCondition)
+	autoscaler.Status.Conditions = wingv1.SetConditionAt(autoscaler.Status.Conditions, exhaustedCondition, now)
 	return NotRequeue, nil
 }
@@ -0,0 +1,418 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	_ "github.com/xscaling/wing/core/engine/plugin"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+	fakescale "k8s.io/client-go/scale/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakePrometheus serves instant queries with value set by test, and checks query time is taken from clock
+type fakePrometheus struct {
+	clock *clocktesting.FakeClock
+
+	mu         sync.Mutex
+	value      float64
+	clockError error
+}
+
+func (p *fakePrometheus) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if queryTime := req.URL.Query().Get("time"); queryTime != strconv.FormatInt(p.clock.Now().Unix(), 10) {
+		p.clockError = fmt.Errorf("query time %s is not taken from clock %s", queryTime, p.clock.Now())
+	}
+	fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[%d,"%g"]}]}}`,
+		p.clock.Now().Unix(), p.value)
+}
+
+func (p *fakePrometheus) setValue(value float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.value = value
+}
+
+func newTestEngine(t *testing.T, clock *clocktesting.FakeClock, prometheusAddress string, disableTuner bool) *engine.Engine {
+	rawConfig := fmt.Sprintf(`
+cpu: {}
+memory: {}
+prometheus:
+  defaultServer:
+    serverAddress: %s
+simple:
+  disableTuner: %v
+`, prometheusAddress, disableTuner)
+	config := NewDefaultConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(rawConfig), &config.Plugins))
+	e, err := engine.NewWithClock(&rest.Config{Host: "http://127.0.0.1:1"}, nil,
+		config.Plugins, record.NewFakeRecorder(1024), clock)
+	require.NoError(t, err)
+	return e
+}
+
+// TestReconcileAutoscalingWithClock replays panic mode and flux windows end to end with a fake clock
+func TestReconcileAutoscalingWithClock(t *testing.T) {
+	type step struct {
+		// advance clock before reconciling
+		after time.Duration
+		value float64
+
+		expectedReplicas     int32
+		expectedPanicMode    bool
+		expectedRequeueDelay time.Duration
+	}
+	for _, testCase := range []struct {
+		name         string
+		disableTuner bool
+		strategy     *wingv1.ReplicaAutoscalerStrategy
+		// replicaPatches is annotation of replica patches
+		replicaPatches string
+		replicaBudget  *int32
+		dryRun         bool
+		steps          []step
+	}{
+		{
+			name:         "panic mode window",
+			disableTuner: true,
+			strategy: &wingv1.ReplicaAutoscalerStrategy{
+				PanicThreshold:     resource.NewQuantity(2, resource.DecimalSI),
+				PanicWindowSeconds: pointer.Int32(60),
+			},
+			steps: []step{
+				{value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				// 4x scaling up enters panic mode
+				{after: time.Minute, value: 80, expectedReplicas: 8, expectedPanicMode: true,
+					expectedRequeueDelay: RequeueDelayOnPanicState},
+				{after: 15 * time.Second, value: 80, expectedReplicas: 8, expectedPanicMode: true,
+					expectedRequeueDelay: DefaultRequeueDelay},
+				// still in panic mode window
+				{after: 15 * time.Second, value: 40, expectedReplicas: 4, expectedPanicMode: true,
+					expectedRequeueDelay: DefaultRequeueDelay},
+				// 90s after entering panic mode
+				{after: time.Minute, value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:         "scaling cold-down",
+			disableTuner: true,
+			steps: []step{
+				{value: 40, expectedReplicas: 4, expectedRequeueDelay: DefaultRequeueDelay},
+				// requeue right after cold-down
+				{after: 10 * time.Second, value: 80, expectedReplicas: 4, expectedRequeueDelay: 20 * time.Second},
+				{after: 20 * time.Second, value: 80, expectedReplicas: 8, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:         "dry run doesn't enter cold-down",
+			disableTuner: true,
+			dryRun:       true,
+			steps: []step{
+				{value: 40, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 10 * time.Second, value: 80, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:         "scaling cold-down close to replica patch start",
+			disableTuner: true,
+			replicaPatches: `[{"timezone":"UTC","start":"2024-01-01 00:01","end":"2024-01-01 00:20",` +
+				`"minReplicas":10,"maxReplicas":20}]`,
+			steps: []step{
+				{after: 50 * time.Second, value: 40, expectedReplicas: 4, expectedRequeueDelay: 11 * time.Second},
+				// requeue right after patch starts rather than cold-down ends
+				{after: 5 * time.Second, value: 40, expectedReplicas: 4, expectedRequeueDelay: 6 * time.Second},
+				{after: 25 * time.Second, value: 40, expectedReplicas: 10, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name: "flux windows",
+			steps: []step{
+				{value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				// limited by 2 * 150% within a minute
+				{after: time.Minute, value: 80, expectedReplicas: 3, expectedRequeueDelay: DefaultRequeueDelay},
+				// limited by 3 * 150% since scaled to 3 within a minute
+				{after: 30 * time.Second, value: 80, expectedReplicas: 5, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 30 * time.Second, value: 80, expectedReplicas: 5, expectedRequeueDelay: DefaultRequeueDelay},
+				// the snapshot of 3 replicas is out of window
+				{after: time.Minute, value: 80, expectedReplicas: 8, expectedRequeueDelay: DefaultRequeueDelay},
+				// limited by 8 * 50% on scaling down
+				{after: time.Minute, value: 0, expectedReplicas: 4, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:         "replica patch lead time and scale-down delay",
+			disableTuner: true,
+			replicaPatches: `[{"timezone":"UTC","start":"2024-01-01 00:10","end":"2024-01-01 00:20",` +
+				`"leadTime":"5m","scaleDownDelay":"5m","minReplicas":6,"maxReplicas":20}]`,
+			steps: []step{
+				{value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				// requeue right after warming up starts
+				{after: 4*time.Minute + 30*time.Second, value: 20, expectedReplicas: 2,
+					expectedRequeueDelay: 31 * time.Second},
+				{after: 31 * time.Second, value: 20, expectedReplicas: 6, expectedRequeueDelay: DefaultRequeueDelay},
+				// still patched within scale-down delay
+				{after: 19*time.Minute + 30*time.Second, value: 20, expectedReplicas: 6,
+					expectedRequeueDelay: 30 * time.Second},
+				{after: 30 * time.Second, value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:         "replica patch ramp",
+			disableTuner: true,
+			replicaPatches: `[{"timezone":"UTC","start":"2024-01-01 00:10","end":"2024-01-01 00:20",` +
+				`"ramp":{"duration":"4m","steps":2},"minReplicas":10,"maxReplicas":20}]`,
+			steps: []step{
+				{value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				// entering ramp [0, 20] -> [10, 20] in 2 steps
+				{after: 10 * time.Minute, value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 2 * time.Minute, value: 20, expectedReplicas: 5, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 2 * time.Minute, value: 20, expectedReplicas: 10, expectedRequeueDelay: DefaultRequeueDelay},
+				// exiting ramp [10, 20] -> [0, 20]
+				{after: 6*time.Minute + time.Second, value: 20, expectedReplicas: 10,
+					expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 2 * time.Minute, value: 20, expectedReplicas: 5, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: 2 * time.Minute, value: 20, expectedReplicas: 2, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+		{
+			name:          "replica budget",
+			disableTuner:  true,
+			replicaBudget: pointer.Int32(5),
+			steps: []step{
+				{value: 40, expectedReplicas: 4, expectedRequeueDelay: DefaultRequeueDelay},
+				{after: time.Minute, value: 80, expectedReplicas: 5, expectedRequeueDelay: DefaultRequeueDelay},
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			prometheus := &fakePrometheus{clock: clock}
+			server := httptest.NewServer(prometheus)
+			defer server.Close()
+
+			replicas := int32(2)
+			scaleClient := &fakescale.FakeScaleClient{}
+			scaleClient.AddReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				scale := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+				replicas = scale.Spec.Replicas
+				return true, scale, nil
+			})
+			r := &ReplicaAutoscalerReconciler{
+				Config:        NewDefaultConfig().ReplicaAutoscalerControllerConfig,
+				EventRecorder: record.NewFakeRecorder(1024),
+				Engine:        newTestEngine(t, clock, server.URL, testCase.disableTuner),
+				Cache:         &informertest.FakeInformers{},
+				DryRun:        testCase.dryRun,
+				scaleClient:   scaleClient,
+			}
+			autoscaler := &wingv1.ReplicaAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: testCase.name, Annotations: map[string]string{}},
+				Spec: wingv1.ReplicaAutoscalerSpec{
+					ScaleTargetRef: wingv1.CrossVersionObjectReference{
+						APIVersion: "apps/v1", Kind: "Deployment", Name: "test",
+					},
+					MinReplicas:   pointer.Int32(0),
+					MaxReplicas:   20,
+					Strategy:      testCase.strategy,
+					ReplicaBudget: testCase.replicaBudget,
+					Targets: []wingv1.ReplicaAutoscalerTarget{{
+						Metric: "prometheus",
+						Settings: wingv1.TargetSettings{
+							Default: &runtime.RawExtension{Raw: []byte(`{"query":"sum(requests)","threshold":10}`)},
+						},
+					}},
+				},
+			}
+			if testCase.replicaPatches != "" {
+				autoscaler.Annotations[wingv1.ReplicaPatchesAnnotation] = testCase.replicaPatches
+			}
+			gvkr := wingv1.GroupVersionKindResource{
+				Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments",
+			}
+
+			for i, step := range testCase.steps {
+				clock.Step(step.after)
+				prometheus.setValue(step.value)
+				autoscaler.Status.CurrentReplicas = replicas
+				scale := &autoscalingv1.Scale{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+					Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+					Status:     autoscalingv1.ScaleStatus{Replicas: replicas, Selector: "app=test"},
+				}
+				requeueDelay := r.reconcileAutoscaling(context.TODO(), log.Log, autoscaler, gvkr, scale)
+
+				require.NoError(t, prometheus.clockError, "step %d", i)
+				require.Equal(t, step.expectedReplicas, replicas, "step %d", i)
+				require.Equal(t, step.expectedRequeueDelay, requeueDelay, "step %d", i)
+				panicMode := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionPanicMode)
+				require.Equal(t, step.expectedPanicMode, panicMode.Status == metav1.ConditionTrue, "step %d", i)
+				if panicMode.Status == metav1.ConditionTrue {
+					require.False(t, panicMode.LastTransitionTime.After(clock.Now()), "step %d", i)
+				}
+				if testCase.dryRun {
+					require.Nil(t, autoscaler.Status.LastScaleTime, "step %d", i)
+				} else if autoscaler.Status.LastScaleTime != nil {
+					require.False(t, autoscaler.Status.LastScaleTime.After(clock.Now()), "step %d", i)
+				}
+			}
+		})
+	}
+}
+
+// countingReader counts reads going through API server
+type countingReader struct {
+	runtimeclient.Reader
+	reads int
+}
+
+func (r *countingReader) Get(ctx context.Context, key runtimeclient.ObjectKey,
+	obj runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+	r.reads++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestReconcileAutoscalingColdDownCalendars(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	reader := &countingReader{Reader: newTestClient(t, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "holidays"},
+		Data:       map[string]string{"dates": "2024-01-01"},
+	})}
+	r := &ReplicaAutoscalerReconciler{
+		Config:        NewDefaultConfig().ReplicaAutoscalerControllerConfig,
+		EventRecorder: record.NewFakeRecorder(1024),
+		Engine:        newTestEngine(t, clock, "http://127.0.0.1:1", true),
+		Cache:         &informertest.FakeInformers{},
+		APIReader:     reader,
+		scaleClient:   &fakescale.FakeScaleClient{},
+	}
+	autoscaler := &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sample"},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test"},
+			MaxReplicas:    20,
+			Targets: []wingv1.ReplicaAutoscalerTarget{{
+				Metric: "prometheus",
+				Settings: wingv1.TargetSettings{
+					Default: &runtime.RawExtension{Raw: []byte(`{"query":"sum(requests)","threshold":10}`)},
+					Schedules: []wingv1.ScheduleTargetSettings{{
+						Timezone: "UTC",
+						Start:    "0 8 * * *",
+						End:      "0 10 * * *",
+						Calendar: &wingv1.ScheduleCalendar{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "holidays"}, Key: "dates",
+						}},
+						Settings: &runtime.RawExtension{Raw: []byte(`{"query":"sum(requests)","threshold":20}`)},
+					}},
+				},
+			}},
+		},
+		Status: wingv1.ReplicaAutoscalerStatus{
+			LastScaleTime: &metav1.Time{Time: clock.Now().Add(-10 * time.Second)},
+		},
+	}
+	gvkr := wingv1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: 2},
+		Status:     autoscalingv1.ScaleStatus{Replicas: 2, Selector: "app=test"},
+	}
+
+	// Calendar ConfigMap is not read within cold-down
+	requeueDelay := r.reconcileAutoscaling(context.TODO(), log.Log, autoscaler, gvkr, scale)
+	require.Equal(t, DefaultScalingColdDown-10*time.Second, requeueDelay)
+	require.Zero(t, reader.reads)
+
+	clock.Step(requeueDelay)
+	r.reconcileAutoscaling(context.TODO(), log.Log, autoscaler, gvkr, scale)
+	require.Equal(t, 1, reader.reads)
+}
+
+func TestUpdateExhaustedAutoscalerWithClock(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newPod := func(name string, phase corev1.PodPhase, createdAt time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: name, Labels: map[string]string{"app": "test"},
+				CreationTimestamp: metav1.NewTime(createdAt),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := newTestClient(t,
+		newPod("running", corev1.PodRunning, clock.Now().Add(-time.Hour)),
+		newPod("pending-0", corev1.PodPending, clock.Now().Add(-30*time.Second)),
+		newPod("pending-1", corev1.PodPending, clock.Now()),
+	)
+	r := &ReplicaAutoscalerReconciler{
+		Engine: newTestEngine(t, clock, "http://127.0.0.1:1", true),
+	}
+	r.Engine.InformerFactory = engine.NewInformerFactory(&fakeCache{
+		FakeInformers: &informertest.FakeInformers{}, reader: client,
+	})
+	autoscaler := &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sample"},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			Exhaust: &wingv1.Exhaust{
+				Type:    wingv1.ExhaustOnPending,
+				Pending: &wingv1.ExhaustPending{Threshold: intstr.FromInt(1), TimeoutSeconds: 60},
+			},
+		},
+		Status: wingv1.ReplicaAutoscalerStatus{CurrentReplicas: 3},
+	}
+	scale := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=test"}}
+
+	// Oldest pending pod is within timeout by clock
+	_, err := r.updateExhaustedAutoscaler(log.Log, autoscaler, scale)
+	require.NoError(t, err)
+	exhausted := wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionExhausted)
+	require.Equal(t, metav1.ConditionFalse, exhausted.Status)
+
+	clock.Step(31 * time.Second)
+	_, err = r.updateExhaustedAutoscaler(log.Log, autoscaler, scale)
+	require.NoError(t, err)
+	exhausted = wingv1.GetCondition(autoscaler.Status.Conditions, wingv1.ConditionExhausted)
+	require.Equal(t, metav1.ConditionTrue, exhausted.Status)
+	require.Equal(t, "ExhaustedOnPending", exhausted.Reason)
+	require.True(t, exhausted.LastTransitionTime.Time.Equal(clock.Now()))
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/sharding"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	shardingEventsBufferSize = 1024
+
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// NewShardingCoordinator returns coordinator of sharding group, identity is taken from
+// `POD_NAME` environment variable or hostname.
+func NewShardingCoordinator(config ShardingConfig, kubeConfig *rest.Config) (*sharding.Coordinator, error) {
+	identity, err := getIdentity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity of sharding member: %w", err)
+	}
+	namespace := config.Namespace
+	if namespace == "" {
+		content, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return nil, fmt.Errorf("sharding namespace is required out of cluster: %w", err)
+		}
+		namespace = strings.TrimSpace(string(content))
+	}
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return sharding.NewCoordinator(sharding.Options{
+		Identity:      identity,
+		Namespace:     namespace,
+		Group:         config.Group,
+		LeaseDuration: config.LeaseDuration,
+		RenewInterval: config.RenewInterval,
+		HandoverDelay: config.HandoverDelay,
+	}, clientset.CoordinationV1()), nil
+}
+
+// getIdentity returns identity of controller replica from `POD_NAME` environment variable or hostname
+func getIdentity() (string, error) {
+	if identity := os.Getenv("POD_NAME"); identity != "" {
+		return identity, nil
+	}
+	return os.Hostname()
+}
+
+func (r *ReplicaAutoscalerReconciler) setupSharding(blder *builder.Builder) *builder.Builder {
+	if r.Sharding == nil {
+		return blder
+	}
+	r.shardingEvents = make(chan event.GenericEvent, shardingEventsBufferSize)
+	r.Sharding.AddListener(r)
+	return blder.Watches(&source.Channel{Source: r.shardingEvents}, &handler.EnqueueRequestForObject{})
+}
+
+// ownsAutoscaler returns whether the autoscaler should be reconciled by this controller now,
+// a newly acquired autoscaler should be requeued after wait.
+func (r *ReplicaAutoscalerReconciler) ownsAutoscaler(key types.NamespacedName) (owned bool, wait time.Duration) {
+	if r.Sharding == nil {
+		return true, 0
+	}
+	return r.Sharding.Owns(key)
+}
+
+// takeOverAutoscaler drops states left by last ownership on first working on the autoscaler,
+// and consumes handover state whenever present as handover of previous owner may land later.
+func (r *ReplicaAutoscalerReconciler) takeOverAutoscaler(logger logr.Logger, autoscaler *wingv1.ReplicaAutoscaler) {
+	key := types.NamespacedName{Namespace: autoscaler.Namespace, Name: autoscaler.Name}
+	rawHandover, handedOver := autoscaler.Annotations[wingv1.HandoverStateAnnotation]
+	if r.Sharding != nil {
+		if _, working := r.workingAutoscalers.LoadOrStore(key, struct{}{}); !working {
+			r.Engine.ForgetStates(key)
+			if !handedOver {
+				logger.V(2).Info("Took over autoscaler without handover state")
+			}
+		}
+	}
+	if !handedOver {
+		return
+	}
+	// Purge handover state anyway, otherwise it would be imported on next ownership
+	delete(autoscaler.Annotations, wingv1.HandoverStateAnnotation)
+	if r.Sharding == nil {
+		logger.Info("Dropped handover state as sharding is disabled")
+		return
+	}
+	handover, err := sharding.ParseHandover(rawHandover)
+	if err != nil {
+		logger.Error(err, "Failed to parse handover state, ignored")
+		return
+	}
+	if handover.IsStale(r.Sharding.Identity(), r.Sharding.Ring()) {
+		logger.Info("Dropped stale handover state", "owner", handover.Owner, "ringVersion", handover.RingVersion)
+		return
+	}
+	// States built before handover landed are replaced
+	r.Engine.ForgetStates(key)
+	if err := r.Engine.ImportStates(key, handover.States); err != nil {
+		logger.Error(err, "Failed to import handover state, ignored")
+		return
+	}
+	logger.Info("Took over autoscaler with handover state", "plugins", len(handover.States))
+}
+
+// OnRebalance implements sharding.Listener, hands over autoscalers no longer owned
+// and triggers reconciling autoscalers newly owned.
+func (r *ReplicaAutoscalerReconciler) OnRebalance(ring *sharding.Ring) {
+	identity := r.Sharding.Identity()
+	r.workingAutoscalers.Range(func(k, _ interface{}) bool {
+		key := k.(types.NamespacedName)
+		if ring.Owner(key) != identity {
+			r.handOverAutoscaler(key, ring)
+		}
+		return true
+	})
+
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers); err != nil {
+		log.Log.Error(err, "Failed to list autoscalers after rebalance")
+		return
+	}
+	var acquired []runtimeclient.Object
+	for i := range autoscalers.Items {
+		autoscaler := &autoscalers.Items[i]
+		key := types.NamespacedName{Namespace: autoscaler.Namespace, Name: autoscaler.Name}
+		if _, working := r.workingAutoscalers.Load(key); !working && ring.Owner(key) == identity {
+			acquired = append(acquired, autoscaler)
+		}
+	}
+	log.Log.Info("Rebalanced autoscalers", "members", ring.Members(), "acquired", len(acquired))
+	go func() {
+		for _, autoscaler := range acquired {
+			r.shardingEvents <- event.GenericEvent{Object: autoscaler}
+		}
+	}()
+}
+
+func (r *ReplicaAutoscalerReconciler) handOverAutoscaler(key types.NamespacedName, ring *sharding.Ring) {
+	logger := log.Log.WithValues("replicaAutoscaler", key)
+	defer r.forgetAutoscaler(key)
+	states, err := r.Engine.ExportStates(key)
+	if err != nil {
+		logger.Error(err, "Failed to export states for handover")
+		return
+	}
+	if len(states) == 0 {
+		logger.V(2).Info("Handed over autoscaler without state")
+		return
+	}
+	rawHandover, err := json.Marshal(sharding.NewHandover(key, ring, states))
+	if err != nil {
+		logger.Error(err, "Failed to marshal states for handover")
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{wingv1.HandoverStateAnnotation: string(rawHandover)},
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to marshal handover patch")
+		return
+	}
+	autoscaler := &wingv1.ReplicaAutoscaler{}
+	autoscaler.Namespace, autoscaler.Name = key.Namespace, key.Name
+	if err = r.Client.Patch(context.TODO(), autoscaler, runtimeclient.RawPatch(types.MergePatchType, patch)); err != nil {
+		logger.Error(err, "Failed to hand over states")
+		return
+	}
+	logger.Info("Handed over autoscaler", "owner", ring.Owner(key), "plugins", len(states))
+}
+
+// forgetAutoscaler drops states, metrics and working mark of the autoscaler deleted or handed over
+func (r *ReplicaAutoscalerReconciler) forgetAutoscaler(key types.NamespacedName) {
+	r.Engine.ForgetStates(key)
+	r.forgetAutoscalerMetrics(key)
+	r.workingAutoscalers.Delete(key)
+}
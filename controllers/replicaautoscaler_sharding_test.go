@@ -0,0 +1,200 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/sharding"
+
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakekubernetes "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakeCache reads objects from client
+type fakeCache struct {
+	*informertest.FakeInformers
+	reader runtimeclient.Reader
+}
+
+func (c *fakeCache) Get(ctx context.Context, key runtimeclient.ObjectKey,
+	obj runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+// List filters autoscalers by scale target index, which is not supported by fake client
+func (c *fakeCache) List(ctx context.Context, list runtimeclient.ObjectList, opts ...runtimeclient.ListOption) error {
+	if err := c.reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	listOptions := (&runtimeclient.ListOptions{}).ApplyOptions(opts)
+	autoscalers, ok := list.(*wingv1.ReplicaAutoscalerList)
+	if !ok || listOptions.FieldSelector == nil {
+		return nil
+	}
+	var indexed []wingv1.ReplicaAutoscaler
+	for _, autoscaler := range autoscalers.Items {
+		for _, key := range indexScaleTarget(&autoscaler) {
+			if listOptions.FieldSelector.Matches(fields.Set{ScaleTargetIndexField: key}) {
+				indexed = append(indexed, autoscaler)
+				break
+			}
+		}
+	}
+	autoscalers.Items = indexed
+	return nil
+}
+
+func newTestClient(t *testing.T, objects ...runtimeclient.Object) runtimeclient.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, wingv1.AddToScheme(scheme))
+	return fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+// TestShardingHandover hands over an autoscaler from member a to b on rebalance
+func TestShardingHandover(t *testing.T) {
+	ring := sharding.NewRing([]string{"a", "b"})
+	var key types.NamespacedName
+	for i := 0; ; i++ {
+		key = types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("autoscaler-%d", i)}
+		if ring.Owner(key) == "b" {
+			break
+		}
+	}
+	client := newTestClient(t, &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+	})
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newReconciler := func(coordinator *sharding.Coordinator) *ReplicaAutoscalerReconciler {
+		return &ReplicaAutoscalerReconciler{
+			Client:         client,
+			Cache:          &fakeCache{FakeInformers: &informertest.FakeInformers{}, reader: client},
+			EventRecorder:  record.NewFakeRecorder(1024),
+			Engine:         newTestEngine(t, clock, "http://127.0.0.1:1", false),
+			Sharding:       coordinator,
+			shardingEvents: make(chan event.GenericEvent, 1),
+		}
+	}
+	fluxState := []byte(`{"scaleUp":[{"Timestamp":"2024-01-01T00:00:00Z","Replicas":3}]}`)
+	states := map[string][]byte{engine.PluginEndpointReplicator + "/simple": fluxState}
+
+	// Member a hands over autoscaler moved to b
+	a := newReconciler(sharding.NewCoordinator(sharding.Options{Identity: "a"}, nil))
+	require.NoError(t, a.Engine.ImportStates(key, states))
+	a.workingAutoscalers.Store(key, struct{}{})
+	a.OnRebalance(ring)
+	_, working := a.workingAutoscalers.Load(key)
+	require.False(t, working)
+	exported, err := a.Engine.ExportStates(key)
+	require.NoError(t, err)
+	require.Empty(t, exported)
+
+	autoscaler := &wingv1.ReplicaAutoscaler{}
+	require.NoError(t, client.Get(context.TODO(), key, autoscaler))
+	handover, err := sharding.ParseHandover(autoscaler.Annotations[wingv1.HandoverStateAnnotation])
+	require.NoError(t, err)
+	require.Equal(t, "b", handover.Owner)
+	require.Equal(t, ring.Version(), handover.RingVersion)
+	require.JSONEq(t, string(fluxState), string(handover.States[engine.PluginEndpointReplicator+"/simple"]))
+
+	// Member b joins the ring with alive lease of a
+	now := metav1.NewMicroTime(time.Now())
+	clientset := fakekubernetes.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "wing-a", Namespace: "wing-system", Labels: map[string]string{sharding.GroupLabel: "wing"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: pointer.String("a"), LeaseDurationSeconds: pointer.Int32(60), RenewTime: &now,
+		},
+	})
+	coordinator := sharding.NewCoordinator(sharding.Options{
+		Identity: "b", Namespace: "wing-system", Group: "wing",
+		LeaseDuration: time.Minute, RenewInterval: 10 * time.Millisecond,
+	}, clientset.CoordinationV1())
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go func() {
+		_ = coordinator.Start(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return coordinator.Ring().Equal(ring)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Autoscaler acquired is triggered
+	b := newReconciler(coordinator)
+	b.OnRebalance(ring)
+	select {
+	case acquired := <-b.shardingEvents:
+		require.Equal(t, key.Name, acquired.Object.GetName())
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "acquired autoscaler is not triggered")
+	}
+
+	// Handover landing after working on the autoscaler is imported as well
+	b.takeOverAutoscaler(log.Log, &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+	})
+	exported, err = b.Engine.ExportStates(key)
+	require.NoError(t, err)
+	require.Empty(t, exported)
+	b.takeOverAutoscaler(log.Log, autoscaler)
+	require.NotContains(t, autoscaler.Annotations, wingv1.HandoverStateAnnotation)
+	exported, err = b.Engine.ExportStates(key)
+	require.NoError(t, err)
+	require.JSONEq(t, string(fluxState), string(exported[engine.PluginEndpointReplicator+"/simple"]))
+
+	// Stale handover made on other ring is dropped
+	staleHandover, err := json.Marshal(sharding.NewHandover(key, sharding.NewRing([]string{"b", "c"}), map[string][]byte{
+		engine.PluginEndpointReplicator + "/simple": []byte(`{"scaleUp":[{"Timestamp":"2023-01-01T00:00:00Z","Replicas":9}]}`),
+	}))
+	require.NoError(t, err)
+	autoscaler.Annotations[wingv1.HandoverStateAnnotation] = string(staleHandover)
+	b.takeOverAutoscaler(log.Log, autoscaler)
+	require.NotContains(t, autoscaler.Annotations, wingv1.HandoverStateAnnotation)
+	exported, err = b.Engine.ExportStates(key)
+	require.NoError(t, err)
+	require.JSONEq(t, string(fluxState), string(exported[engine.PluginEndpointReplicator+"/simple"]))
+
+	// Deleted autoscaler is forgotten
+	b.forgetAutoscaler(key)
+	_, working = b.workingAutoscalers.Load(key)
+	require.False(t, working)
+	exported, err = b.Engine.ExportStates(key)
+	require.NoError(t, err)
+	require.Empty(t, exported)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// delayedEnqueueHandler maps events to ReplicaAutoscaler requests and enqueues them after delay,
+// so that bursts of events (e.g. a rollout creating many pods) are merged into one reconcile.
+type delayedEnqueueHandler struct {
+	mapFunc handler.MapFunc
+	delay   time.Duration
+}
+
+var _ handler.EventHandler = &delayedEnqueueHandler{}
+
+func (h *delayedEnqueueHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+func (h *delayedEnqueueHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.ObjectNew, q)
+}
+
+func (h *delayedEnqueueHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+func (h *delayedEnqueueHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+func (h *delayedEnqueueHandler) enqueue(object runtimeclient.Object, q workqueue.RateLimitingInterface) {
+	for _, req := range h.mapFunc(object) {
+		// Delaying queue keeps the earliest ready time for duplicated items
+		q.AddAfter(req, h.delay)
+	}
+}
+
+// podPhaseChangedPredicate only passes pod updates changing phase, which is what exhaust detection cares about
+var podPhaseChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(evt event.UpdateEvent) bool {
+		oldPod, ok := evt.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		newPod, ok := evt.ObjectNew.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		return oldPod.Status.Phase != newPod.Status.Phase
+	},
+}
+
+// setupWatches registers watches on scale targets and pods according to watch config, and on scaling windows
+// and policies
+func (r *ReplicaAutoscalerReconciler) setupWatches(blder *builder.Builder) *builder.Builder {
+	for _, kind := range r.Config.Watch.ScaleTargets {
+		target := &metav1.PartialObjectMetadata{}
+		target.SetGroupVersionKind(schema.FromAPIVersionAndKind(kind.APIVersion, kind.Kind))
+		blder = blder.Watches(&source.Kind{Type: target},
+			&delayedEnqueueHandler{
+				mapFunc: r.mapScaleTargetToAutoscalers(kind),
+				delay:   r.Config.Watch.EnqueueDelay,
+			},
+			builder.OnlyMetadata,
+			// Replicas changes increase generation of workloads
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	}
+	if r.Config.Watch.Pods {
+		blder = blder.Watches(&source.Kind{Type: &corev1.Pod{}},
+			&delayedEnqueueHandler{
+				mapFunc: r.mapPodToAutoscalers,
+				delay:   r.Config.Watch.EnqueueDelay,
+			},
+			builder.WithPredicates(podPhaseChangedPredicate))
+	}
+	// Both old and new selected autoscalers of updated window are enqueued
+	blder = blder.Watches(&source.Kind{Type: &wingv1.ScalingWindow{}},
+		handler.EnqueueRequestsFromMapFunc(r.mapScalingWindowToAutoscalers),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	blder = blder.Watches(&source.Kind{Type: &wingv1.ClusterReplicaAutoscalerPolicy{}},
+		handler.EnqueueRequestsFromMapFunc(r.mapPolicyToAutoscalers),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	return blder
+}
+
+func (r *ReplicaAutoscalerReconciler) mapScaleTargetToAutoscalers(kind WatchedKind) handler.MapFunc {
+	return func(object runtimeclient.Object) []reconcile.Request {
+		autoscalers := &wingv1.ReplicaAutoscalerList{}
+		if err := r.Cache.List(context.TODO(), autoscalers,
+			runtimeclient.InNamespace(object.GetNamespace()),
+			runtimeclient.MatchingFields{
+				ScaleTargetIndexField: utils.GetScaleTargetKey(kind.APIVersion, kind.Kind, object.GetName()),
+			}); err != nil {
+			log.Log.Error(err, "Failed to list ReplicaAutoscaler by scale target",
+				"kind", kind.Kind, "namespace", object.GetNamespace(), "name", object.GetName())
+			return nil
+		}
+		return getAutoscalerRequests(autoscalers.Items, nil)
+	}
+}
+
+func (r *ReplicaAutoscalerReconciler) mapPodToAutoscalers(object runtimeclient.Object) []reconcile.Request {
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers, runtimeclient.InNamespace(object.GetNamespace())); err != nil {
+		log.Log.Error(err, "Failed to list ReplicaAutoscaler for pod",
+			"namespace", object.GetNamespace(), "name", object.GetName())
+		return nil
+	}
+	podLabels := labels.Set(object.GetLabels())
+	return getAutoscalerRequests(autoscalers.Items, func(autoscaler *wingv1.ReplicaAutoscaler) bool {
+		// Only exhaust detection depends on pods
+		if autoscaler.Spec.Exhaust == nil || autoscaler.Status.Selector == "" {
+			return false
+		}
+		selector, err := labels.Parse(autoscaler.Status.Selector)
+		if err != nil {
+			return false
+		}
+		return selector.Matches(podLabels)
+	})
+}
+
+func (r *ReplicaAutoscalerReconciler) mapScalingWindowToAutoscalers(object runtimeclient.Object) []reconcile.Request {
+	window, ok := object.(*wingv1.ScalingWindow)
+	if !ok {
+		return nil
+	}
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.Cache.List(context.TODO(), autoscalers, runtimeclient.InNamespace(window.Namespace)); err != nil {
+		log.Log.Error(err, "Failed to list ReplicaAutoscaler for scaling window",
+			"namespace", window.Namespace, "name", window.Name)
+		return nil
+	}
+	return getAutoscalerRequests(autoscalers.Items, func(autoscaler *wingv1.ReplicaAutoscaler) bool {
+		selected, err := utils.IsScalingWindowSelecting(*window, *autoscaler)
+		return err == nil && selected
+	})
+}
+
+// getAutoscalerRequests returns requests of autoscalers passing filter, nil filter passes all
+func getAutoscalerRequests(autoscalers []wingv1.ReplicaAutoscaler,
+	filter func(*wingv1.ReplicaAutoscaler) bool) []reconcile.Request {
+	var requests []reconcile.Request
+	for i := range autoscalers {
+		autoscaler := &autoscalers[i]
+		if filter != nil && !filter(autoscaler) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: autoscaler.Namespace,
+			Name:      autoscaler.Name,
+		}})
+	}
+	return requests
+}
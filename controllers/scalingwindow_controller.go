@@ -0,0 +1,183 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/scheduling"
+	"github.com/xscaling/wing/core/sharding"
+	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/timerange"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ScalingWindowReconciler reconciles a ScalingWindow object, it reports phase and selected autoscalers
+// in status and garbage collects expired date windows.
+// Windows are applied to autoscalers by ReplicaAutoscalerReconciler.
+type ScalingWindowReconciler struct {
+	runtimeclient.Client
+	// APIReader reads objects not worth caching cluster wide, e.g. calendar ConfigMaps
+	APIReader runtimeclient.Reader
+
+	EventRecorder record.EventRecorder
+	Clock         clock.PassiveClock
+	// Sharding is nil if sharding is disabled, windows are sharded among members as autoscalers
+	Sharding *sharding.Coordinator
+
+	// shardingEvents triggers reconciling windows acquired on rebalance
+	shardingEvents chan event.GenericEvent
+}
+
+//+kubebuilder:rbac:groups=wing.xscaling.dev,resources=scalingwindows,verbs=get;list;watch;update;patch;delete
+//+kubebuilder:rbac:groups=wing.xscaling.dev,resources=scalingwindows/status,verbs=get;update;patch
+
+func (r *ScalingWindowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if r.Sharding != nil {
+		// Only one member patches status and garbage collects the window
+		if owned, wait := r.Sharding.Owns(req.NamespacedName); !owned || wait > 0 {
+			logger.V(4).Info("Scaling window is not owned by this shard currently", "owned", owned, "wait", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+	window := &wingv1.ScalingWindow{}
+	if err := r.Get(ctx, req.NamespacedName, window); err != nil {
+		return ctrl.Result{}, runtimeclient.IgnoreNotFound(err)
+	}
+	if window.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	now := r.Clock.Now()
+	if expireTime, ok := utils.GetReplicaPatchExpireTime(window.Spec.ReplicaPatch); ok && now.After(expireTime) {
+		logger.Info("Deleting expired scaling window", "expireTime", expireTime)
+		r.EventRecorder.Eventf(window, wingv1.EventTypeNormal, wingv1.EventReasonScalingWindowExpired,
+			"Scaling window expired at %s", expireTime.Format(time.RFC3339))
+		return ctrl.Result{}, runtimeclient.IgnoreNotFound(r.Delete(ctx, window))
+	}
+
+	scheduledWindow := window.DeepCopy()
+	resolveErr := utils.ResolveScalingWindowCalendar(ctx, r.APIReader, scheduledWindow)
+	status, requeueDelay := getScalingWindowStatus(*scheduledWindow, now)
+	if resolveErr != nil {
+		status.Phase, status.Message = wingv1.ScalingWindowInvalid, resolveErr.Error()
+	}
+	autoscalers := &wingv1.ReplicaAutoscalerList{}
+	if err := r.List(ctx, autoscalers, runtimeclient.InNamespace(window.Namespace)); err != nil {
+		logger.Error(err, "Failed to list ReplicaAutoscalers")
+		return ctrl.Result{}, err
+	}
+	for _, autoscaler := range autoscalers.Items {
+		if selected, err := utils.IsScalingWindowSelecting(*window, autoscaler); err != nil {
+			status.Phase, status.Message = wingv1.ScalingWindowInvalid, "Invalid autoscaler selector: "+err.Error()
+			break
+		} else if selected {
+			status.Autoscalers = append(status.Autoscalers, autoscaler.Name)
+		}
+	}
+
+	if !utils.DeepEqual(window.Status, status) {
+		patch := runtimeclient.MergeFrom(window.DeepCopy())
+		window.Status = status
+		if err := r.Status().Patch(ctx, window, patch); err != nil {
+			logger.Error(err, "Failed to update scaling window status")
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueDelay}, nil
+}
+
+// getScalingWindowStatus returns status of the window at given time, and requeue delay till next phase
+// transition which is bounded by DefaultRequeueDelay to refresh selected autoscalers.
+func getScalingWindowStatus(window wingv1.ScalingWindow, now time.Time) (wingv1.ScalingWindowStatus, time.Duration) {
+	status := wingv1.ScalingWindowStatus{ObservedGeneration: window.Generation}
+	if err := scheduling.ValidateReplicaPatch(window.Spec.ReplicaPatch); err != nil {
+		status.Phase, status.Message = wingv1.ScalingWindowInvalid, err.Error()
+		return status, DefaultRequeueDelay
+	}
+	scheduler, _ := scheduling.GetReplicaPatchScheduler(window.Spec.ReplicaPatch)
+	status.Phase = wingv1.ScalingWindowInactive
+	if scheduler.Contains(now) {
+		status.Phase = wingv1.ScalingWindowActive
+	}
+	dateScheduler, ok := timerange.AsDateScheduler(scheduler)
+	if !ok {
+		return status, getRequeueDelayTillTransition(now, DefaultRequeueDelay, scheduler.GetNextTransition(now))
+	}
+
+	expireTime, _ := utils.GetReplicaPatchExpireTime(window.Spec.ReplicaPatch)
+	status.ExpireTime = &metav1.Time{Time: expireTime}
+	// Transit right after boundary
+	nextTransition := expireTime
+	switch {
+	case status.Phase == wingv1.ScalingWindowActive:
+		nextTransition = dateScheduler.GetEndTime()
+	case now.Before(dateScheduler.GetEndTime()):
+		status.Phase = wingv1.ScalingWindowPending
+		nextTransition, _ = dateScheduler.GetUpcomingTriggerDuration(now)
+	default:
+		status.Phase = wingv1.ScalingWindowExpired
+	}
+	return status, getRequeueDelayTillTransition(now, DefaultRequeueDelay, nextTransition)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScalingWindowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	blder := ctrl.NewControllerManagedBy(mgr).
+		For(&wingv1.ScalingWindow{})
+	if r.Sharding != nil {
+		r.shardingEvents = make(chan event.GenericEvent, shardingEventsBufferSize)
+		r.Sharding.AddListener(r)
+		blder = blder.Watches(&source.Channel{Source: r.shardingEvents}, &handler.EnqueueRequestForObject{})
+	}
+	return blder.Complete(r)
+}
+
+// OnRebalance implements sharding.Listener, triggers reconciling windows newly owned
+func (r *ScalingWindowReconciler) OnRebalance(ring *sharding.Ring) {
+	windows := &wingv1.ScalingWindowList{}
+	if err := r.List(context.TODO(), windows); err != nil {
+		log.Log.Error(err, "Failed to list scaling windows after rebalance")
+		return
+	}
+	identity := r.Sharding.Identity()
+	var acquired []runtimeclient.Object
+	for i := range windows.Items {
+		window := &windows.Items[i]
+		if ring.Owner(types.NamespacedName{Namespace: window.Namespace, Name: window.Name}) == identity {
+			acquired = append(acquired, window)
+		}
+	}
+	go func() {
+		for _, window := range acquired {
+			r.shardingEvents <- event.GenericEvent{Object: window}
+		}
+	}()
+}
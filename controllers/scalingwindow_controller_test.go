@@ -0,0 +1,103 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetScalingWindowStatus(t *testing.T) {
+	datePatch := wingv1.ReplicaPatch{
+		Timezone: "UTC", Start: "2024-08-15 08:00", End: "2024-08-15 10:00",
+		RetentionSeconds: pointer.Int64(600), MinReplicas: 4, MaxReplicas: 40,
+	}
+	for _, testCase := range []struct {
+		description   string
+		patch         wingv1.ReplicaPatch
+		now           time.Time
+		expectedPhase wingv1.ScalingWindowPhase
+		expectedDelay time.Duration
+	}{
+		{
+			description:   "pending far from start",
+			patch:         datePatch,
+			now:           time.Date(2024, 8, 15, 6, 0, 0, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowPending,
+			expectedDelay: DefaultRequeueDelay,
+		},
+		{
+			description:   "pending close to start",
+			patch:         datePatch,
+			now:           time.Date(2024, 8, 15, 7, 59, 30, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowPending,
+			expectedDelay: 31 * time.Second,
+		},
+		{
+			description:   "active close to end",
+			patch:         datePatch,
+			now:           time.Date(2024, 8, 15, 9, 59, 50, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowActive,
+			expectedDelay: 11 * time.Second,
+		},
+		{
+			description:   "expired in retention",
+			patch:         datePatch,
+			now:           time.Date(2024, 8, 15, 10, 9, 40, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowExpired,
+			expectedDelay: 21 * time.Second,
+		},
+		{
+			description:   "cron window in period",
+			patch:         wingv1.ReplicaPatch{Timezone: "UTC", Start: "0 8 * * *", End: "0 10 * * *"},
+			now:           time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowActive,
+			expectedDelay: DefaultRequeueDelay,
+		},
+		{
+			description:   "cron window out of period",
+			patch:         wingv1.ReplicaPatch{Timezone: "UTC", Start: "0 8 * * *", End: "0 10 * * *"},
+			now:           time.Date(2024, 8, 15, 11, 0, 0, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowInactive,
+			expectedDelay: DefaultRequeueDelay,
+		},
+		{
+			description:   "cron window close to end",
+			patch:         wingv1.ReplicaPatch{Timezone: "UTC", Start: "0 8 * * *", End: "0 10 * * *"},
+			now:           time.Date(2024, 8, 15, 9, 59, 30, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowActive,
+			expectedDelay: 31 * time.Second,
+		},
+		{
+			description:   "invalid period",
+			patch:         wingv1.ReplicaPatch{Timezone: "UTC", Start: "2024-08-15 10:00", End: "2024-08-15 08:00"},
+			now:           time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC),
+			expectedPhase: wingv1.ScalingWindowInvalid,
+			expectedDelay: DefaultRequeueDelay,
+		},
+	} {
+		window := wingv1.ScalingWindow{Spec: wingv1.ScalingWindowSpec{ReplicaPatch: testCase.patch}}
+		status, requeueDelay := getScalingWindowStatus(window, testCase.now)
+		require.Equal(t, testCase.expectedPhase, status.Phase, testCase.description)
+		require.Equal(t, testCase.expectedDelay, requeueDelay, testCase.description)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/xscaling/wing/utils/tracing"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	tracingShutdownTimeout = 5 * time.Second
+)
+
+// SetupTracing registers global tracer provider exporting to OTLP receiver,
+// the returned runnable flushes pending spans when manager stops.
+func SetupTracing(config TracingConfig) (manager.Runnable, error) {
+	// Identity is optional for tracing
+	identity, _ := getIdentity()
+	provider, err := tracing.Setup(tracing.Options{
+		Endpoint:          config.Endpoint,
+		URLPath:           config.URLPath,
+		Insecure:          config.Insecure,
+		Headers:           config.Headers,
+		SampleRatio:       config.SampleRatio,
+		ServiceName:       config.ServiceName,
+		ServiceInstanceID: identity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tracingFlusher{provider: provider}, nil
+}
+
+// tracingFlusher flushes pending spans when manager stops
+type tracingFlusher struct {
+	provider interface {
+		Shutdown(ctx context.Context) error
+	}
+}
+
+var (
+	_ manager.Runnable               = &tracingFlusher{}
+	_ manager.LeaderElectionRunnable = &tracingFlusher{}
+)
+
+func (f *tracingFlusher) NeedLeaderElection() bool {
+	return false
+}
+
+func (f *tracingFlusher) Start(ctx context.Context) error {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := f.provider.Shutdown(shutdownCtx); err != nil {
+		log.Log.Error(err, "Failed to flush spans on shutdown")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/xscaling/wing/utils/metrics"
 
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 )
 
 const (
@@ -22,12 +23,22 @@ type Controller interface {
 	AddReplicator(name string, replicator Replicator)
 	GetReplicator(name string) (Replicator, bool)
 	AddScaler(name string, scaler Scaler)
+	GetScaler(name string) (Scaler, bool)
 	GetKubernetesMetricsClient() metrics.MetricsClient
 	GetEventRecorder() record.EventRecorder
+	// GetClock returns clock which plugins should take time from
+	GetClock() clock.PassiveClock
+	// SetReloadHook sets how the plugin is set up again on config reload,
+	// plugins without reload hook are set up again by their setup func.
+	SetReloadHook(endpoint, name string, hook PluginReloadFunc)
 }
 
 type PluginSetupFunc func(c Controller) error
 
+// PluginReloadFunc sets up plugin with new config on config reload. Previous is the working instance of plugin
+// which could be used to carry states over, it keeps working if any error returned.
+type PluginReloadFunc func(c Controller, previous interface{}) error
+
 type Plugin struct {
 	Endpoint  string
 	SetupFunc PluginSetupFunc
@@ -68,3 +79,7 @@ func GetPlugin(endpoint, name string) (Plugin, bool) {
 	plugin, ok := registeredPlugins[endpoint][name]
 	return plugin, ok
 }
+
+func getPluginKey(endpoint, name string) string {
+	return fmt.Sprintf("%s/%s", endpoint, name)
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+)
+
+// DecisionTrace collects explanation steps contributed by scalers, replicator, tuners and controller
+// during one reconcile.
+type DecisionTrace struct {
+	mu    sync.Mutex
+	steps []wingv1.DecisionStep
+}
+
+func NewDecisionTrace() *DecisionTrace {
+	return &DecisionTrace{}
+}
+
+// Record appends an explanation step, it's safe to call on nil trace.
+func (t *DecisionTrace) Record(stage wingv1.DecisionStage, source string, replicas int32, format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps = append(t.steps, wingv1.DecisionStep{
+		Stage:    stage,
+		Source:   source,
+		Replicas: replicas,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Explain implements tuner.Explainer
+func (t *DecisionTrace) Explain(tunerName string, replicas int32, message string) {
+	t.Record(wingv1.DecisionStageTuner, tunerName, replicas, "%s", message)
+}
+
+func (t *DecisionTrace) Steps() []wingv1.DecisionStep {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	steps := make([]wingv1.DecisionStep, len(t.steps))
+	copy(steps, t.steps)
+	return steps
+}
+
+// Summary returns a brief one line summary of steps, e.g. `Scaler(cpu)=5 -> Replicator(simple)=5 -> Limit(max)=4`
+func (t *DecisionTrace) Summary() string {
+	return SummarizeDecisionSteps(t.Steps())
+}
+
+// SummarizeDecisionSteps returns a brief one line summary of given steps
+func SummarizeDecisionSteps(steps []wingv1.DecisionStep) string {
+	parts := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if step.Source != "" {
+			parts = append(parts, fmt.Sprintf("%s(%s)=%d", step.Stage, step.Source, step.Replicas))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%d", step.Stage, step.Replicas))
+		}
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/xscaling/wing/utils"
@@ -18,27 +19,40 @@ import (
 	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
 	"k8s.io/metrics/pkg/client/custom_metrics"
 	"k8s.io/metrics/pkg/client/external_metrics"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type engineProvisioner struct {
 	kubeConfig    *rest.Config
+	metricsClient metrics.MetricsClient
+	eventRecorder record.EventRecorder
+	clock         clock.PassiveClock
+
+	// mu protects plugins and their configs which are swapped on reload
+	mu            sync.RWMutex
 	scalers       map[string]Scaler
 	replicators   map[string]Replicator
-	metricsClient metrics.MetricsClient
 	pluginConfigs map[string]utils.YamlRawMessage
-	eventRecorder record.EventRecorder
+	reloadHooks   map[string]PluginReloadFunc
 }
 
 func newEngineProvisioner(kubeConfig *rest.Config, RESTMapper *restmapper.DeferredDiscoveryRESTMapper,
-	pluginConfigs map[string]utils.YamlRawMessage, eventRecorder record.EventRecorder) *engineProvisioner {
+	pluginConfigs map[string]utils.YamlRawMessage, eventRecorder record.EventRecorder,
+	clock clock.PassiveClock) *engineProvisioner {
 	ep := &engineProvisioner{
 		kubeConfig:    kubeConfig,
+		clock:         clock,
 		scalers:       make(map[string]Scaler),
 		replicators:   make(map[string]Replicator),
-		pluginConfigs: pluginConfigs,
+		pluginConfigs: make(map[string]utils.YamlRawMessage, len(pluginConfigs)),
+		reloadHooks:   make(map[string]PluginReloadFunc),
 		eventRecorder: eventRecorder,
 	}
+	for name, rawConfig := range pluginConfigs {
+		ep.pluginConfigs[name] = rawConfig
+	}
 	clientSet := utils.ClientOrDie(*ep.kubeConfig, "wing-engine")
 	apiVersionsGetter := custom_metrics.NewAvailableAPIsGetter(clientSet.Discovery())
 	// invalidate the discovery information roughly once per resync interval our API
@@ -58,16 +72,32 @@ func newEngineProvisioner(kubeConfig *rest.Config, RESTMapper *restmapper.Deferr
 
 func (p *engineProvisioner) AddReplicator(name string, replicator Replicator) {
 	log.Log.Info("Adding replicator to engine", "name", name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.replicators[name] = replicator
 }
 
 func (p *engineProvisioner) AddScaler(name string, scaler Scaler) {
 	log.Log.Info("Adding scaler to engine", "name", name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.scalers[name] = scaler
 }
 
+func (p *engineProvisioner) SetReloadHook(endpoint, name string, hook PluginReloadFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reloadHooks[getPluginKey(endpoint, name)] = hook
+}
+
 func (p *engineProvisioner) GetPluginConfig(name string, configReceiver PluginConfig) (ok bool, err error) {
+	p.mu.RLock()
 	rawConfig, ok := p.pluginConfigs[name]
+	p.mu.RUnlock()
+	return getPluginConfig(rawConfig, ok, configReceiver)
+}
+
+func getPluginConfig(rawConfig utils.YamlRawMessage, ok bool, configReceiver PluginConfig) (bool, error) {
 	if !ok {
 		return false, nil
 	}
@@ -82,11 +112,15 @@ func (p *engineProvisioner) GetPluginConfig(name string, configReceiver PluginCo
 }
 
 func (p *engineProvisioner) GetScaler(name string) (Scaler, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	scaler, ok := p.scalers[name]
 	return scaler, ok
 }
 
 func (p *engineProvisioner) GetReplicator(name string) (Replicator, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	replicator, ok := p.replicators[name]
 	return replicator, ok
 }
@@ -99,12 +133,26 @@ func (p *engineProvisioner) GetEventRecorder() record.EventRecorder {
 	return p.eventRecorder
 }
 
+func (p *engineProvisioner) GetClock() clock.PassiveClock {
+	return p.clock
+}
+
 type Engine struct {
 	*engineProvisioner
 	*InformerFactory
+
+	reloadMu sync.Mutex
+}
+
+func New(kubeConfig *rest.Config, cache cache.Cache,
+	pluginConfigs map[string]utils.YamlRawMessage, eventRecorder record.EventRecorder) (*Engine, error) {
+	return NewWithClock(kubeConfig, cache, pluginConfigs, eventRecorder, clock.RealClock{})
 }
 
-func New(kubeConfig *rest.Config, pluginConfigs map[string]utils.YamlRawMessage, eventRecorder record.EventRecorder) (*Engine, error) {
+// NewWithClock returns engine whose plugins and reconciling work on given clock
+func NewWithClock(kubeConfig *rest.Config, cache cache.Cache,
+	pluginConfigs map[string]utils.YamlRawMessage, eventRecorder record.EventRecorder,
+	clock clock.PassiveClock) (*Engine, error) {
 	// Use a discovery client capable of being refreshed.
 	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(
 		cacheddiscovery.NewMemCacheClient(
@@ -114,10 +162,9 @@ func New(kubeConfig *rest.Config, pluginConfigs map[string]utils.YamlRawMessage,
 	}, 30*time.Second)
 
 	e := &Engine{
-		engineProvisioner: newEngineProvisioner(kubeConfig, restMapper, pluginConfigs, eventRecorder),
-		InformerFactory:   NewInformerFactory(utils.ClientOrDie(*kubeConfig, "wing-engine")),
+		engineProvisioner: newEngineProvisioner(kubeConfig, restMapper, pluginConfigs, eventRecorder, clock),
+		InformerFactory:   NewInformerFactory(cache),
 	}
-	e.InformerFactory.Run(make(<-chan struct{}))
 	if err := e.loadPlugins(); err != nil {
 		return nil, err
 	}
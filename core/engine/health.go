@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthChecker could be implemented by scalers and replicators optionally,
+// to report whether their dependencies (e.g. metrics server) are available.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+type PluginHealth struct {
+	Endpoint string `json:"endpoint"`
+	Name     string `json:"name"`
+	// Checkable is false if plugin doesn't implement HealthChecker, which is regarded as healthy
+	Checkable bool      `json:"checkable"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Elapsed   string    `json:"elapsed,omitempty"`
+}
+
+// CheckPluginsHealth checks health of all plugins concurrently, each check is limited by timeout.
+// Results are sorted by endpoint and name.
+func (e *Engine) CheckPluginsHealth(ctx context.Context, timeout time.Duration) []PluginHealth {
+	type plugin struct {
+		endpoint string
+		name     string
+		instance interface{}
+	}
+	e.mu.RLock()
+	plugins := make([]plugin, 0, len(e.scalers)+len(e.replicators))
+	for name, scaler := range e.scalers {
+		plugins = append(plugins, plugin{endpoint: PluginEndpointScaler, name: name, instance: scaler})
+	}
+	for name, replicator := range e.replicators {
+		plugins = append(plugins, plugin{endpoint: PluginEndpointReplicator, name: name, instance: replicator})
+	}
+	e.mu.RUnlock()
+
+	results := make([]PluginHealth, len(plugins))
+	var wg sync.WaitGroup
+	for i, p := range plugins {
+		results[i] = PluginHealth{
+			Endpoint:  p.endpoint,
+			Name:      p.name,
+			Healthy:   true,
+			CheckedAt: time.Now(),
+		}
+		checker, ok := p.instance.(HealthChecker)
+		if !ok {
+			continue
+		}
+		results[i].Checkable = true
+		wg.Add(1)
+		go func(result *PluginHealth) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := checker.CheckHealth(checkCtx); err != nil {
+				result.Healthy = false
+				result.Error = err.Error()
+			}
+			result.Elapsed = time.Since(result.CheckedAt).String()
+		}(&results[i])
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Endpoint != results[j].Endpoint {
+			return results[i].Endpoint < results[j].Endpoint
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
@@ -1,42 +1,69 @@
 package engine
 
 import (
-	"fmt"
-	"time"
+	"context"
 
-	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/informers"
-	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// InformerFactory provides listers backed by the shared controller-runtime cache,
+// so that there is only one watch per resource in the whole controller.
+// Scope of cached objects is decided by the cache, see controllers.NewCache.
 type InformerFactory struct {
-	factory informers.SharedInformerFactory
+	cache cache.Cache
 }
 
-func NewInformerFactory(clientSet kubernetes.Interface) *InformerFactory {
-	f := &InformerFactory{
-		factory: informers.NewSharedInformerFactory(clientSet, time.Minute),
+func NewInformerFactory(cache cache.Cache) *InformerFactory {
+	return &InformerFactory{
+		cache: cache,
 	}
-	return f
 }
 
-func (f *InformerFactory) Run(stopCh <-chan struct{}) {
-	defer runtime.HandleCrash()
+func (f InformerFactory) PodLister() listerscorev1.PodLister {
+	return &podLister{cache: f.cache}
+}
 
-	// Add informer here
-	f.factory.Core().V1().Pods().Informer()
+// podLister adapts cache to PodLister, listed pods are deep copied by cache.
+type podLister struct {
+	cache     cache.Cache
+	namespace string
+}
 
-	f.factory.Start(stopCh)
+var (
+	_ listerscorev1.PodLister          = &podLister{}
+	_ listerscorev1.PodNamespaceLister = &podLister{}
+)
 
-	syncs := f.factory.WaitForCacheSync(stopCh)
-	for typ, syn := range syncs {
-		if !syn {
-			runtime.HandleError(fmt.Errorf("wait for cache %s sync timeout", typ.String()))
-		}
+func (l *podLister) List(selector labels.Selector) ([]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := l.cache.List(context.TODO(), podList,
+		runtimeclient.InNamespace(l.namespace),
+		runtimeclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
 	}
+	return pods, nil
 }
 
-func (f InformerFactory) PodLister() listerscorev1.PodLister {
-	return f.factory.Core().V1().Pods().Lister()
+func (l *podLister) Pods(namespace string) listerscorev1.PodNamespaceLister {
+	return &podLister{cache: l.cache, namespace: namespace}
+}
+
+func (l *podLister) Get(name string) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	if err := l.cache.Get(context.TODO(), runtimeclient.ObjectKey{Namespace: l.namespace, Name: name}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFound(corev1.Resource("pod"), name)
+		}
+		return nil, err
+	}
+	return pod, nil
 }
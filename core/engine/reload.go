@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xscaling/wing/utils"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pluginReloader is the Controller given to plugins on reload, plugins added are staged
+// until all plugins reloaded. Previous instances are still available by GetScaler and GetReplicator.
+type pluginReloader struct {
+	*engineProvisioner
+	pluginConfigs map[string]utils.YamlRawMessage
+
+	scalers     map[string]Scaler
+	replicators map[string]Replicator
+	reloadHooks map[string]PluginReloadFunc
+}
+
+func (r *pluginReloader) GetPluginConfig(name string, configReceiver PluginConfig) (ok bool, err error) {
+	rawConfig, ok := r.pluginConfigs[name]
+	return getPluginConfig(rawConfig, ok, configReceiver)
+}
+
+func (r *pluginReloader) AddReplicator(name string, replicator Replicator) {
+	r.replicators[name] = replicator
+}
+
+func (r *pluginReloader) AddScaler(name string, scaler Scaler) {
+	r.scalers[name] = scaler
+}
+
+func (r *pluginReloader) SetReloadHook(endpoint, name string, hook PluginReloadFunc) {
+	r.reloadHooks[getPluginKey(endpoint, name)] = hook
+}
+
+// Reload sets up all plugins again with new plugin configs, plugins and configs are swapped at once
+// after all plugins reloaded. If any plugin failed to reload, nothing is swapped and all plugins keep
+// their previous instances and configs.
+func (e *Engine) Reload(pluginConfigs map[string]utils.YamlRawMessage) error {
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+
+	staged := &pluginReloader{
+		engineProvisioner: e.engineProvisioner,
+		pluginConfigs:     pluginConfigs,
+		scalers:           make(map[string]Scaler),
+		replicators:       make(map[string]Replicator),
+		reloadHooks:       make(map[string]PluginReloadFunc),
+	}
+	var errs []error
+	for _, endpoint := range []string{PluginEndpointReplicator, PluginEndpointScaler} {
+		pluginNames := Replicators
+		if endpoint == PluginEndpointScaler {
+			pluginNames = Scalers
+		}
+		for _, pluginName := range pluginNames {
+			if err := e.reloadPlugin(staged, endpoint, pluginName); err != nil {
+				log.Log.Error(err, "Failed to reload plugin", "endpoint", endpoint, "plugin", pluginName)
+				errs = append(errs, fmt.Errorf("%s plugin %s failed to reload: %w", endpoint, pluginName, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		log.Log.Info("Reloading plugins aborted, previous plugins are kept", "failed", len(errs))
+		return utilerrors.NewAggregate(errs)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for name, scaler := range staged.scalers {
+		e.scalers[name] = scaler
+	}
+	for name, replicator := range staged.replicators {
+		e.replicators[name] = replicator
+	}
+	for key, hook := range staged.reloadHooks {
+		e.reloadHooks[key] = hook
+	}
+	e.pluginConfigs = make(map[string]utils.YamlRawMessage, len(pluginConfigs))
+	for name, rawConfig := range pluginConfigs {
+		e.pluginConfigs[name] = rawConfig
+	}
+	log.Log.Info("Reloaded plugins", "scalers", len(staged.scalers), "replicators", len(staged.replicators))
+	return nil
+}
+
+func (e *Engine) reloadPlugin(reloader *pluginReloader, endpoint, pluginName string) error {
+	plugin, ok := GetPlugin(endpoint, pluginName)
+	if !ok {
+		return fmt.Errorf("plugin not exists")
+	}
+	e.mu.RLock()
+	hook, ok := e.reloadHooks[getPluginKey(endpoint, pluginName)]
+	e.mu.RUnlock()
+	if !ok {
+		return plugin.SetupFunc(reloader)
+	}
+	var previous interface{}
+	switch endpoint {
+	case PluginEndpointScaler:
+		previous, _ = e.GetScaler(pluginName)
+	case PluginEndpointReplicator:
+		previous, _ = e.GetReplicator(pluginName)
+	}
+	return hook(reloader, previous)
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xscaling/wing/utils"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type fakePluginConfig struct {
+	Value int `yaml:"value"`
+}
+
+func (c *fakePluginConfig) Validate() error {
+	if c.Value < 0 {
+		return errors.New("value must not be negative")
+	}
+	return nil
+}
+
+type fakeScaler struct {
+	Scaler
+	value int
+}
+
+type fakeReplicator struct {
+	Replicator
+	value int
+	// generation counts instances carried over by reload hook
+	generation int
+}
+
+func init() {
+	RegisterPlugin("reload-test", Plugin{
+		Endpoint: PluginEndpointScaler,
+		SetupFunc: func(c Controller) error {
+			config := &fakePluginConfig{}
+			if _, err := c.GetPluginConfig("reload-test-scaler", config); err != nil {
+				return err
+			}
+			c.AddScaler("reload-test", &fakeScaler{value: config.Value})
+			return nil
+		},
+	})
+	RegisterPlugin("reload-test", Plugin{
+		Endpoint: PluginEndpointReplicator,
+		SetupFunc: func(c Controller) error {
+			config := &fakePluginConfig{}
+			if _, err := c.GetPluginConfig("reload-test-replicator", config); err != nil {
+				return err
+			}
+			c.AddReplicator("reload-test", &fakeReplicator{value: config.Value})
+			c.SetReloadHook(PluginEndpointReplicator, "reload-test", func(c Controller, previous interface{}) error {
+				config := &fakePluginConfig{}
+				if _, err := c.GetPluginConfig("reload-test-replicator", config); err != nil {
+					return err
+				}
+				c.AddReplicator("reload-test", &fakeReplicator{
+					value:      config.Value,
+					generation: previous.(*fakeReplicator).generation + 1,
+				})
+				return nil
+			})
+			return nil
+		},
+	})
+}
+
+func TestEngineReload(t *testing.T) {
+	scalers, replicators := Scalers, Replicators
+	defer func() {
+		Scalers, Replicators = scalers, replicators
+	}()
+	Scalers, Replicators = []string{"reload-test"}, []string{"reload-test"}
+
+	parseConfigs := func(raw string) map[string]utils.YamlRawMessage {
+		configs := make(map[string]utils.YamlRawMessage)
+		require.NoError(t, yaml.Unmarshal([]byte(raw), &configs))
+		return configs
+	}
+	e := &Engine{engineProvisioner: &engineProvisioner{
+		scalers:       make(map[string]Scaler),
+		replicators:   make(map[string]Replicator),
+		pluginConfigs: parseConfigs(`{"reload-test-scaler":{"value":1},"reload-test-replicator":{"value":1}}`),
+		reloadHooks:   make(map[string]PluginReloadFunc),
+	}}
+	require.NoError(t, e.loadPlugins())
+	assertPlugins := func(scalerValue, replicatorValue, replicatorGeneration int) {
+		scaler, ok := e.GetScaler("reload-test")
+		require.True(t, ok)
+		require.Equal(t, scalerValue, scaler.(*fakeScaler).value)
+		replicator, ok := e.GetReplicator("reload-test")
+		require.True(t, ok)
+		require.Equal(t, replicatorValue, replicator.(*fakeReplicator).value)
+		require.Equal(t, replicatorGeneration, replicator.(*fakeReplicator).generation)
+		config := &fakePluginConfig{}
+		_, err := e.GetPluginConfig("reload-test-scaler", config)
+		require.NoError(t, err)
+		require.Equal(t, scalerValue, config.Value)
+	}
+	assertPlugins(1, 1, 0)
+
+	// Replicator is carried over by reload hook
+	require.NoError(t, e.Reload(parseConfigs(`{"reload-test-scaler":{"value":2},"reload-test-replicator":{"value":2}}`)))
+	assertPlugins(2, 2, 1)
+
+	// Nothing is swapped if any plugin failed to reload
+	require.Error(t, e.Reload(parseConfigs(`{"reload-test-scaler":{"value":-1},"reload-test-replicator":{"value":3}}`)))
+	assertPlugins(2, 2, 1)
+	require.Error(t, e.Reload(parseConfigs(`{"reload-test-scaler":{"value":3},"reload-test-replicator":{"value":-1}}`)))
+	assertPlugins(2, 2, 1)
+
+	// Configs removed are dropped
+	require.NoError(t, e.Reload(parseConfigs(`{"reload-test-replicator":{"value":4}}`)))
+	assertPlugins(0, 4, 2)
+}
@@ -1,9 +1,13 @@
 package engine
 
 import (
+	"context"
+	"time"
+
 	wingv1 "github.com/xscaling/wing/api/v1"
 
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/utils/clock"
 )
 
 type Replicator interface {
@@ -12,15 +16,41 @@ type Replicator interface {
 }
 
 type ReplicatorContext struct {
+	// Context carries trace of reconcile, it may be nil
+	Context context.Context
+	// Clock is where time of reconcile taken from, it may be nil
+	Clock         clock.PassiveClock
 	Autoscaler    *wingv1.ReplicaAutoscaler
 	Scale         *autoscalingv1.Scale
 	ScalersOutput map[string]ScalerOutput
+	// Decision is used by replicator and its tuners to explain how desired replicas is made
+	Decision *DecisionTrace
+}
+
+// GetContext returns context of reconcile, or an empty one if not set
+func (c ReplicatorContext) GetContext() context.Context {
+	if c.Context == nil {
+		return context.TODO()
+	}
+	return c.Context
+}
+
+// Now returns time of reconcile, or current time if clock not set
+func (c ReplicatorContext) Now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
 }
 
-func NewReplicatorContext(autoscaler *wingv1.ReplicaAutoscaler, scale *autoscalingv1.Scale) ReplicatorContext {
+func NewReplicatorContext(ctx context.Context, clock clock.PassiveClock, autoscaler *wingv1.ReplicaAutoscaler, scale *autoscalingv1.Scale,
+	decision *DecisionTrace) ReplicatorContext {
 	return ReplicatorContext{
+		Context:       ctx,
+		Clock:         clock,
 		Autoscaler:    autoscaler,
 		Scale:         scale,
 		ScalersOutput: make(map[string]ScalerOutput),
+		Decision:      decision,
 	}
 }
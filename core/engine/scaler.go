@@ -1,12 +1,15 @@
 package engine
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/clock"
 )
 
 type ScalerOutput struct {
@@ -20,6 +23,10 @@ type Scaler interface {
 
 type ScalerContext struct {
 	*InformerFactory
+	// Context carries trace of reconcile, it may be nil
+	Context context.Context
+	// Clock is where time of reconcile taken from, it may be nil
+	Clock                clock.PassiveClock
 	RawSettings          []byte
 	ScaleTargetRef       wingv1.CrossVersionObjectReference
 	Namespace            string
@@ -28,6 +35,22 @@ type ScalerContext struct {
 	AutoscalerStatus     *wingv1.ReplicaAutoscalerStatus
 }
 
+// GetContext returns context of reconcile, or an empty one if not set
+func (c ScalerContext) GetContext() context.Context {
+	if c.Context == nil {
+		return context.TODO()
+	}
+	return c.Context
+}
+
+// Now returns time of reconcile, or current time if clock not set
+func (c ScalerContext) Now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
 func (c ScalerContext) LoadSettings(receiver interface{}) error {
 	err := json.Unmarshal(c.RawSettings, receiver)
 	if err != nil {
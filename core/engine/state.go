@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StatefulPlugin is optionally implemented by scalers and replicators keeping in-memory state per autoscaler.
+// The state is handed over between controllers when ownership of autoscaler moves.
+type StatefulPlugin interface {
+	// ExportState returns nil if there is no state of the autoscaler
+	ExportState(autoscaler types.NamespacedName) ([]byte, error)
+	ImportState(autoscaler types.NamespacedName, state []byte) error
+	ForgetState(autoscaler types.NamespacedName)
+}
+
+func (e *Engine) statefulPlugins() map[string]StatefulPlugin {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	plugins := make(map[string]StatefulPlugin)
+	for name, scaler := range e.scalers {
+		if plugin, ok := scaler.(StatefulPlugin); ok {
+			plugins[getPluginKey(PluginEndpointScaler, name)] = plugin
+		}
+	}
+	for name, replicator := range e.replicators {
+		if plugin, ok := replicator.(StatefulPlugin); ok {
+			plugins[getPluginKey(PluginEndpointReplicator, name)] = plugin
+		}
+	}
+	return plugins
+}
+
+// ExportStates exports states of all stateful plugins for the autoscaler, keyed by `endpoint/plugin`
+func (e *Engine) ExportStates(autoscaler types.NamespacedName) (map[string][]byte, error) {
+	states := make(map[string][]byte)
+	for name, plugin := range e.statefulPlugins() {
+		state, err := plugin.ExportState(autoscaler)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export state of %s: %w", name, err)
+		}
+		if state != nil {
+			states[name] = state
+		}
+	}
+	return states, nil
+}
+
+// ImportStates imports states exported by ExportStates, states of unknown plugins are ignored
+func (e *Engine) ImportStates(autoscaler types.NamespacedName, states map[string][]byte) error {
+	plugins := e.statefulPlugins()
+	for name, state := range states {
+		plugin, ok := plugins[name]
+		if !ok {
+			continue
+		}
+		if err := plugin.ImportState(autoscaler, state); err != nil {
+			return fmt.Errorf("failed to import state of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ForgetStates drops states of the autoscaler in all stateful plugins
+func (e *Engine) ForgetStates(autoscaler types.NamespacedName) {
+	for _, plugin := range e.statefulPlugins() {
+		plugin.ForgetState(autoscaler)
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeStatefulScaler keeps state per autoscaler in memory
+type fakeStatefulScaler struct {
+	Scaler
+	states map[types.NamespacedName][]byte
+}
+
+func (s *fakeStatefulScaler) ExportState(autoscaler types.NamespacedName) ([]byte, error) {
+	return s.states[autoscaler], nil
+}
+
+func (s *fakeStatefulScaler) ImportState(autoscaler types.NamespacedName, state []byte) error {
+	if string(state) == "broken" {
+		return errors.New("broken state")
+	}
+	s.states[autoscaler] = state
+	return nil
+}
+
+func (s *fakeStatefulScaler) ForgetState(autoscaler types.NamespacedName) {
+	delete(s.states, autoscaler)
+}
+
+func TestEngineStatesHandover(t *testing.T) {
+	newEngine := func() (*Engine, *fakeStatefulScaler) {
+		scaler := &fakeStatefulScaler{states: make(map[types.NamespacedName][]byte)}
+		return &Engine{engineProvisioner: &engineProvisioner{
+			scalers: map[string]Scaler{
+				"stateful": scaler,
+				// Stateless plugins are skipped
+				"stateless": nil,
+			},
+			replicators: map[string]Replicator{},
+		}}, scaler
+	}
+	foo := types.NamespacedName{Namespace: "default", Name: "foo"}
+	bar := types.NamespacedName{Namespace: "default", Name: "bar"}
+
+	previous, previousScaler := newEngine()
+	previousScaler.states[foo] = []byte("foo")
+	states, err := previous.ExportStates(foo)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{getPluginKey(PluginEndpointScaler, "stateful"): []byte("foo")}, states)
+	// Autoscaler without state exports nothing
+	states, err = previous.ExportStates(bar)
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	next, nextScaler := newEngine()
+	nextScaler.states[foo] = []byte("stale")
+	require.NoError(t, next.ImportStates(foo, map[string][]byte{
+		getPluginKey(PluginEndpointScaler, "stateful"): []byte("foo"),
+		// States of unknown plugins are ignored
+		getPluginKey(PluginEndpointReplicator, "unknown"): []byte("unknown"),
+	}))
+	require.Equal(t, []byte("foo"), nextScaler.states[foo])
+	require.Error(t, next.ImportStates(bar, map[string][]byte{getPluginKey(PluginEndpointScaler, "stateful"): []byte("broken")}))
+
+	next.ForgetStates(foo)
+	require.Empty(t, nextScaler.states)
+}
@@ -0,0 +1,77 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+)
+
+var (
+	ErrInvalidMember = errors.New("invalid member")
+)
+
+// ValidateMembers checks scale target refs, ratios and replica limits of members
+func ValidateMembers(members []wingv1.ScaleTargetMember) error {
+	for i, member := range members {
+		if member.ScaleTargetRef.Name == "" || member.ScaleTargetRef.Kind == "" {
+			return fmt.Errorf("%w: name or kind of member[%d] missing", ErrInvalidMember, i)
+		}
+		if member.Ratio.MilliValue() <= 0 {
+			return fmt.Errorf("%w: ratio `%s` of member[%d] is not positive",
+				ErrInvalidMember, member.Ratio.String(), i)
+		}
+		if member.MinReplicas != nil && member.MaxReplicas != nil && *member.MinReplicas > *member.MaxReplicas {
+			return fmt.Errorf("%w: min replicas %d of member[%d] is greater than max replicas %d",
+				ErrInvalidMember, *member.MinReplicas, i, *member.MaxReplicas)
+		}
+	}
+	return nil
+}
+
+// GetMemberReplicas returns replicas of member following replicas of scale target by ratio,
+// rounded by rounding of member and limited by its min and max replicas.
+func GetMemberReplicas(replicas int32, member wingv1.ScaleTargetMember) int32 {
+	// Ratio is taken in milli to avoid float rounding errors, e.g. 0.1 * 30 should be exactly 3
+	product := member.Ratio.MilliValue() * int64(replicas)
+	var memberReplicas int64
+	switch member.Rounding {
+	case wingv1.MemberRoundingFloor:
+		memberReplicas = product / 1000
+	case wingv1.MemberRoundingRound:
+		memberReplicas = (product + 500) / 1000
+	default:
+		memberReplicas = (product + 999) / 1000
+	}
+	if member.MaxReplicas != nil && memberReplicas > int64(*member.MaxReplicas) {
+		memberReplicas = int64(*member.MaxReplicas)
+	}
+	if member.MinReplicas != nil && memberReplicas < int64(*member.MinReplicas) {
+		memberReplicas = int64(*member.MinReplicas)
+	}
+	if memberReplicas > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(memberReplicas)
+}
+
+// GetTotalReplicas returns total replicas of scale target and members following it
+func GetTotalReplicas(replicas int32, members []wingv1.ScaleTargetMember) int64 {
+	total := int64(replicas)
+	for _, member := range members {
+		total += int64(GetMemberReplicas(replicas, member))
+	}
+	return total
+}
+
+// LimitReplicasByBudget lowers desired replicas of scale target until total replicas of the group fit in budget,
+// but never below min replicas. Min replicas is returned if the budget could not be met.
+func LimitReplicasByBudget(desiredReplicas, minReplicas int32,
+	members []wingv1.ScaleTargetMember, budget int32) (replicas int32, limited bool) {
+	replicas = desiredReplicas
+	for replicas > minReplicas && GetTotalReplicas(replicas, members) > int64(budget) {
+		replicas--
+	}
+	return replicas, replicas != desiredReplicas
+}
@@ -0,0 +1,101 @@
+package group
+
+import (
+	"errors"
+	"testing"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+func newMember(name, ratio string, rounding wingv1.MemberRounding) wingv1.ScaleTargetMember {
+	return wingv1.ScaleTargetMember{
+		ScaleTargetRef: wingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+		Ratio:          resource.MustParse(ratio),
+		Rounding:       rounding,
+	}
+}
+
+func TestValidateMembers(t *testing.T) {
+	limited := newMember("cache", "1", "")
+	limited.MinReplicas, limited.MaxReplicas = pointer.Int32(5), pointer.Int32(3)
+	for _, c := range []struct {
+		description string
+		member      wingv1.ScaleTargetMember
+		valid       bool
+	}{
+		{description: "Valid", member: newMember("worker", "0.5", ""), valid: true},
+		{description: "Name missing", member: newMember("", "0.5", "")},
+		{description: "Zero ratio", member: newMember("worker", "0", "")},
+		{description: "Negative ratio", member: newMember("worker", "-1", "")},
+		{description: "Min greater than max", member: limited},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			err := ValidateMembers([]wingv1.ScaleTargetMember{c.member})
+			if c.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, ErrInvalidMember), err)
+		})
+	}
+}
+
+func TestGetMemberReplicas(t *testing.T) {
+	limited := newMember("cache", "0.1", "")
+	limited.MinReplicas, limited.MaxReplicas = pointer.Int32(2), pointer.Int32(4)
+	for _, c := range []struct {
+		description string
+		replicas    int32
+		member      wingv1.ScaleTargetMember
+		expected    int32
+	}{
+		{description: "Ceil by default", replicas: 5, member: newMember("worker", "0.5", ""), expected: 3},
+		{description: "Floor", replicas: 5, member: newMember("worker", "0.5", wingv1.MemberRoundingFloor), expected: 2},
+		{description: "Round half up", replicas: 5, member: newMember("worker", "0.5", wingv1.MemberRoundingRound), expected: 3},
+		{description: "Round down", replicas: 5, member: newMember("worker", "0.3", wingv1.MemberRoundingRound), expected: 2},
+		{description: "Exact without float error", replicas: 30, member: newMember("worker", "0.1", ""), expected: 3},
+		{description: "Ratio over one", replicas: 3, member: newMember("worker", "2", ""), expected: 6},
+		{description: "Zero replicas", replicas: 0, member: newMember("worker", "2", ""), expected: 0},
+		{description: "Limited by min replicas", replicas: 5, member: limited, expected: 2},
+		{description: "Limited by max replicas", replicas: 100, member: limited, expected: 4},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			require.Equal(t, c.expected, GetMemberReplicas(c.replicas, c.member))
+		})
+	}
+}
+
+func TestLimitReplicasByBudget(t *testing.T) {
+	members := []wingv1.ScaleTargetMember{
+		newMember("worker", "0.5", ""),
+		newMember("cache", "0.25", wingv1.MemberRoundingFloor),
+	}
+	require.Equal(t, int64(10+5+2), GetTotalReplicas(10, members))
+
+	for _, c := range []struct {
+		description      string
+		desiredReplicas  int32
+		minReplicas      int32
+		budget           int32
+		expectedReplicas int32
+		expectedLimited  bool
+	}{
+		{description: "Within budget", desiredReplicas: 10, minReplicas: 1, budget: 17, expectedReplicas: 10},
+		{description: "Over budget", desiredReplicas: 10, minReplicas: 1, budget: 16, expectedReplicas: 9,
+			expectedLimited: true},
+		{description: "Lowered to the largest fitting replicas", desiredReplicas: 10, minReplicas: 1, budget: 12,
+			expectedReplicas: 7, expectedLimited: true},
+		{description: "Never below min replicas", desiredReplicas: 10, minReplicas: 8, budget: 5,
+			expectedReplicas: 8, expectedLimited: true},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			replicas, limited := LimitReplicasByBudget(c.desiredReplicas, c.minReplicas, members, c.budget)
+			require.Equal(t, c.expectedReplicas, replicas)
+			require.Equal(t, c.expectedLimited, limited)
+		})
+	}
+}
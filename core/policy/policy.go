@@ -0,0 +1,209 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// IsPolicySelecting returns whether the policy selects autoscaler in namespace with given labels,
+// nil selectors select everything.
+func IsPolicySelecting(policy wingv1.ClusterReplicaAutoscalerPolicy,
+	namespaceLabels map[string]string, autoscaler wingv1.ReplicaAutoscaler) (bool, error) {
+	for _, s := range []struct {
+		selector *metav1.LabelSelector
+		labels   map[string]string
+	}{
+		{selector: policy.Spec.NamespaceSelector, labels: namespaceLabels},
+		{selector: policy.Spec.AutoscalerSelector, labels: autoscaler.Labels},
+	} {
+		if s.selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(s.selector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(s.labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// RequiresNamespaceLabels returns whether any policy selects namespaces by labels
+func RequiresNamespaceLabels(policies []wingv1.ClusterReplicaAutoscalerPolicy) bool {
+	for _, policy := range policies {
+		if policy.Spec.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSelectingPolicies returns policies selecting autoscaler in order of precedence,
+// which is priority descending then name. Policies with broken selectors are skipped.
+func GetSelectingPolicies(policies []wingv1.ClusterReplicaAutoscalerPolicy,
+	namespaceLabels map[string]string, autoscaler wingv1.ReplicaAutoscaler) []wingv1.ClusterReplicaAutoscalerPolicy {
+	var selecting []wingv1.ClusterReplicaAutoscalerPolicy
+	for _, policy := range policies {
+		if selected, err := IsPolicySelecting(policy, namespaceLabels, autoscaler); err == nil && selected {
+			selecting = append(selecting, policy)
+		}
+	}
+	sort.SliceStable(selecting, func(i, j int) bool {
+		if selecting[i].Spec.Priority != selecting[j].Spec.Priority {
+			return selecting[i].Spec.Priority > selecting[j].Spec.Priority
+		}
+		return selecting[i].Name < selecting[j].Name
+	})
+	return selecting
+}
+
+// ApplyPolicies applies limits and defaults of policies in order of precedence to spec of autoscaler,
+// and returns the effective settings. Nil is returned if there is no policy.
+// Limits are always applied, defaults failed to merge are reported by error and left unmerged.
+func ApplyPolicies(autoscaler *wingv1.ReplicaAutoscaler, policies []wingv1.ClusterReplicaAutoscalerPolicy,
+	defaultScalingColdDown time.Duration) (*wingv1.AutoscalerPolicyStatus, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	status := &wingv1.AutoscalerPolicyStatus{
+		ScalingColdDownSeconds: int32(defaultScalingColdDown / time.Second),
+	}
+	for _, policy := range policies {
+		status.Policies = append(status.Policies, policy.Name)
+	}
+	applyLimits(autoscaler, policies, status)
+	err := applyDefaults(autoscaler, policies)
+
+	status.MaxReplicas = autoscaler.Spec.MaxReplicas
+	status.Strategy = autoscaler.Spec.Strategy
+	status.Exhaust = autoscaler.Spec.Exhaust
+	status.ReplicatorSettings = autoscaler.Spec.ReplicatorSettings
+	return status, err
+}
+
+func applyLimits(autoscaler *wingv1.ReplicaAutoscaler, policies []wingv1.ClusterReplicaAutoscalerPolicy,
+	status *wingv1.AutoscalerPolicyStatus) {
+	var allowedScalers []map[string]bool
+	for _, policy := range policies {
+		limits := policy.Spec.Limits
+		if limits == nil {
+			continue
+		}
+		if limits.MaxReplicas != nil && (status.MaxReplicasCeiling == nil || *limits.MaxReplicas < *status.MaxReplicasCeiling) {
+			ceiling := *limits.MaxReplicas
+			status.MaxReplicasCeiling = &ceiling
+		}
+		if limits.MinScalingColdDownSeconds != nil && *limits.MinScalingColdDownSeconds > status.ScalingColdDownSeconds {
+			status.ScalingColdDownSeconds = *limits.MinScalingColdDownSeconds
+		}
+		if len(limits.AllowedScalers) > 0 {
+			allowed := make(map[string]bool, len(limits.AllowedScalers))
+			for _, scaler := range limits.AllowedScalers {
+				allowed[scaler] = true
+			}
+			allowedScalers = append(allowedScalers, allowed)
+		}
+	}
+
+	if ceiling := status.MaxReplicasCeiling; ceiling != nil && autoscaler.Spec.MaxReplicas > *ceiling {
+		autoscaler.Spec.MaxReplicas = *ceiling
+		if autoscaler.Spec.MinReplicas != nil && *autoscaler.Spec.MinReplicas > *ceiling {
+			minReplicas := *ceiling
+			autoscaler.Spec.MinReplicas = &minReplicas
+		}
+	}
+	// Members follow replicas by ratio, so they are limited by the ceiling on their own
+	for i := range autoscaler.Spec.Members {
+		member := &autoscaler.Spec.Members[i]
+		if ceiling := status.MaxReplicasCeiling; ceiling != nil {
+			if member.MaxReplicas == nil || *member.MaxReplicas > *ceiling {
+				maxReplicas := *ceiling
+				member.MaxReplicas = &maxReplicas
+			}
+			if member.MinReplicas != nil && *member.MinReplicas > *ceiling {
+				minReplicas := *ceiling
+				member.MinReplicas = &minReplicas
+			}
+		}
+	}
+	for _, target := range autoscaler.Spec.Targets {
+		for _, allowed := range allowedScalers {
+			if !allowed[target.Metric] {
+				status.DisallowedScalers = append(status.DisallowedScalers, target.Metric)
+				break
+			}
+		}
+	}
+}
+
+func applyDefaults(autoscaler *wingv1.ReplicaAutoscaler, policies []wingv1.ClusterReplicaAutoscalerPolicy) error {
+	var (
+		replicatorSettingsBases []*runtime.RawExtension
+		targetSettingsBases     = make(map[string][]*runtime.RawExtension)
+	)
+	for _, policy := range policies {
+		defaults := policy.Spec.Defaults
+		if defaults == nil {
+			continue
+		}
+		if autoscaler.Spec.Strategy == nil && defaults.Strategy != nil {
+			autoscaler.Spec.Strategy = defaults.Strategy.DeepCopy()
+		}
+		if autoscaler.Spec.Exhaust == nil && defaults.Exhaust != nil {
+			autoscaler.Spec.Exhaust = defaults.Exhaust.DeepCopy()
+		}
+		if defaults.ReplicatorSettings != nil {
+			replicatorSettingsBases = append(replicatorSettingsBases, defaults.ReplicatorSettings)
+		}
+		for _, target := range defaults.Targets {
+			if target.Settings != nil {
+				targetSettingsBases[target.Metric] = append(targetSettingsBases[target.Metric], target.Settings)
+			}
+		}
+	}
+
+	var err error
+	if autoscaler.Spec.ReplicatorSettings, err = mergeSettings(replicatorSettingsBases,
+		autoscaler.Spec.ReplicatorSettings); err != nil {
+		return fmt.Errorf("failed to merge replicator settings: %w", err)
+	}
+	for i := range autoscaler.Spec.Targets {
+		target := &autoscaler.Spec.Targets[i]
+		if target.Settings.Default, err = mergeSettings(targetSettingsBases[target.Metric],
+			target.Settings.Default); err != nil {
+			return fmt.Errorf("failed to merge default settings of target %s: %w", target.Metric, err)
+		}
+	}
+	return nil
+}
+
+// mergeSettings merges bases in order of precedence from the lowest one, then settings on the top.
+// Settings are returned as is if there is no base or merging fails.
+func mergeSettings(bases []*runtime.RawExtension, settings *runtime.RawExtension) (*runtime.RawExtension, error) {
+	if len(bases) == 0 {
+		return settings, nil
+	}
+	payload := []byte("{}")
+	var err error
+	for i := len(bases) - 1; i >= 0; i-- {
+		if payload, err = jsonpatch.MergePatch(payload, bases[i].Raw); err != nil {
+			return settings, err
+		}
+	}
+	if settings != nil && len(settings.Raw) > 0 {
+		if payload, err = jsonpatch.MergePatch(payload, settings.Raw); err != nil {
+			return settings, err
+		}
+	}
+	return &runtime.RawExtension{Raw: payload}, nil
+}
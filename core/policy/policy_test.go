@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func newPolicy(name string, priority int32, spec wingv1.ClusterReplicaAutoscalerPolicySpec) wingv1.ClusterReplicaAutoscalerPolicy {
+	spec.Priority = priority
+	return wingv1.ClusterReplicaAutoscalerPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func newAutoscaler(targets ...wingv1.ReplicaAutoscalerTarget) *wingv1.ReplicaAutoscaler {
+	return &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+		Spec: wingv1.ReplicaAutoscalerSpec{
+			MinReplicas: pointer.Int32(2),
+			MaxReplicas: 50,
+			Targets:     targets,
+		},
+	}
+}
+
+func TestGetSelectingPolicies(t *testing.T) {
+	autoscaler := newAutoscaler()
+	policies := []wingv1.ClusterReplicaAutoscalerPolicy{
+		newPolicy("everything", 0, wingv1.ClusterReplicaAutoscalerPolicySpec{}),
+		newPolicy("production", 10, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+		}),
+		newPolicy("backend", 20, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			AutoscalerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+		}),
+		newPolicy("frontend", 10, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			AutoscalerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+		}),
+		newPolicy("broken", 30, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			AutoscalerSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			}},
+		}),
+	}
+	require.True(t, RequiresNamespaceLabels(policies))
+	require.False(t, RequiresNamespaceLabels(policies[:1]))
+
+	for _, c := range []struct {
+		description     string
+		namespaceLabels map[string]string
+		expected        []string
+	}{
+		{
+			description: "Namespace not selected",
+			expected:    []string{"frontend", "everything"},
+		},
+		{
+			description:     "Namespace selected and same priority ordered by name",
+			namespaceLabels: map[string]string{"env": "production"},
+			expected:        []string{"frontend", "production", "everything"},
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			var names []string
+			for _, policy := range GetSelectingPolicies(policies, c.namespaceLabels, *autoscaler) {
+				names = append(names, policy.Name)
+			}
+			require.Equal(t, c.expected, names)
+		})
+	}
+}
+
+func TestApplyPoliciesLimits(t *testing.T) {
+	autoscaler := newAutoscaler(
+		wingv1.ReplicaAutoscalerTarget{Metric: "cpu"},
+		wingv1.ReplicaAutoscalerTarget{Metric: "prometheus"},
+		wingv1.ReplicaAutoscalerTarget{Metric: "memory"},
+	)
+	status, err := ApplyPolicies(autoscaler, []wingv1.ClusterReplicaAutoscalerPolicy{
+		newPolicy("strict", 10, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			Limits: &wingv1.ReplicaAutoscalerPolicyLimits{
+				MaxReplicas:               pointer.Int32(20),
+				MinScalingColdDownSeconds: pointer.Int32(120),
+				AllowedScalers:            []string{"cpu", "memory"},
+			},
+		}),
+		newPolicy("loose", 0, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			Limits: &wingv1.ReplicaAutoscalerPolicyLimits{
+				MaxReplicas:               pointer.Int32(1),
+				MinScalingColdDownSeconds: pointer.Int32(10),
+				AllowedScalers:            []string{"cpu", "prometheus"},
+			},
+		}),
+	}, 30*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []string{"strict", "loose"}, status.Policies)
+	require.Equal(t, int32(1), *status.MaxReplicasCeiling)
+	require.Equal(t, int32(1), status.MaxReplicas)
+	require.Equal(t, int32(120), status.ScalingColdDownSeconds)
+	require.Equal(t, []string{"prometheus", "memory"}, status.DisallowedScalers)
+	require.Equal(t, int32(1), autoscaler.Spec.MaxReplicas)
+	require.Equal(t, int32(1), *autoscaler.Spec.MinReplicas)
+
+	autoscaler = newAutoscaler()
+	autoscaler.Spec.Members = []wingv1.ScaleTargetMember{
+		{ScaleTargetRef: wingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "worker"}},
+		{ScaleTargetRef: wingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "cache"},
+			MinReplicas: pointer.Int32(30), MaxReplicas: pointer.Int32(40)},
+		{ScaleTargetRef: wingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "proxy"},
+			MaxReplicas: pointer.Int32(5)},
+	}
+	_, err = ApplyPolicies(autoscaler, []wingv1.ClusterReplicaAutoscalerPolicy{
+		newPolicy("ceiling", 0, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			Limits: &wingv1.ReplicaAutoscalerPolicyLimits{MaxReplicas: pointer.Int32(20)},
+		}),
+	}, 30*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int32(20), *autoscaler.Spec.Members[0].MaxReplicas)
+	require.Nil(t, autoscaler.Spec.Members[0].MinReplicas)
+	require.Equal(t, int32(20), *autoscaler.Spec.Members[1].MinReplicas)
+	require.Equal(t, int32(20), *autoscaler.Spec.Members[1].MaxReplicas)
+	require.Equal(t, int32(5), *autoscaler.Spec.Members[2].MaxReplicas)
+
+	status, err = ApplyPolicies(newAutoscaler(), nil, 30*time.Second)
+	require.NoError(t, err)
+	require.Nil(t, status)
+}
+
+func TestApplyPoliciesDefaults(t *testing.T) {
+	raw := func(payload string) *runtime.RawExtension {
+		return &runtime.RawExtension{Raw: []byte(payload)}
+	}
+	highStrategy := &wingv1.ReplicaAutoscalerStrategy{PanicWindowSeconds: pointer.Int32(60)}
+	policies := []wingv1.ClusterReplicaAutoscalerPolicy{
+		newPolicy("high", 10, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			Defaults: &wingv1.ReplicaAutoscalerPolicyDefaults{
+				Strategy:           highStrategy,
+				ReplicatorSettings: raw(`{"tolerance":0.1}`),
+				Targets: []wingv1.ReplicaAutoscalerPolicyTarget{
+					{Metric: "cpu", Settings: raw(`{"utilization":60}`)},
+				},
+			},
+		}),
+		newPolicy("low", 0, wingv1.ClusterReplicaAutoscalerPolicySpec{
+			Defaults: &wingv1.ReplicaAutoscalerPolicyDefaults{
+				Strategy:           &wingv1.ReplicaAutoscalerStrategy{PanicWindowSeconds: pointer.Int32(30)},
+				Exhaust:            &wingv1.Exhaust{Type: wingv1.ExhaustOnPending},
+				ReplicatorSettings: raw(`{"tolerance":0.2,"stabilizationSeconds":300}`),
+				Targets: []wingv1.ReplicaAutoscalerPolicyTarget{
+					{Metric: "cpu", Settings: raw(`{"utilization":50,"containers":["app"]}`)},
+					{Metric: "memory", Settings: raw(`{"utilization":80}`)},
+				},
+			},
+		}),
+	}
+
+	autoscaler := newAutoscaler(
+		wingv1.ReplicaAutoscalerTarget{Metric: "cpu", Settings: wingv1.TargetSettings{Default: raw(`{"utilization":70}`)}},
+		wingv1.ReplicaAutoscalerTarget{Metric: "memory"},
+		wingv1.ReplicaAutoscalerTarget{Metric: "prometheus", Settings: wingv1.TargetSettings{Default: raw(`{"query":"up"}`)}},
+	)
+	autoscaler.Spec.ReplicatorSettings = raw(`{"stabilizationSeconds":60}`)
+	status, err := ApplyPolicies(autoscaler, policies, 30*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, highStrategy, status.Strategy)
+	require.Equal(t, wingv1.ExhaustOnPending, status.Exhaust.Type)
+	require.Nil(t, status.MaxReplicasCeiling)
+	require.Equal(t, int32(30), status.ScalingColdDownSeconds)
+	require.JSONEq(t, `{"tolerance":0.1,"stabilizationSeconds":60}`, string(status.ReplicatorSettings.Raw))
+	require.JSONEq(t, `{"utilization":70,"containers":["app"]}`, string(autoscaler.Spec.Targets[0].Settings.Default.Raw))
+	require.JSONEq(t, `{"utilization":80}`, string(autoscaler.Spec.Targets[1].Settings.Default.Raw))
+	require.JSONEq(t, `{"query":"up"}`, string(autoscaler.Spec.Targets[2].Settings.Default.Raw))
+
+	autoscaler = newAutoscaler()
+	autoscaler.Spec.Strategy = &wingv1.ReplicaAutoscalerStrategy{}
+	autoscaler.Spec.ReplicatorSettings = raw(`not json`)
+	status, err = ApplyPolicies(autoscaler, policies, 30*time.Second)
+	require.Error(t, err)
+	require.Equal(t, &wingv1.ReplicaAutoscalerStrategy{}, status.Strategy)
+	require.Equal(t, `not json`, string(status.ReplicatorSettings.Raw))
+}
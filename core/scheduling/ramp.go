@@ -0,0 +1,110 @@
+package scheduling
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	ErrInvalidReplicaPatchRamp = errors.New("replica patch ramp requires positive duration and non-negative steps")
+)
+
+// ValidateReplicaPatchRamp validates ramp of replica patch if any
+func ValidateReplicaPatchRamp(ramp *wingv1.ReplicaPatchRamp) error {
+	if ramp != nil && (ramp.Duration.Duration <= 0 || ramp.Steps < 0) {
+		return ErrInvalidReplicaPatchRamp
+	}
+	return nil
+}
+
+// GetReplicaRamp returns the ramp of scaling range at given time, nil means the target range applies at once.
+// Target range is the range of effective patch or the default one if no patch works. Once it changes, a new
+// ramp starts from the range applied by the previous ramp or patch, with ramp of the entering patch or
+// the exiting one. Ramp in progress keeps working until done.
+func GetReplicaRamp(when time.Time, previous *wingv1.ReplicaRampStatus,
+	previousPatch, effective *wingv1.ReplicaPatchStatus, minReplicas, maxReplicas int32) *wingv1.ReplicaRampStatus {
+	toMin, toMax := minReplicas, maxReplicas
+	if effective != nil {
+		toMin, toMax = effective.MinReplicas, effective.MaxReplicas
+	}
+	// Range applied currently and the one targeted previously
+	fromMin, fromMax := minReplicas, maxReplicas
+	if previousPatch != nil {
+		fromMin, fromMax = previousPatch.MinReplicas, previousPatch.MaxReplicas
+	}
+	targetMin, targetMax := fromMin, fromMax
+	if previous != nil {
+		fromMin, fromMax = GetRampedReplicaRange(when, *previous)
+		targetMin, targetMax = previous.ToMinReplicas, previous.ToMaxReplicas
+	}
+
+	if targetMin == toMin && targetMax == toMax {
+		if previous != nil && when.Before(previous.StartTime.Add(previous.Duration.Duration)) {
+			return previous
+		}
+		return nil
+	}
+	var ramp *wingv1.ReplicaPatchRamp
+	switch {
+	case effective != nil:
+		ramp = effective.Ramp
+	case previousPatch != nil:
+		ramp = previousPatch.Ramp
+	}
+	if ramp == nil || ValidateReplicaPatchRamp(ramp) != nil {
+		return nil
+	}
+	return &wingv1.ReplicaRampStatus{
+		FromMinReplicas:  fromMin,
+		FromMaxReplicas:  fromMax,
+		ToMinReplicas:    toMin,
+		ToMaxReplicas:    toMax,
+		StartTime:        metav1.NewTime(when),
+		ReplicaPatchRamp: *ramp,
+	}
+}
+
+// GetRampedReplicaRange returns scaling range interpolated by progress of ramp at given time
+func GetRampedReplicaRange(when time.Time, ramp wingv1.ReplicaRampStatus) (minReplicas, maxReplicas int32) {
+	progress := getReplicaRampProgress(when, ramp)
+	interpolate := func(from, to int32) int32 {
+		return from + int32(math.Round(float64(to-from)*progress))
+	}
+	return interpolate(ramp.FromMinReplicas, ramp.ToMinReplicas), interpolate(ramp.FromMaxReplicas, ramp.ToMaxReplicas)
+}
+
+// GetReplicaRampNextTransition returns the next step of stepwise ramp or the end of linear ramp,
+// zero time means the ramp is done.
+func GetReplicaRampNextTransition(when time.Time, ramp wingv1.ReplicaRampStatus) time.Time {
+	end := ramp.StartTime.Add(ramp.Duration.Duration)
+	if !when.Before(end) {
+		return time.Time{}
+	}
+	if ramp.Steps <= 0 {
+		return end
+	}
+	step := math.Floor(getReplicaRampProgress(when, ramp)*float64(ramp.Steps)) + 1
+	return ramp.StartTime.Add(time.Duration(float64(ramp.Duration.Duration) * step / float64(ramp.Steps)))
+}
+
+// getReplicaRampProgress returns progress of ramp in [0, 1], which is rounded down to steps if any
+func getReplicaRampProgress(when time.Time, ramp wingv1.ReplicaRampStatus) float64 {
+	if ramp.Duration.Duration <= 0 {
+		return 1
+	}
+	progress := float64(when.Sub(ramp.StartTime.Time)) / float64(ramp.Duration.Duration)
+	switch {
+	case progress <= 0:
+		return 0
+	case progress >= 1:
+		return 1
+	case ramp.Steps > 0:
+		return math.Floor(progress*float64(ramp.Steps)) / float64(ramp.Steps)
+	}
+	return progress
+}
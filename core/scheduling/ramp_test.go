@@ -0,0 +1,71 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetReplicaRamp(t *testing.T) {
+	start := time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC)
+	ramp := &wingv1.ReplicaPatchRamp{Duration: metav1.Duration{Duration: 10 * time.Minute}}
+	patch := &wingv1.ReplicaPatchStatus{MinReplicas: 200, MaxReplicas: 400, Sources: []string{"annotation[0]"}, Ramp: ramp}
+
+	// Entering patch ramps from the default range
+	entering := GetReplicaRamp(start, nil, nil, patch, 10, 20)
+	require.Equal(t, &wingv1.ReplicaRampStatus{
+		FromMinReplicas: 10, FromMaxReplicas: 20, ToMinReplicas: 200, ToMaxReplicas: 400,
+		StartTime: metav1.NewTime(start), ReplicaPatchRamp: *ramp,
+	}, entering)
+	minReplicas, maxReplicas := GetRampedReplicaRange(start.Add(5*time.Minute), *entering)
+	require.Equal(t, []int32{105, 210}, []int32{minReplicas, maxReplicas})
+	// Keeps ramping until done
+	require.Equal(t, entering, GetReplicaRamp(start.Add(5*time.Minute), entering, patch, patch, 10, 20))
+	require.Nil(t, GetReplicaRamp(start.Add(10*time.Minute), entering, patch, patch, 10, 20))
+	require.Nil(t, GetReplicaRamp(start.Add(20*time.Minute), nil, patch, patch, 10, 20))
+
+	// Exiting patch during entering ramp starts from the ramped range
+	exiting := GetReplicaRamp(start.Add(5*time.Minute), entering, patch, nil, 10, 20)
+	require.NotNil(t, exiting)
+	require.Equal(t, []int32{105, 210, 10, 20},
+		[]int32{exiting.FromMinReplicas, exiting.FromMaxReplicas, exiting.ToMinReplicas, exiting.ToMaxReplicas})
+
+	// Patch without ramp applies at once
+	require.Nil(t, GetReplicaRamp(start, nil, nil, &wingv1.ReplicaPatchStatus{MinReplicas: 200, MaxReplicas: 400}, 10, 20))
+}
+
+func TestGetRampedReplicaRangeStepwise(t *testing.T) {
+	start := time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC)
+	ramp := wingv1.ReplicaRampStatus{
+		FromMinReplicas: 10, FromMaxReplicas: 20, ToMinReplicas: 50, ToMaxReplicas: 100,
+		StartTime:        metav1.NewTime(start),
+		ReplicaPatchRamp: wingv1.ReplicaPatchRamp{Duration: metav1.Duration{Duration: 4 * time.Minute}, Steps: 4},
+	}
+	for _, c := range []struct {
+		after          time.Duration
+		expectedMin    int32
+		expectedMax    int32
+		nextTransition time.Duration
+	}{
+		{after: 0, expectedMin: 10, expectedMax: 20, nextTransition: time.Minute},
+		{after: 90 * time.Second, expectedMin: 20, expectedMax: 40, nextTransition: 2 * time.Minute},
+		{after: 3 * time.Minute, expectedMin: 40, expectedMax: 80, nextTransition: 4 * time.Minute},
+		{after: 5 * time.Minute, expectedMin: 50, expectedMax: 100},
+	} {
+		when := start.Add(c.after)
+		minReplicas, maxReplicas := GetRampedReplicaRange(when, ramp)
+		require.Equal(t, c.expectedMin, minReplicas, "after %s", c.after)
+		require.Equal(t, c.expectedMax, maxReplicas, "after %s", c.after)
+		expectedTransition := time.Time{}
+		if c.nextTransition != 0 {
+			expectedTransition = start.Add(c.nextTransition)
+		}
+		require.Equal(t, expectedTransition, GetReplicaRampNextTransition(when, ramp), "after %s", c.after)
+	}
+
+	require.ErrorIs(t, ValidateReplicaPatchRamp(&wingv1.ReplicaPatchRamp{}), ErrInvalidReplicaPatchRamp)
+}
@@ -1,53 +1,129 @@
 package scheduling
 
 import (
-	"strings"
+	"errors"
+	"sort"
 	"time"
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 	"github.com/xscaling/wing/utils/timerange"
 )
 
+var (
+	ErrInvalidReplicaPatchRange = errors.New("replica patch requires 0 <= minReplicas <= maxReplicas")
+)
+
+// GetReplicaPatch returns the working replica patch of highest priority, nil means no patch works
 func GetReplicaPatch(when time.Time, patches wingv1.ReplicaPatches) (*wingv1.ReplicaPatch, error) {
-	var (
-		start string
-		end   string
-	)
-	for _, patch := range patches {
-		if patch.Timezone == "" {
-			return nil, ErrTimezoneNotFound
-		}
-		timezone, err := time.LoadLocation(patch.Timezone)
-		if err != nil {
-			return nil, err
-		}
-		start, end = patch.Start, patch.End
-		if start == "" || end == "" {
-			return nil, ErrSchedulePeriodNotFound
-		}
-		if start == end {
-			return nil, ErrStartEndSpecCanNotBeEqual
-		}
+	index, err := GetReplicaPatchIndex(when, patches)
+	if err != nil || index < 0 {
+		return nil, err
+	}
+	return &patches[index], nil
+}
 
-		var (
-			scheduler timerange.Scheduler
-		)
-		// Easy-Predict
-		switch len(strings.Split(start, timerange.CronFieldSeparator)) {
-		case 2:
-			scheduler, err = timerange.NewDateScheduler(timezone, start, end)
-		case 5:
-			scheduler, err = timerange.NewCronScheduler(timezone, start, end)
-		default:
-			return nil, timerange.ErrInvalidSchedulePeriodFormat
-		}
+// GetReplicaPatchIndex returns index of the working replica patch of highest priority, -1 means no patch works
+func GetReplicaPatchIndex(when time.Time, patches wingv1.ReplicaPatches) (int, error) {
+	indexes, err := GetReplicaPatchIndexes(when, patches)
+	if err != nil || len(indexes) == 0 {
+		return -1, err
+	}
+	return indexes[0], nil
+}
 
+// GetReplicaPatchIndexes returns indexes of replica patches working at given time in order of priority,
+// patches of the same priority keep their order.
+func GetReplicaPatchIndexes(when time.Time, patches wingv1.ReplicaPatches) ([]int, error) {
+	var indexes []int
+	for index, patch := range patches {
+		scheduler, err := GetReplicaPatchScheduler(patch)
 		if err != nil {
 			return nil, err
 		}
 		if scheduler.Contains(when) {
-			return &patch, nil
+			indexes = append(indexes, index)
 		}
 	}
-	return nil, nil
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return patches[indexes[i]].Priority > patches[indexes[j]].Priority
+	})
+	return indexes, nil
+}
+
+// GetEffectiveReplicaPatch combines replica patches working at given time by mode, sources are names of
+// patches shown in status. Nil is returned if no patch works.
+func GetEffectiveReplicaPatch(when time.Time, patches wingv1.ReplicaPatches, sources []string,
+	mode wingv1.ReplicaPatchMode) (*wingv1.ReplicaPatchStatus, error) {
+	indexes, err := GetReplicaPatchIndexes(when, patches)
+	if err != nil || len(indexes) == 0 {
+		return nil, err
+	}
+	if mode == "" {
+		mode = wingv1.ReplicaPatchHighestPriority
+	}
+	highest := patches[indexes[0]]
+	effective := &wingv1.ReplicaPatchStatus{
+		Mode:        mode,
+		MinReplicas: highest.MinReplicas,
+		MaxReplicas: highest.MaxReplicas,
+		Sources:     []string{sources[indexes[0]]},
+		Ramp:        highest.Ramp,
+	}
+	if mode == wingv1.ReplicaPatchHighestPriority {
+		return effective, nil
+	}
+
+	minReplicas, maxReplicas := highest.MinReplicas, highest.MaxReplicas
+	for _, index := range indexes[1:] {
+		patch := patches[index]
+		switch mode {
+		case wingv1.ReplicaPatchWidest:
+			minReplicas, maxReplicas = minInt32(minReplicas, patch.MinReplicas), maxInt32(maxReplicas, patch.MaxReplicas)
+		case wingv1.ReplicaPatchNarrowest:
+			minReplicas, maxReplicas = maxInt32(minReplicas, patch.MinReplicas), minInt32(maxReplicas, patch.MaxReplicas)
+		}
+	}
+	// Narrowest patches have no common range
+	if minReplicas > maxReplicas {
+		return effective, nil
+	}
+	effective.MinReplicas, effective.MaxReplicas = minReplicas, maxReplicas
+	for _, index := range indexes[1:] {
+		effective.Sources = append(effective.Sources, sources[index])
+	}
+	return effective, nil
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// GetReplicaPatchScheduler returns scheduler of replica patch period, which could be date or cron one
+func GetReplicaPatchScheduler(patch wingv1.ReplicaPatch) (timerange.Scheduler, error) {
+	scheduler, err := newPeriodScheduler(patch.Timezone, patch.Start, patch.End, patch.Duration, patch.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	return shiftPeriod(scheduler, patch.LeadTime, patch.ScaleDownDelay)
+}
+
+// ValidateReplicaPatch validates period, replicas range and ramp of replica patch
+func ValidateReplicaPatch(patch wingv1.ReplicaPatch) error {
+	if _, err := GetReplicaPatchScheduler(patch); err != nil {
+		return err
+	}
+	if patch.MinReplicas < 0 || patch.MinReplicas > patch.MaxReplicas {
+		return ErrInvalidReplicaPatchRange
+	}
+	return ValidateReplicaPatchRamp(patch.Ramp)
 }
@@ -0,0 +1,87 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEffectiveReplicaPatch(t *testing.T) {
+	patches := wingv1.ReplicaPatches{
+		{Timezone: "UTC", Start: "0 9 * * *", End: "0 19 * * *", MinReplicas: 4, MaxReplicas: 20},
+		{Timezone: "UTC", Start: "0 12 * * *", End: "0 14 * * *", MinReplicas: 10, MaxReplicas: 40, Priority: 10},
+		{Timezone: "UTC", Start: "0 13 * * *", End: "0 15 * * *", MinReplicas: 2, MaxReplicas: 8},
+	}
+	sources := []string{"annotation[0]", "annotation[1]", "ScalingWindow/promotion"}
+	at := func(hour int) time.Time {
+		return time.Date(2024, 8, 15, hour, 30, 0, 0, time.UTC)
+	}
+
+	for _, c := range []struct {
+		description string
+		mode        wingv1.ReplicaPatchMode
+		when        time.Time
+		expected    *wingv1.ReplicaPatchStatus
+	}{
+		{
+			description: "No patch works",
+			when:        at(20),
+		},
+		{
+			description: "Highest priority by default",
+			when:        at(12),
+			expected: &wingv1.ReplicaPatchStatus{
+				Mode: wingv1.ReplicaPatchHighestPriority, MinReplicas: 10, MaxReplicas: 40,
+				Sources: []string{"annotation[1]"},
+			},
+		},
+		{
+			description: "Patches of the same priority keep their order",
+			mode:        wingv1.ReplicaPatchHighestPriority,
+			when:        at(14),
+			expected: &wingv1.ReplicaPatchStatus{
+				Mode: wingv1.ReplicaPatchHighestPriority, MinReplicas: 4, MaxReplicas: 20,
+				Sources: []string{"annotation[0]"},
+			},
+		},
+		{
+			description: "Widest",
+			mode:        wingv1.ReplicaPatchWidest,
+			when:        at(13),
+			expected: &wingv1.ReplicaPatchStatus{
+				Mode: wingv1.ReplicaPatchWidest, MinReplicas: 2, MaxReplicas: 40,
+				Sources: []string{"annotation[1]", "annotation[0]", "ScalingWindow/promotion"},
+			},
+		},
+		{
+			description: "Narrowest",
+			mode:        wingv1.ReplicaPatchNarrowest,
+			when:        at(12),
+			expected: &wingv1.ReplicaPatchStatus{
+				Mode: wingv1.ReplicaPatchNarrowest, MinReplicas: 10, MaxReplicas: 20,
+				Sources: []string{"annotation[1]", "annotation[0]"},
+			},
+		},
+		{
+			description: "Narrowest without common range falls back to highest priority",
+			mode:        wingv1.ReplicaPatchNarrowest,
+			when:        at(13),
+			expected: &wingv1.ReplicaPatchStatus{
+				Mode: wingv1.ReplicaPatchNarrowest, MinReplicas: 10, MaxReplicas: 40,
+				Sources: []string{"annotation[1]"},
+			},
+		},
+	} {
+		effective, err := GetEffectiveReplicaPatch(c.when, patches, sources, c.mode)
+		require.NoError(t, err, c.description)
+		require.Equal(t, c.expected, effective, c.description)
+	}
+
+	_, err := GetEffectiveReplicaPatch(at(12), wingv1.ReplicaPatches{
+		{Timezone: "UTC", Start: "0 9 * * *", End: "0 9 * * *"},
+	}, []string{"annotation[0]"}, wingv1.ReplicaPatchWidest)
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/utils"
+)
+
+// GetAutoscalerReplicaPatches returns replica patches of annotation followed by patches of windows
+// selecting the autoscaler, so that the annotation keeps working for migration.
+// Sources are names of patches which are `annotation[<index>]` or `ScalingWindow/<name>`.
+func GetAutoscalerReplicaPatches(autoscaler wingv1.ReplicaAutoscaler,
+	windows []wingv1.ScalingWindow) (patches wingv1.ReplicaPatches, sources []string, err error) {
+	patches, err = utils.GetReplicaPatches(autoscaler)
+	if err != nil {
+		return nil, nil, err
+	}
+	for index := range patches {
+		sources = append(sources, fmt.Sprintf("annotation[%d]", index))
+	}
+	for _, window := range GetSelectingScalingWindows(autoscaler, windows) {
+		patches = append(patches, window.Spec.ReplicaPatch)
+		sources = append(sources, "ScalingWindow/"+window.Name)
+	}
+	return patches, sources, nil
+}
+
+// GetSelectingScalingWindows returns valid windows selecting the autoscaler in order of name.
+// Broken windows are skipped rather than failing patches of the autoscaler.
+func GetSelectingScalingWindows(autoscaler wingv1.ReplicaAutoscaler,
+	windows []wingv1.ScalingWindow) []wingv1.ScalingWindow {
+	selectedWindows := make([]wingv1.ScalingWindow, 0, len(windows))
+	for _, window := range windows {
+		if window.DeletionTimestamp != nil || ValidateReplicaPatch(window.Spec.ReplicaPatch) != nil {
+			continue
+		}
+		if selected, err := utils.IsScalingWindowSelecting(window, autoscaler); err == nil && selected {
+			selectedWindows = append(selectedWindows, window)
+		}
+	}
+	sort.Slice(selectedWindows, func(i, j int) bool {
+		return selectedWindows[i].Name < selectedWindows[j].Name
+	})
+	return selectedWindows
+}
@@ -3,6 +3,7 @@ package scheduling
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,88 +11,205 @@ import (
 	"github.com/xscaling/wing/utils/timerange"
 
 	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
 	ErrTimezoneNotFound          = errors.New("timezone not found")
-	ErrSchedulePeriodNotFound    = errors.New("schedule period not found, `start` or `end` field not exists")
+	ErrSchedulePeriodNotFound    = errors.New("schedule period not found, `start` or `end`(`duration`) field not exists")
 	ErrStartEndSpecCanNotBeEqual = errors.New("start and end spec can not be equal")
+	ErrEndDurationAreExclusive   = errors.New("`end` and `duration` are mutually exclusive")
+	ErrDurationRequiresCronStart = errors.New("`duration` requires cron `start`")
+	ErrCalendarNotResolved       = errors.New("calendar ConfigMap is not resolved")
+	ErrNegativeLeadTimeOrDelay   = errors.New("`leadTime` and `scaleDownDelay` must not be negative")
 )
 
-// GetScheduledSettingsRaw returns the raw settings of LAST hit schedule one
+// GetScheduledSettingsRaw returns default settings merged with hit schedule settings by merge mode,
+// which is the hit schedule of highest priority or all hit schedules in order of priority.
 func GetScheduledSettingsRaw(when time.Time, settings wingv1.TargetSettings) (payload []byte, err error) {
 	payload = make([]byte, len(settings.Default.Raw))
 	copy(payload, settings.Default.Raw)
 
-	var (
-		hitScheduleSettingsPayload []byte
-	)
-	for _, schedule := range settings.Schedules {
+	indexes, err := GetScheduleIndexes(when, settings.Schedules)
+	if err != nil {
+		return nil, err
+	}
+	if settings.MergeMode != wingv1.ScheduleMerge && len(indexes) > 0 {
+		indexes = indexes[:1]
+	}
+	// Merge from the lowest priority so that higher priority wins on conflict
+	for i := len(indexes) - 1; i >= 0; i-- {
+		hitScheduleSettingsPayload := make([]byte, len(settings.Schedules[indexes[i]].Settings.Raw))
+		copy(hitScheduleSettingsPayload, settings.Schedules[indexes[i]].Settings.Raw)
+		if payload, err = jsonpatch.MergePatch(payload, hitScheduleSettingsPayload); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+// GetScheduleIndex returns index of the hit schedule of highest priority at given time, -1 means no schedule hit
+func GetScheduleIndex(when time.Time, schedules []wingv1.ScheduleTargetSettings) (int, error) {
+	indexes, err := GetScheduleIndexes(when, schedules)
+	if err != nil || len(indexes) == 0 {
+		return -1, err
+	}
+	return indexes[0], nil
+}
+
+// GetScheduleIndexes returns indexes of schedules hit at given time in order of priority,
+// schedules of the same priority keep their order.
+func GetScheduleIndexes(when time.Time, schedules []wingv1.ScheduleTargetSettings) ([]int, error) {
+	var indexes []int
+	for index, schedule := range schedules {
 		scheduler, err := GetScheduler(schedule)
 		if err != nil {
 			return nil, err
 		}
-
 		if scheduler.Contains(when) {
-			hitScheduleSettingsPayload = make([]byte, len(schedule.Settings.Raw))
-			copy(hitScheduleSettingsPayload, schedule.Settings.Raw)
-			break
+			indexes = append(indexes, index)
 		}
 	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return schedules[indexes[i]].Priority > schedules[indexes[j]].Priority
+	})
+	return indexes, nil
+}
+
+// GetScheduler returns scheduler of schedule period, which could be date or cron one
+func GetScheduler(scheduleSettings wingv1.ScheduleTargetSettings) (timerange.Scheduler, error) {
+	scheduler, err := newPeriodScheduler(scheduleSettings.Timezone, scheduleSettings.Start, scheduleSettings.End,
+		scheduleSettings.Duration, scheduleSettings.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	return shiftPeriod(scheduler, scheduleSettings.LeadTime, scheduleSettings.ScaleDownDelay)
+}
 
-	if hitScheduleSettingsPayload != nil {
-		patchedPayload, err := jsonpatch.MergePatch(payload, hitScheduleSettingsPayload)
+// GetExpiredScheduleIndexes returns indexes of date schedules ended before given time,
+// broken schedules are left to GetScheduleIndex to report.
+func GetExpiredScheduleIndexes(when time.Time, schedules []wingv1.ScheduleTargetSettings) []int {
+	var indexes []int
+	for index, schedule := range schedules {
+		scheduler, err := GetScheduler(schedule)
 		if err != nil {
+			continue
+		}
+		if dateScheduler, ok := timerange.AsDateScheduler(scheduler); ok && when.After(dateScheduler.GetEndTime()) {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// newPeriodScheduler validates period and predicts its format by fields count of start,
+// date has 2 fields and cron has 5. The period is narrowed by calendar if any.
+func newPeriodScheduler(timezone, start, end string, duration *metav1.Duration,
+	calendar *wingv1.ScheduleCalendar) (timerange.Scheduler, error) {
+	if timezone == "" {
+		return nil, ErrTimezoneNotFound
+	}
+	locale, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	if calendar == nil {
+		return newTimeRangeScheduler(locale, start, end, duration)
+	}
+	c, err := newCalendar(locale, *calendar)
+	if err != nil {
+		return nil, err
+	}
+	var scheduler timerange.Scheduler
+	// Calendar works alone
+	if start != "" || end != "" || duration != nil {
+		if scheduler, err = newTimeRangeScheduler(locale, start, end, duration); err != nil {
 			return nil, err
 		}
-		payload = patchedPayload
 	}
-	return payload, nil
+	return timerange.NewCalendarScheduler(locale, scheduler, c, calendar.Mode == wingv1.CalendarExclude), nil
 }
 
-func GetScheduler(scheduleSettings wingv1.ScheduleTargetSettings) (timerange.Scheduler, error) {
-	start, end, tz, err := getSchedulePeriod(scheduleSettings)
+// shiftPeriod starts period lead time earlier and ends it delay later if any
+func shiftPeriod(scheduler timerange.Scheduler, leadTime, delay *metav1.Duration) (timerange.Scheduler, error) {
+	var leadDuration, delayDuration time.Duration
+	if leadTime != nil {
+		leadDuration = leadTime.Duration
+	}
+	if delay != nil {
+		delayDuration = delay.Duration
+	}
+	switch {
+	case leadDuration < 0 || delayDuration < 0:
+		return nil, ErrNegativeLeadTimeOrDelay
+	case leadDuration == 0 && delayDuration == 0:
+		return scheduler, nil
+	}
+	return timerange.NewShiftedScheduler(scheduler, leadDuration, delayDuration), nil
+}
+
+// newCalendar merges dates and iCalendar of resolved calendar
+func newCalendar(timezone *time.Location, calendar wingv1.ScheduleCalendar) (*timerange.Calendar, error) {
+	if calendar.ConfigMapKeyRef != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCalendarNotResolved, calendar.ConfigMapKeyRef.Name)
+	}
+	c, err := timerange.ParseCalendarDates(timezone, calendar.Dates)
 	if err != nil {
 		return nil, err
 	}
+	if calendar.ICalendar == "" {
+		return c, nil
+	}
+	events, err := timerange.ParseICalendar(timezone, calendar.ICalendar)
+	if err != nil {
+		return nil, err
+	}
+	return c.Merge(events), nil
+}
+
+func newTimeRangeScheduler(locale *time.Location, start, end string,
+	duration *metav1.Duration) (timerange.Scheduler, error) {
+	switch {
+	case start == "" || (end == "" && duration == nil):
+		return nil, ErrSchedulePeriodNotFound
+	case end != "" && duration != nil:
+		return nil, ErrEndDurationAreExclusive
+	case start == end:
+		return nil, ErrStartEndSpecCanNotBeEqual
+	}
+
 	var (
 		scheduler timerange.Scheduler
+		err       error
 	)
 	// Easy-Predict
 	switch len(strings.Split(start, timerange.CronFieldSeparator)) {
+	case 2:
+		if duration != nil {
+			return nil, ErrDurationRequiresCronStart
+		}
+		scheduler, err = timerange.NewDateScheduler(locale, start, end)
 	case 5:
-		scheduler, err = timerange.NewCronScheduler(tz, start, end)
+		if duration != nil {
+			scheduler, err = timerange.NewCronDurationScheduler(locale, start, duration.Duration)
+		} else {
+			scheduler, err = timerange.NewCronScheduler(locale, start, end)
+		}
 	default:
 		return nil, timerange.ErrInvalidSchedulePeriodFormat
 	}
-	return scheduler, err
-}
-
-func getSchedulePeriod(scheduleSettings wingv1.ScheduleTargetSettings) (start, end string, locale *time.Location, err error) {
-	if scheduleSettings.Timezone == "" {
-		return "", "", nil, ErrTimezoneNotFound
-	}
-	locale, err = time.LoadLocation(scheduleSettings.Timezone)
 	if err != nil {
-		return "", "", nil, err
-	}
-	start, end = scheduleSettings.Start, scheduleSettings.End
-	if start == "" || end == "" {
-		return "", "", nil, ErrSchedulePeriodNotFound
-	}
-	if start == end {
-		return "", "", nil, ErrStartEndSpecCanNotBeEqual
+		return nil, err
 	}
-	return
+	return scheduler, nil
 }
 
 // nolint
 // Unused currently, reserve for validation webhook
 func ValidateScheduleSettings(scheduleSettings []wingv1.ScheduleTargetSettings) error {
 	for index, settings := range scheduleSettings {
-		_, _, _, err := getSchedulePeriod(settings)
-		if err != nil {
-			return fmt.Errorf("%w: broken schedule settings(%d)", ErrSchedulePeriodNotFound, index)
+		if _, err := GetScheduler(settings); err != nil {
+			return fmt.Errorf("%w: broken schedule settings(%d): %v", ErrSchedulePeriodNotFound, index, err)
 		}
 	}
 	return nil
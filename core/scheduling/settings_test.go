@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -151,6 +153,24 @@ func TestIsSchedulePeriodContains(t *testing.T) {
 			end:      "0 23 * * 3",
 			contains: false,
 		},
+		{
+			date:     "2021-12-04 10:00",
+			start:    "2021-12-04 00:00",
+			end:      "2021-12-05 23:59",
+			contains: true,
+		},
+		{
+			date:     "2021-12-06 00:00",
+			start:    "2021-12-04 00:00",
+			end:      "2021-12-05 23:59",
+			contains: false,
+		},
+		{
+			date:       "2021-12-06 00:00",
+			start:      "2021-12-05 23:59",
+			end:        "2021-12-04 00:00",
+			causeError: timerange.ErrStartDateMustBeBeforeEndDate,
+		},
 		{
 			date:       "2021-12-02 18:59",
 			start:      "0 0 * * 3",
@@ -246,6 +266,149 @@ func TestIsSchedulePeriodContains(t *testing.T) {
 	}
 }
 
+func TestGetSchedulerWithDuration(t *testing.T) {
+	duration := &metav1.Duration{Duration: 15 * time.Minute}
+	scheduler, err := GetScheduler(wingv1.ScheduleTargetSettings{
+		Timezone: "UTC", Start: "0 */2 * * *", Duration: duration,
+	})
+	require.NoError(t, err)
+	require.True(t, scheduler.Contains(time.Date(2024, 8, 15, 10, 14, 0, 0, time.UTC)))
+	require.False(t, scheduler.Contains(time.Date(2024, 8, 15, 10, 15, 0, 0, time.UTC)))
+	require.False(t, scheduler.Contains(time.Date(2024, 8, 15, 11, 0, 0, 0, time.UTC)))
+
+	for _, testCase := range []struct {
+		settings   wingv1.ScheduleTargetSettings
+		causeError error
+	}{
+		{
+			settings:   wingv1.ScheduleTargetSettings{Timezone: "UTC", Start: "0 */2 * * *", End: "0 9 * * *", Duration: duration},
+			causeError: ErrEndDurationAreExclusive,
+		},
+		{
+			settings:   wingv1.ScheduleTargetSettings{Timezone: "UTC", Start: "2024-08-15 08:00", Duration: duration},
+			causeError: ErrDurationRequiresCronStart,
+		},
+		{
+			settings:   wingv1.ScheduleTargetSettings{Timezone: "UTC", Start: "0 */2 * * *"},
+			causeError: ErrSchedulePeriodNotFound,
+		},
+		{
+			settings:   wingv1.ScheduleTargetSettings{Timezone: "UTC", Start: "0 */2 * * *", End: "0 9 * * *"},
+			causeError: timerange.ErrCronScheduleSupportsExactMinuteHourValueOnly,
+		},
+	} {
+		_, err = GetScheduler(testCase.settings)
+		require.ErrorIs(t, err, testCase.causeError, "%+v", testCase.settings)
+	}
+}
+
+func TestGetScheduledSettingsRawWithCalendar(t *testing.T) {
+	settings := wingv1.TargetSettings{
+		Default: &runtime.RawExtension{Raw: []byte(`{"threshold":1000}`)},
+		Schedules: []wingv1.ScheduleTargetSettings{
+			{
+				// Weekday worktime except holidays
+				Timezone: "UTC", Start: "0 9 * * 1-5", End: "0 19 * * 1-5",
+				Calendar: &wingv1.ScheduleCalendar{Mode: wingv1.CalendarExclude, Dates: []string{"2024-08-15"}},
+				Settings: &runtime.RawExtension{Raw: []byte(`{"threshold":800}`)},
+			},
+			{
+				// Whole days of sales events
+				Timezone: "UTC",
+				Calendar: &wingv1.ScheduleCalendar{
+					ICalendar: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240815\nDTEND;VALUE=DATE:20240817\nEND:VEVENT",
+				},
+				Settings: &runtime.RawExtension{Raw: []byte(`{"threshold":600}`)},
+			},
+		},
+	}
+	for when, expected := range map[time.Time]string{
+		time.Date(2024, 8, 14, 10, 0, 0, 0, time.UTC): `{"threshold":800}`,
+		time.Date(2024, 8, 14, 20, 0, 0, 0, time.UTC): `{"threshold":1000}`,
+		time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC): `{"threshold":600}`,
+		time.Date(2024, 8, 16, 10, 0, 0, 0, time.UTC): `{"threshold":800}`,
+		time.Date(2024, 8, 16, 20, 0, 0, 0, time.UTC): `{"threshold":600}`,
+	} {
+		payload, err := GetScheduledSettingsRaw(when, settings)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(payload), "when: %s", when)
+	}
+
+	// ConfigMap must be resolved before scheduling
+	settings.Schedules[1].Calendar.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "holidays"}, Key: "dates",
+	}
+	_, err := GetScheduledSettingsRaw(time.Date(2024, 8, 14, 20, 0, 0, 0, time.UTC), settings)
+	require.ErrorIs(t, err, ErrCalendarNotResolved)
+}
+
+func TestGetScheduledSettingsRawWithPriority(t *testing.T) {
+	settings := wingv1.TargetSettings{
+		Default: &runtime.RawExtension{Raw: []byte(`{"threshold":1000,"tolerance":0.1}`)},
+		Schedules: []wingv1.ScheduleTargetSettings{
+			{
+				// Daily worktime
+				Timezone: "UTC", Start: "0 9 * * *", End: "0 19 * * *",
+				Settings: &runtime.RawExtension{Raw: []byte(`{"threshold":800,"tolerance":0.2}`)},
+			},
+			{
+				// Sales event overrides worktime
+				Timezone: "UTC", Start: "2024-08-15 00:00", End: "2024-08-15 23:59", Priority: 10,
+				Settings: &runtime.RawExtension{Raw: []byte(`{"threshold":600}`)},
+			},
+		},
+	}
+	require.Equal(t, []int{1, 0}, mustGetScheduleIndexes(t, time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC), settings.Schedules))
+
+	for _, c := range []struct {
+		mergeMode wingv1.ScheduleMergeMode
+		expected  map[time.Time]string
+	}{
+		{
+			mergeMode: wingv1.ScheduleHighestPriority,
+			expected: map[time.Time]string{
+				time.Date(2024, 8, 14, 10, 0, 0, 0, time.UTC): `{"threshold":800,"tolerance":0.2}`,
+				time.Date(2024, 8, 15, 8, 0, 0, 0, time.UTC):  `{"threshold":600,"tolerance":0.1}`,
+				time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC): `{"threshold":600,"tolerance":0.1}`,
+			},
+		},
+		{
+			mergeMode: wingv1.ScheduleMerge,
+			expected: map[time.Time]string{
+				time.Date(2024, 8, 14, 10, 0, 0, 0, time.UTC): `{"threshold":800,"tolerance":0.2}`,
+				time.Date(2024, 8, 15, 8, 0, 0, 0, time.UTC):  `{"threshold":600,"tolerance":0.1}`,
+				time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC): `{"threshold":600,"tolerance":0.2}`,
+			},
+		},
+	} {
+		settings.MergeMode = c.mergeMode
+		for when, expected := range c.expected {
+			payload, err := GetScheduledSettingsRaw(when, settings)
+			require.NoError(t, err)
+			require.JSONEq(t, expected, string(payload), "[%s] when: %s", c.mergeMode, when)
+		}
+	}
+}
+
+func mustGetScheduleIndexes(t *testing.T, when time.Time, schedules []wingv1.ScheduleTargetSettings) []int {
+	indexes, err := GetScheduleIndexes(when, schedules)
+	require.NoError(t, err)
+	return indexes
+}
+
+func TestGetExpiredScheduleIndexes(t *testing.T) {
+	schedules := []wingv1.ScheduleTargetSettings{
+		{Timezone: "UTC", Start: "0 8 * * *", End: "0 10 * * *"},
+		{Timezone: "UTC", Start: "2024-08-10 00:00", End: "2024-08-11 23:59"},
+		{Timezone: "UTC", Start: "2024-08-17 00:00", End: "2024-08-18 23:59"},
+		{Timezone: "UTC", Start: "2024-08-11 00:00", End: "broken"},
+	}
+	when := time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC)
+	require.Equal(t, []int{1}, GetExpiredScheduleIndexes(when, schedules))
+	require.Empty(t, GetExpiredScheduleIndexes(when.AddDate(0, 0, -5), schedules))
+	require.Equal(t, []int{1, 2}, GetExpiredScheduleIndexes(when.AddDate(0, 0, 5), schedules))
+}
+
 func BenchmarkIsSchedulePeriodContainsWithCron(b *testing.B) {
 	const fixedTZ = "Asia/Shanghai"
 	when := time.Now()
@@ -0,0 +1,34 @@
+package scheduling
+
+import (
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/utils/timerange"
+)
+
+// GetNextTransition returns the nearest boundary after given time at which hit schedules of targets or
+// working replica patches may change, zero time means no upcoming boundary is known.
+// Broken periods are ignored.
+func GetNextTransition(when time.Time, targets []wingv1.ReplicaAutoscalerTarget,
+	patches wingv1.ReplicaPatches) time.Time {
+	var next time.Time
+	nearest := func(scheduler timerange.Scheduler, err error) {
+		if err != nil {
+			return
+		}
+		if transition := scheduler.GetNextTransition(when); !transition.IsZero() &&
+			(next.IsZero() || transition.Before(next)) {
+			next = transition
+		}
+	}
+	for _, target := range targets {
+		for _, schedule := range target.Settings.Schedules {
+			nearest(GetScheduler(schedule))
+		}
+	}
+	for _, patch := range patches {
+		nearest(GetReplicaPatchScheduler(patch))
+	}
+	return next
+}
@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNextTransition(t *testing.T) {
+	targets := []wingv1.ReplicaAutoscalerTarget{{
+		Metric: "cpu",
+		Settings: wingv1.TargetSettings{
+			Schedules: []wingv1.ScheduleTargetSettings{
+				{Timezone: "UTC", Start: "2024-08-15 10:00", End: "2024-08-15 12:00"},
+				{Timezone: "UTC", Start: "0 9 * * *", End: "0 19 * * *"},
+				{Timezone: "UTC", Start: "broken", End: "2024-08-15 12:00"},
+			},
+		},
+	}}
+	patches := wingv1.ReplicaPatches{{
+		Timezone: "UTC", Start: "2024-08-15 11:00", End: "2024-08-15 11:30",
+		LeadTime: &metav1.Duration{Duration: 5 * time.Minute}, ScaleDownDelay: &metav1.Duration{Duration: time.Minute},
+	}}
+	for _, c := range []struct {
+		when     time.Time
+		expected time.Time
+	}{
+		{
+			when:     time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			when:     time.Date(2024, 8, 15, 10, 30, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 15, 10, 55, 0, 0, time.UTC),
+		},
+		{
+			when:     time.Date(2024, 8, 15, 11, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 15, 11, 31, 0, 0, time.UTC),
+		},
+		{
+			when:     time.Date(2024, 8, 15, 11, 40, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			when:     time.Date(2024, 8, 15, 13, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 15, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			when:     time.Date(2024, 8, 15, 20, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 8, 16, 9, 0, 0, 0, time.UTC),
+		},
+	} {
+		require.Equal(t, c.expected, GetNextTransition(c.when, targets, patches), "when: %s", c.when)
+	}
+
+	_, err := GetScheduler(wingv1.ScheduleTargetSettings{
+		Timezone: "UTC", Start: "0 9 * * *", End: "0 19 * * *", LeadTime: &metav1.Duration{Duration: -time.Minute},
+	})
+	require.ErrorIs(t, err, ErrNegativeLeadTimeOrDelay)
+}
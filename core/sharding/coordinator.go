@@ -0,0 +1,226 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// GroupLabel labels leases of members in the same sharding group
+	GroupLabel = "wing.xscaling.dev/shard-group"
+)
+
+type Options struct {
+	// Identity is the unique identity of this member, e.g. pod name
+	Identity string
+	// Namespace is where leases of members are
+	Namespace string
+	// Group is the name of sharding group, which is also the prefix of leases
+	Group string
+	// LeaseDuration is how long a member is regarded as alive since its last renewal
+	LeaseDuration time.Duration
+	// RenewInterval is the interval of renewing lease and refreshing members
+	RenewInterval time.Duration
+	// HandoverDelay is the delay before working on newly acquired autoscalers,
+	// which makes sure previous owners have observed the change and handed over.
+	HandoverDelay time.Duration
+}
+
+// Listener is notified when members change
+type Listener interface {
+	OnRebalance(ring *Ring)
+}
+
+// Coordinator maintains lease of this member and members of the sharding group,
+// autoscalers are assigned to alive members by Ring.
+type Coordinator struct {
+	options Options
+	leases  coordinationv1client.LeasesGetter
+	logger  logr.Logger
+
+	mu            sync.RWMutex
+	ring          *Ring
+	previousRing  *Ring
+	ringChangedAt time.Time
+	listeners     []Listener
+}
+
+var (
+	_ manager.Runnable               = &Coordinator{}
+	_ manager.LeaderElectionRunnable = &Coordinator{}
+)
+
+func NewCoordinator(options Options, leases coordinationv1client.LeasesGetter) *Coordinator {
+	return &Coordinator{
+		options: options,
+		leases:  leases,
+		logger:  log.Log.WithName("sharding").WithValues("identity", options.Identity),
+	}
+}
+
+func (c *Coordinator) AddListener(listener Listener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+func (c *Coordinator) Identity() string {
+	return c.options.Identity
+}
+
+// Ring returns current ring, nil means members are not known yet
+func (c *Coordinator) Ring() *Ring {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring
+}
+
+// Owns returns whether this member owns the autoscaler.
+// A newly acquired autoscaler is owned but should wait before working until handover is done.
+func (c *Coordinator) Owns(key types.NamespacedName) (owned bool, wait time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.ring == nil {
+		// Members are not known yet
+		return true, c.options.RenewInterval
+	}
+	if c.ring.Owner(key) != c.options.Identity {
+		return false, 0
+	}
+	if c.previousRing.Owner(key) != c.options.Identity {
+		if untilHandover := c.options.HandoverDelay - time.Since(c.ringChangedAt); untilHandover > 0 {
+			return true, untilHandover
+		}
+	}
+	return true, 0
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, all members work at the same time.
+func (c *Coordinator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements Runnable, it blocks until context done and releases lease of this member.
+func (c *Coordinator) Start(ctx context.Context) error {
+	c.logger.Info("Starting sharding coordinator", "group", c.options.Group, "namespace", c.options.Namespace)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.renew(ctx); err != nil {
+			c.logger.Error(err, "Failed to renew lease")
+			return
+		}
+		if err := c.refresh(ctx); err != nil {
+			c.logger.Error(err, "Failed to refresh members")
+		}
+	}, c.options.RenewInterval)
+
+	// Hand over all autoscalers to the rest members before leaving
+	var restMembers []string
+	for _, member := range c.Ring().Members() {
+		if member != c.options.Identity {
+			restMembers = append(restMembers, member)
+		}
+	}
+	c.rebalance(NewRing(restMembers))
+	releaseCtx, cancel := context.WithTimeout(context.Background(), c.options.RenewInterval)
+	defer cancel()
+	err := c.leases.Leases(c.options.Namespace).Delete(releaseCtx, c.leaseName(), metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		c.logger.Error(err, "Failed to release lease")
+	}
+	return nil
+}
+
+func (c *Coordinator) leaseName() string {
+	return fmt.Sprintf("%s-%s", c.options.Group, c.options.Identity)
+}
+
+func (c *Coordinator) renew(ctx context.Context) error {
+	leases := c.leases.Leases(c.options.Namespace)
+	now := metav1.NewMicroTime(time.Now())
+	lease, err := leases.Get(ctx, c.leaseName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.leaseName(),
+				Namespace: c.options.Namespace,
+				Labels:    map[string]string{GroupLabel: c.options.Group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.String(c.options.Identity),
+				LeaseDurationSeconds: pointer.Int32(int32(c.options.LeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	lease.Spec.HolderIdentity = pointer.String(c.options.Identity)
+	lease.Spec.LeaseDurationSeconds = pointer.Int32(int32(c.options.LeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &now
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *Coordinator) refresh(ctx context.Context) error {
+	leaseList, err := c.leases.Leases(c.options.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{GroupLabel: c.options.Group}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	var members []string
+	for _, lease := range leaseList.Items {
+		if isLeaseAlive(lease, time.Now()) {
+			members = append(members, *lease.Spec.HolderIdentity)
+		}
+	}
+	c.rebalance(NewRing(members))
+	return nil
+}
+
+func isLeaseAlive(lease coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	expireAt := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.Before(expireAt)
+}
+
+func (c *Coordinator) rebalance(ring *Ring) {
+	c.mu.Lock()
+	if c.ring != nil && c.ring.Equal(ring) {
+		c.mu.Unlock()
+		return
+	}
+	c.logger.Info("Sharding members changed", "previous", c.ring.Members(), "current", ring.Members())
+	if c.ring == nil {
+		// Nothing to hand over on starting, but previous owners may still be working
+		c.previousRing = NewRing(nil)
+	} else {
+		c.previousRing = c.ring
+	}
+	c.ring = ring
+	c.ringChangedAt = time.Now()
+	listeners := append([]Listener(nil), c.listeners...)
+	c.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnRebalance(ring)
+	}
+}
@@ -0,0 +1,169 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+const testNamespace = "wing-system"
+
+// recordingListener records rings of rebalances
+type recordingListener struct {
+	rings chan *Ring
+}
+
+func (l *recordingListener) OnRebalance(ring *Ring) {
+	l.rings <- ring
+}
+
+func newRecordingListener() *recordingListener {
+	return &recordingListener{rings: make(chan *Ring, 16)}
+}
+
+func newTestCoordinator(identity string, clientset *fake.Clientset) *Coordinator {
+	return NewCoordinator(Options{
+		Identity:      identity,
+		Namespace:     testNamespace,
+		Group:         "wing",
+		LeaseDuration: time.Minute,
+		RenewInterval: 10 * time.Millisecond,
+		HandoverDelay: time.Hour,
+	}, clientset.CoordinationV1())
+}
+
+func newLease(name, group, holder string, renewTime time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    map[string]string{GroupLabel: group},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       pointer.String(holder),
+			LeaseDurationSeconds: pointer.Int32(60),
+			RenewTime:            &metav1.MicroTime{Time: renewTime},
+		},
+	}
+}
+
+func TestCoordinatorMembership(t *testing.T) {
+	ctx := context.TODO()
+	clientset := fake.NewSimpleClientset(
+		// Member of other group
+		newLease("other-c", "other", "c", time.Now()),
+		// Expired member
+		newLease("wing-d", "wing", "d", time.Now().Add(-2*time.Minute)),
+	)
+	a, b := newTestCoordinator("a", clientset), newTestCoordinator("b", clientset)
+	listener := newRecordingListener()
+	a.AddListener(listener)
+
+	require.NoError(t, a.renew(ctx))
+	require.NoError(t, b.renew(ctx))
+	// Renew existing lease
+	require.NoError(t, b.renew(ctx))
+	lease, err := clientset.CoordinationV1().Leases(testNamespace).Get(ctx, "wing-a", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "a", *lease.Spec.HolderIdentity)
+	require.Equal(t, "wing", lease.Labels[GroupLabel])
+	require.Equal(t, int32(60), *lease.Spec.LeaseDurationSeconds)
+
+	require.Nil(t, a.Ring())
+	require.NoError(t, a.refresh(ctx))
+	require.Equal(t, []string{"a", "b"}, a.Ring().Members())
+	require.Equal(t, []string{"a", "b"}, (<-listener.rings).Members())
+
+	// Listeners are not notified without members changed
+	require.NoError(t, a.refresh(ctx))
+	require.Len(t, listener.rings, 0)
+
+	require.NoError(t, clientset.CoordinationV1().Leases(testNamespace).Delete(ctx, "wing-b", metav1.DeleteOptions{}))
+	require.NoError(t, a.refresh(ctx))
+	require.Equal(t, []string{"a"}, (<-listener.rings).Members())
+}
+
+func TestCoordinatorOwns(t *testing.T) {
+	a := newTestCoordinator("a", fake.NewSimpleClientset())
+	key := types.NamespacedName{Namespace: "default", Name: "foo"}
+
+	// Members are not known yet
+	owned, wait := a.Owns(key)
+	require.True(t, owned)
+	require.Equal(t, a.options.RenewInterval, wait)
+
+	// Previous owners may still be working on starting
+	a.rebalance(NewRing([]string{"a"}))
+	owned, wait = a.Owns(key)
+	require.True(t, owned)
+	require.InDelta(t, time.Hour, wait, float64(time.Minute))
+
+	a.ringChangedAt = time.Now().Add(-time.Hour)
+	owned, wait = a.Owns(key)
+	require.True(t, owned)
+	require.Zero(t, wait)
+
+	// Keys kept by a are worked on without waiting, keys moved to b are not owned any more
+	a.rebalance(NewRing([]string{"a", "b"}))
+	var kept, moved []types.NamespacedName
+	for i := 0; i < 100; i++ {
+		key := types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("autoscaler-%d", i)}
+		owned, wait := a.Owns(key)
+		if a.Ring().Owner(key) == "a" {
+			require.True(t, owned)
+			require.Zero(t, wait)
+			kept = append(kept, key)
+		} else {
+			require.False(t, owned)
+			require.Zero(t, wait)
+			moved = append(moved, key)
+		}
+	}
+	require.NotEmpty(t, kept)
+	require.NotEmpty(t, moved)
+
+	// Keys moved back wait for handover of b
+	a.rebalance(NewRing([]string{"a"}))
+	for _, key := range moved {
+		owned, wait := a.Owns(key)
+		require.True(t, owned)
+		require.Greater(t, wait, time.Duration(0))
+	}
+	for _, key := range kept {
+		owned, wait := a.Owns(key)
+		require.True(t, owned)
+		require.Zero(t, wait)
+	}
+}
+
+func TestCoordinatorStartAndLeave(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newLease("wing-b", "wing", "b", time.Now()))
+	a := newTestCoordinator("a", clientset)
+	listener := newRecordingListener()
+	a.AddListener(listener)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	stopped := make(chan error)
+	go func() {
+		stopped <- a.Start(ctx)
+	}()
+	require.Equal(t, []string{"a", "b"}, (<-listener.rings).Members())
+	cancel()
+	require.NoError(t, <-stopped)
+
+	// Autoscalers are handed over to the rest members, so that handover is not stale on them
+	ring := <-listener.rings
+	require.Equal(t, []string{"b"}, ring.Members())
+	require.Equal(t, NewRing([]string{"b"}).Version(), ring.Version())
+	_, err := clientset.CoordinationV1().Leases(testNamespace).Get(context.TODO(), "wing-a", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+}
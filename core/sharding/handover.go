@@ -0,0 +1,41 @@
+package sharding
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Handover carries in-memory states of an autoscaler from previous owner to the next one
+type Handover struct {
+	// Owner is identity of member the autoscaler is handed over to
+	Owner string `json:"owner"`
+	// RingVersion is version of ring the handover happened on
+	RingVersion string `json:"ringVersion"`
+	// States are plugin states keyed by `endpoint/plugin`
+	States map[string][]byte `json:"states"`
+}
+
+// NewHandover returns handover of the autoscaler to its owner of the ring
+func NewHandover(key types.NamespacedName, ring *Ring, states map[string][]byte) Handover {
+	return Handover{
+		Owner:       ring.Owner(key),
+		RingVersion: ring.Version(),
+		States:      states,
+	}
+}
+
+// ParseHandover parses handover marshaled in annotation
+func ParseHandover(raw string) (*Handover, error) {
+	handover := &Handover{}
+	if err := json.Unmarshal([]byte(raw), handover); err != nil {
+		return nil, err
+	}
+	return handover, nil
+}
+
+// IsStale returns true if the handover is not made to the member on the ring,
+// e.g. it's left by an ownership long ago or ownership has moved again since then.
+func (h *Handover) IsStale(identity string, ring *Ring) bool {
+	return h.Owner != identity || h.RingVersion != ring.Version()
+}
@@ -0,0 +1,33 @@
+package sharding
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestHandover(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "foo"}
+	ring := NewRing([]string{"a", "b"})
+	states := map[string][]byte{"replicator/simple": []byte(`{"snapshots":[]}`)}
+
+	raw, err := json.Marshal(NewHandover(key, ring, states))
+	require.NoError(t, err)
+	handover, err := ParseHandover(string(raw))
+	require.NoError(t, err)
+	require.Equal(t, states, handover.States)
+	owner := ring.Owner(key)
+	require.Equal(t, owner, handover.Owner)
+	require.False(t, handover.IsStale(owner, NewRing([]string{"b", "a"})))
+
+	// Handed over to other member
+	require.True(t, handover.IsStale(owner+"-other", ring))
+	// Ownership moved again since handover
+	require.True(t, handover.IsStale(owner, NewRing([]string{"a", "b", "c"})))
+	require.True(t, handover.IsStale(owner, nil))
+
+	_, err = ParseHandover(`not json`)
+	require.Error(t, err)
+}
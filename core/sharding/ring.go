@@ -0,0 +1,67 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Ring splits the hash space of autoscaler keys into even ranges,
+// each member owns one range in order of member identity.
+type Ring struct {
+	members []string
+}
+
+func NewRing(members []string) *Ring {
+	sorted := append([]string(nil), members...)
+	sort.Strings(sorted)
+	return &Ring{members: sorted}
+}
+
+func (r *Ring) Members() []string {
+	if r == nil {
+		return nil
+	}
+	return append([]string(nil), r.members...)
+}
+
+// Owner returns identity of member owning the key, empty means no member available
+func (r *Ring) Owner(key types.NamespacedName) string {
+	if r == nil || len(r.members) == 0 {
+		return ""
+	}
+	index := uint64(HashKey(key)) * uint64(len(r.members)) >> 32
+	return r.members[index]
+}
+
+// Equal returns true if both rings have the same members
+func (r *Ring) Equal(other *Ring) bool {
+	members, otherMembers := r.Members(), other.Members()
+	if len(members) != len(otherMembers) {
+		return false
+	}
+	for i := range members {
+		if members[i] != otherMembers[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Version returns fingerprint of members, rings with the same members have the same version
+func (r *Ring) Version() string {
+	hasher := fnv.New64a()
+	for _, member := range r.Members() {
+		hasher.Write([]byte(member))
+		hasher.Write([]byte{0})
+	}
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
+
+func HashKey(key types.NamespacedName) uint32 {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(key.String()))
+	return hasher.Sum32()
+}
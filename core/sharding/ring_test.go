@@ -0,0 +1,40 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRing(t *testing.T) {
+	var nilRing *Ring
+	require.Equal(t, "", nilRing.Owner(types.NamespacedName{Name: "foo"}))
+	require.Equal(t, "", NewRing(nil).Owner(types.NamespacedName{Name: "foo"}))
+
+	ring := NewRing([]string{"c", "a", "b"})
+	require.Equal(t, []string{"a", "b", "c"}, ring.Members())
+	require.True(t, ring.Equal(NewRing([]string{"a", "b", "c"})))
+	require.False(t, ring.Equal(NewRing([]string{"a", "b"})))
+	require.False(t, ring.Equal(nilRing))
+	require.Equal(t, NewRing([]string{"b", "c", "a"}).Version(), ring.Version())
+	require.NotEqual(t, NewRing([]string{"ab", "c"}).Version(), NewRing([]string{"a", "bc"}).Version())
+	require.Equal(t, NewRing(nil).Version(), nilRing.Version())
+
+	owned := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("autoscaler-%d", i)}
+		owner := ring.Owner(key)
+		// Ownership is stable
+		require.Equal(t, owner, NewRing([]string{"b", "c", "a"}).Owner(key))
+		owned[owner]++
+	}
+	require.Len(t, owned, 3)
+	for member, count := range owned {
+		require.InDelta(t, 1000, count, 150, "member %s owns %d autoscalers", member, count)
+	}
+
+	// Single member owns all
+	require.Equal(t, "a", NewRing([]string{"a"}).Owner(types.NamespacedName{Name: "foo"}))
+}
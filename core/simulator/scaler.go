@@ -0,0 +1,80 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/scalerprovider/podresource"
+	"github.com/xscaling/wing/utils/scalerprovider/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sampleCursor holds the sample being replayed for replay scalers
+type sampleCursor struct {
+	current Sample
+}
+
+func (c *sampleCursor) value(metric string) (float64, error) {
+	value, ok := c.current.Values[metric]
+	if !ok {
+		return 0, fmt.Errorf("no recorded value of `%s` at %s", metric, c.current.Timestamp.Format(time.RFC3339))
+	}
+	return value, nil
+}
+
+// replayQueryClient implements prometheus.QueryClient by returning recorded value of metric
+type replayQueryClient struct {
+	cursor *sampleCursor
+	metric string
+}
+
+var _ prometheus.QueryClient = &replayQueryClient{}
+
+func (c *replayQueryClient) Query(_ context.Context, _ prometheus.Server, _ string, _ time.Time) (float64, error) {
+	return c.cursor.value(c.metric)
+}
+
+// utilizationScaler replays recorded average utilization(percentage) through pod resource scaler math
+type utilizationScaler struct {
+	cursor     *sampleCursor
+	pluginName string
+	resource   corev1.ResourceName
+	config     podresource.Config
+}
+
+var _ engine.Scaler = &utilizationScaler{}
+
+func (s *utilizationScaler) Get(ctx engine.ScalerContext) (*engine.ScalerOutput, error) {
+	settings := new(podresource.Settings)
+	if err := ctx.LoadSettings(settings); err != nil {
+		return nil, err
+	}
+	value, err := s.cursor.value(s.pluginName)
+	if err != nil {
+		return nil, err
+	}
+	averageUtilization := int32(value)
+	desiredReplicas, err := podresource.CalculateDesiredReplicasByUtilization(s.config.UtilizationToleration,
+		s.resource, ctx.CurrentReplicas, averageUtilization, int32(settings.Utilization))
+	if err != nil {
+		return nil, err
+	}
+	utils.SetTargetStatus(ctx.AutoscalerStatus, wingv1.TargetStatus{
+		Target:          s.pluginName,
+		Scaler:          s.pluginName,
+		DesiredReplicas: desiredReplicas,
+		Metric: wingv1.MetricTarget{
+			Type:               wingv1.UtilizationMetricType,
+			AverageUtilization: &averageUtilization,
+		},
+	})
+	return &engine.ScalerOutput{
+		DesiredReplicas:     desiredReplicas,
+		ManagedTargetStatus: []string{s.pluginName},
+	}, nil
+}
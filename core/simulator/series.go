@@ -0,0 +1,191 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+type Format string
+
+const (
+	// FormatAuto detects format by content, `{` leads Prometheus export, `[` leads JSON, otherwise CSV
+	FormatAuto Format = ""
+	// FormatCSV has a header of `timestamp,<metric>[,<metric>...]`
+	FormatCSV Format = "csv"
+	// FormatJSON is an array of samples, e.g. `[{"timestamp": "2024-01-01T00:00:00Z", "values": {"cpu": 80}}]`
+	FormatJSON Format = "json"
+	// FormatPrometheus is the response of Prometheus range query `/api/v1/query_range` with single series
+	FormatPrometheus Format = "prometheus"
+)
+
+var (
+	ErrEmptySeries            = errors.New("no sample found in series")
+	ErrMultiplePrometheusData = errors.New("prometheus export returns multiple series")
+)
+
+// Sample is the recorded metric values at the moment, values are keyed by target metric(scaler name).
+type Sample struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// ParseSeries parses recorded samples sorted by timestamp. Metric is the target metric
+// which values of Prometheus export belong to.
+func ParseSeries(data []byte, format Format, metric string) ([]Sample, error) {
+	if format == FormatAuto {
+		format = detectFormat(data)
+	}
+	var (
+		samples []Sample
+		err     error
+	)
+	switch format {
+	case FormatCSV:
+		samples, err = parseCSVSeries(data)
+	case FormatJSON:
+		err = json.Unmarshal(data, &samples)
+	case FormatPrometheus:
+		samples, err = parsePrometheusSeries(data, metric)
+	default:
+		return nil, fmt.Errorf("unknown series format `%s`", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, ErrEmptySeries
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	return samples, nil
+}
+
+func detectFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return FormatCSV
+	}
+	switch trimmed[0] {
+	case '{':
+		return FormatPrometheus
+	case '[':
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+func parseCSVSeries(data []byte) ([]Sample, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrEmptySeries
+		}
+		return nil, err
+	}
+	if len(header) < 2 {
+		return nil, errors.New("csv header requires timestamp and at least one metric column")
+	}
+	var samples []Sample
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		timestamp, err := parseTimestamp(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sample := Sample{
+			Timestamp: timestamp,
+			Values:    make(map[string]float64, len(header)-1),
+		}
+		for i, metric := range header[1:] {
+			rawValue := strings.TrimSpace(record[i+1])
+			// Empty value means metric is missing at the moment
+			if rawValue == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(rawValue, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value of `%s`: %w", line, metric, err)
+			}
+			sample.Values[strings.TrimSpace(metric)] = value
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// parseTimestamp accepts RFC3339 time or unix seconds
+func parseTimestamp(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		integer, fraction := math.Modf(seconds)
+		return time.Unix(int64(integer), int64(fraction*float64(time.Second))).UTC(), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp `%s`, requires RFC3339 or unix seconds", raw)
+	}
+	return timestamp, nil
+}
+
+type prometheusRangeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string       `json:"resultType"`
+		Result     model.Matrix `json:"result"`
+	} `json:"data"`
+}
+
+func parsePrometheusSeries(data []byte, metric string) ([]Sample, error) {
+	if metric == "" {
+		return nil, errors.New("target metric of prometheus export is required")
+	}
+	var response prometheusRangeResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "" && response.Status != "success" {
+		return nil, fmt.Errorf("prometheus export is not successful: %s", response.Error)
+	}
+	if response.Data.ResultType != model.ValMatrix.String() {
+		return nil, fmt.Errorf("prometheus export requires result type `matrix` but got `%s`", response.Data.ResultType)
+	}
+	switch len(response.Data.Result) {
+	case 0:
+		return nil, ErrEmptySeries
+	case 1:
+	default:
+		return nil, ErrMultiplePrometheusData
+	}
+	series := response.Data.Result[0]
+	samples := make([]Sample, 0, len(series.Values))
+	for _, pair := range series.Values {
+		samples = append(samples, Sample{
+			Timestamp: pair.Timestamp.Time().UTC(),
+			Values:    map[string]float64{metric: float64(pair.Value)},
+		})
+	}
+	return samples, nil
+}
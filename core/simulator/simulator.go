@@ -0,0 +1,301 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/core/scheduling"
+	simple "github.com/xscaling/wing/plugins/replicator_simple"
+	cpu "github.com/xscaling/wing/plugins/scaler_cpu"
+	memory "github.com/xscaling/wing/plugins/scaler_memory"
+	prometheusscaler "github.com/xscaling/wing/plugins/scaler_prometheus"
+	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/scalerprovider/podresource"
+	"github.com/xscaling/wing/utils/scalerprovider/prometheus"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultScalingColdDown is the same as cold-down of controller
+	DefaultScalingColdDown = 30 * time.Second
+
+	replayServerAddress = "replay://"
+)
+
+// Options of simulator, plugin configs are the same as `plugins` in wing config
+type Options struct {
+	// InitialReplicas is replicas of scale target before first sample,
+	// default to current replicas in status or min replicas.
+	InitialReplicas *int32
+	// ScalingColdDown skips scaling within the period after last scaling unless in panic mode
+	ScalingColdDown time.Duration
+
+	CPU        podresource.Config
+	Memory     podresource.Config
+	Prometheus prometheus.ScalerConfig
+	Replicator simple.Config
+}
+
+func NewDefaultOptions() Options {
+	return Options{
+		ScalingColdDown: DefaultScalingColdDown,
+		CPU:             *podresource.NewDefaultConfig(),
+		Memory:          *podresource.NewDefaultConfig(),
+		Prometheus:      *prometheus.NewDefaultConfig(),
+		Replicator:      *simple.NewDefaultConfig(),
+	}
+}
+
+// Step is the result of replaying a sample
+type Step struct {
+	Timestamp       time.Time          `json:"timestamp"`
+	Metrics         map[string]float64 `json:"metrics"`
+	CurrentReplicas int32              `json:"currentReplicas"`
+	DesiredReplicas int32              `json:"desiredReplicas"`
+	PanicMode       bool               `json:"panicMode"`
+	// ReplicaPatch is the effective replica patch combined from working patches at the moment
+	ReplicaPatch *wingv1.ReplicaPatchStatus `json:"replicaPatch,omitempty"`
+	// ReplicaRamp is the ramp of scaling range in progress at the moment
+	ReplicaRamp *wingv1.ReplicaRampStatus `json:"replicaRamp,omitempty"`
+	// Skipped is the reason of skipping autoscaling, e.g. still in cold-down period
+	Skipped  string                `json:"skipped,omitempty"`
+	Decision []wingv1.DecisionStep `json:"decision,omitempty"`
+	Summary  string                `json:"summary,omitempty"`
+	// Error aborts autoscaling of the sample and keeps current replicas, as controller does
+	Error string `json:"error,omitempty"`
+}
+
+// Simulator replays recorded metrics of an autoscaler through scalers, schedules, replica patches,
+// panic mode and replicator with a fake clock. Scaling takes effect at once in simulation.
+type Simulator struct {
+	options    Options
+	autoscaler *wingv1.ReplicaAutoscaler
+	clock      *clocktesting.FakePassiveClock
+	cursor     *sampleCursor
+	scalers    map[string]engine.Scaler
+	replicator engine.Replicator
+	replicas   int32
+}
+
+// LoadAutoscaler parses ReplicaAutoscaler manifest in YAML or JSON
+func LoadAutoscaler(data []byte) (*wingv1.ReplicaAutoscaler, error) {
+	autoscaler := &wingv1.ReplicaAutoscaler{}
+	if err := yaml.UnmarshalStrict(data, autoscaler); err != nil {
+		return nil, fmt.Errorf("invalid autoscaler: %w", err)
+	}
+	if autoscaler.Kind != "" && autoscaler.Kind != "ReplicaAutoscaler" {
+		return nil, fmt.Errorf("invalid autoscaler: unexpected kind `%s`", autoscaler.Kind)
+	}
+	return autoscaler, nil
+}
+
+func New(autoscaler *wingv1.ReplicaAutoscaler, options Options) (*Simulator, error) {
+	if autoscaler.Spec.Replicator != nil && *autoscaler.Spec.Replicator != simple.PluginName {
+		return nil, fmt.Errorf("replicator `%s` is not supported by simulator", *autoscaler.Spec.Replicator)
+	}
+	s := &Simulator{
+		options:    options,
+		autoscaler: autoscaler.DeepCopy(),
+		clock:      clocktesting.NewFakePassiveClock(time.Time{}),
+		cursor:     &sampleCursor{},
+	}
+	// Recorded values take place of default server
+	prometheusConfig := options.Prometheus
+	if prometheusConfig.DefaultServer.ServerAddress == nil {
+		prometheusConfig.DefaultServer.ServerAddress = pointer.String(replayServerAddress)
+	}
+	prometheusScaler, err := prometheus.NewWithQueryClient(prometheusscaler.PluginName, prometheusConfig,
+		&replayQueryClient{cursor: s.cursor, metric: prometheusscaler.PluginName})
+	if err != nil {
+		return nil, fmt.Errorf("invalid prometheus config: %w", err)
+	}
+	if err = options.CPU.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cpu config: %w", err)
+	}
+	if err = options.Memory.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid memory config: %w", err)
+	}
+	s.scalers = map[string]engine.Scaler{
+		prometheusscaler.PluginName: prometheusScaler,
+		cpu.PluginName: &utilizationScaler{
+			cursor: s.cursor, pluginName: cpu.PluginName, resource: corev1.ResourceCPU, config: options.CPU,
+		},
+		memory.PluginName: &utilizationScaler{
+			cursor: s.cursor, pluginName: memory.PluginName, resource: corev1.ResourceMemory, config: options.Memory,
+		},
+	}
+	s.replicator = simple.NewReplicatorWithClock(options.Replicator, s.clock)
+
+	switch {
+	case options.InitialReplicas != nil:
+		s.replicas = *options.InitialReplicas
+	case s.autoscaler.Status.CurrentReplicas > 0:
+		s.replicas = s.autoscaler.Status.CurrentReplicas
+	case s.autoscaler.Spec.MinReplicas != nil:
+		s.replicas = *s.autoscaler.Spec.MinReplicas
+	default:
+		s.replicas = s.autoscaler.Spec.MaxReplicas
+	}
+	return s, nil
+}
+
+// Run replays samples in order, a step is returned for each sample
+func (s *Simulator) Run(samples []Sample) []Step {
+	steps := make([]Step, 0, len(samples))
+	for _, sample := range samples {
+		steps = append(steps, s.replay(sample))
+	}
+	return steps
+}
+
+func (s *Simulator) replay(sample Sample) (step Step) {
+	now := sample.Timestamp
+	s.clock.SetTime(now)
+	s.cursor.current = sample
+
+	autoscaler := s.autoscaler
+	autoscaler.Status.CurrentReplicas = s.replicas
+	step = Step{
+		Timestamp:       now,
+		Metrics:         sample.Values,
+		CurrentReplicas: s.replicas,
+		DesiredReplicas: s.replicas,
+	}
+
+	trace := engine.NewDecisionTrace()
+	defer func() {
+		step.Decision = trace.Steps()
+		step.Summary = trace.Summary()
+	}()
+
+	// A static replicas setting
+	if autoscaler.Spec.MinReplicas == nil {
+		trace.Record(wingv1.DecisionStageLimit, "static", autoscaler.Spec.MaxReplicas,
+			"static replicas without autoscaling")
+		step.DesiredReplicas = autoscaler.Spec.MaxReplicas
+		s.scale(now, step.DesiredReplicas)
+		return step
+	}
+
+	underPanicModeCurrently := utils.StillInPanicMode(now, autoscaler.Status, autoscaler.Spec.Strategy)
+	step.PanicMode = underPanicModeCurrently
+	if autoscaler.Status.LastScaleTime != nil &&
+		now.Sub(autoscaler.Status.LastScaleTime.Time) < s.options.ScalingColdDown &&
+		!underPanicModeCurrently {
+		step.Skipped = "ScalingColdDown"
+		return step
+	}
+
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Namespace: autoscaler.Namespace, Name: autoscaler.Spec.ScaleTargetRef.Name},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: s.replicas},
+		Status:     autoscalingv1.ScaleStatus{Replicas: s.replicas},
+	}
+	replicatorContext := engine.NewReplicatorContext(context.TODO(), s.clock, autoscaler, scale, trace)
+	var managedTargetStatus []string
+	for _, target := range autoscaler.Spec.Targets {
+		scheduledTargetSettings, err := scheduling.GetScheduledSettingsRaw(now, target.Settings)
+		if err != nil {
+			step.Error = fmt.Sprintf("failed to get scheduled settings of `%s`: %s", target.Metric, err)
+			return step
+		}
+		scaler, ok := s.scalers[target.Metric]
+		if !ok {
+			step.Error = fmt.Sprintf("scaler `%s` is not supported by simulator", target.Metric)
+			return step
+		}
+		scalerOutput, err := scaler.Get(engine.ScalerContext{
+			Clock:                s.clock,
+			RawSettings:          scheduledTargetSettings,
+			Namespace:            autoscaler.Namespace,
+			ScaledObjectSelector: labels.Everything(),
+			CurrentReplicas:      s.replicas,
+			AutoscalerStatus:     &autoscaler.Status,
+		})
+		if err != nil {
+			step.Error = fmt.Sprintf("scaler `%s` failed: %s", target.Metric, err)
+			return step
+		}
+		replicatorContext.ScalersOutput[target.Metric] = *scalerOutput
+		trace.Record(wingv1.DecisionStageScaler, target.Metric, scalerOutput.DesiredReplicas,
+			"calculated by scaler with %d target status", len(scalerOutput.ManagedTargetStatus))
+		managedTargetStatus = append(managedTargetStatus, scalerOutput.ManagedTargetStatus...)
+	}
+	utils.PurgeTargetStatus(managedTargetStatus, &autoscaler.Status)
+
+	desiredReplicas, err := s.replicator.GetDesiredReplicas(replicatorContext)
+	if err != nil {
+		step.Error = fmt.Sprintf("replicator failed: %s", err)
+		return step
+	}
+
+	maxReplicas, minReplicas := autoscaler.Spec.MaxReplicas, *autoscaler.Spec.MinReplicas
+	replicaPatches, sources, err := scheduling.GetAutoscalerReplicaPatches(*autoscaler, nil)
+	if err == nil {
+		step.ReplicaPatch, err = scheduling.GetEffectiveReplicaPatch(now, replicaPatches, sources,
+			autoscaler.Spec.ReplicaPatchMode)
+	}
+	step.ReplicaRamp = scheduling.GetReplicaRamp(now, autoscaler.Status.ReplicaRamp,
+		autoscaler.Status.ReplicaPatch, step.ReplicaPatch, minReplicas, maxReplicas)
+	autoscaler.Status.ReplicaPatch, autoscaler.Status.ReplicaRamp = step.ReplicaPatch, step.ReplicaRamp
+	if err != nil {
+		// Fallback to default as controller does
+		trace.Record(wingv1.DecisionStageReplicaPatch, "", desiredReplicas, "invalid replica patches: %s", err)
+	} else if step.ReplicaPatch != nil {
+		maxReplicas, minReplicas = step.ReplicaPatch.MaxReplicas, step.ReplicaPatch.MinReplicas
+		trace.Record(wingv1.DecisionStageReplicaPatch, "", desiredReplicas,
+			"scaling range patched to [%d, %d]", minReplicas, maxReplicas)
+	}
+	if step.ReplicaRamp != nil {
+		minReplicas, maxReplicas = scheduling.GetRampedReplicaRange(now, *step.ReplicaRamp)
+		trace.Record(wingv1.DecisionStageReplicaPatch, "", desiredReplicas,
+			"scaling range ramped to [%d, %d] towards [%d, %d]", minReplicas, maxReplicas,
+			step.ReplicaRamp.ToMinReplicas, step.ReplicaRamp.ToMaxReplicas)
+	}
+	if desiredReplicas > maxReplicas {
+		desiredReplicas = maxReplicas
+		trace.Record(wingv1.DecisionStageLimit, "max", desiredReplicas, "limited by max replicas %d", maxReplicas)
+	}
+	if desiredReplicas < minReplicas {
+		desiredReplicas = minReplicas
+		trace.Record(wingv1.DecisionStageLimit, "min", desiredReplicas, "limited by min replicas %d", minReplicas)
+	}
+	step.DesiredReplicas = desiredReplicas
+
+	shouldEnterPanicMode := utils.ShouldEnterPanicMode(desiredReplicas, s.replicas, autoscaler.Spec.Strategy)
+	s.scale(now, desiredReplicas)
+	if shouldEnterPanicMode {
+		step.PanicMode = true
+		s.autoscaler.Status.Conditions = wingv1.SetConditionAt(s.autoscaler.Status.Conditions, wingv1.Condition{
+			Type:   wingv1.ConditionPanicMode,
+			Status: metav1.ConditionTrue,
+		}, now)
+	} else if !utils.StillInPanicMode(now, autoscaler.Status, autoscaler.Spec.Strategy) {
+		step.PanicMode = false
+		s.autoscaler.Status.Conditions = wingv1.SetConditionAt(s.autoscaler.Status.Conditions, wingv1.Condition{
+			Type:   wingv1.ConditionPanicMode,
+			Status: metav1.ConditionFalse,
+		}, now)
+	}
+	return step
+}
+
+func (s *Simulator) scale(now time.Time, desiredReplicas int32) {
+	s.autoscaler.Status.DesiredReplicas = desiredReplicas
+	if s.replicas == desiredReplicas {
+		return
+	}
+	s.replicas = desiredReplicas
+	lastScaleTime := metav1.NewTime(now)
+	s.autoscaler.Status.LastScaleTime = &lastScaleTime
+}
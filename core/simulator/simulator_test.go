@@ -0,0 +1,247 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+)
+
+var (
+	replayStartAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestParseSeries(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		data     string
+		format   Format
+		metric   string
+		hasError bool
+		expected []Sample
+	}{
+		{
+			name: "csv with missing value",
+			data: "timestamp,cpu,prometheus\n" +
+				"# comment is ignored\n" +
+				"2024-01-01T00:01:00Z,80,\n" +
+				"1704067200,50.5,12\n",
+			expected: []Sample{
+				{Timestamp: replayStartAt, Values: map[string]float64{"cpu": 50.5, "prometheus": 12}},
+				{Timestamp: replayStartAt.Add(time.Minute), Values: map[string]float64{"cpu": 80}},
+			},
+		},
+		{
+			name:     "csv with invalid timestamp",
+			data:     "timestamp,cpu\nyesterday,80\n",
+			hasError: true,
+		},
+		{
+			name:     "csv without metric",
+			data:     "timestamp\n1704067200\n",
+			format:   FormatCSV,
+			hasError: true,
+		},
+		{
+			name: "json",
+			data: `[{"timestamp": "2024-01-01T00:00:00Z", "values": {"cpu": 80}}]`,
+			expected: []Sample{
+				{Timestamp: replayStartAt, Values: map[string]float64{"cpu": 80}},
+			},
+		},
+		{
+			name:     "empty json",
+			data:     `[]`,
+			hasError: true,
+		},
+		{
+			name: "prometheus export",
+			data: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{},"values":[[1704067200,"1"],[1704067260.5,"2.5"]]}]}}`,
+			metric: "prometheus",
+			expected: []Sample{
+				{Timestamp: replayStartAt, Values: map[string]float64{"prometheus": 1}},
+				{Timestamp: replayStartAt.Add(60500 * time.Millisecond), Values: map[string]float64{"prometheus": 2.5}},
+			},
+		},
+		{
+			name: "prometheus export with multiple series",
+			data: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{"a":"1"},"values":[[1704067200,"1"]]},{"metric":{"a":"2"},"values":[[1704067200,"1"]]}]}}`,
+			metric:   "prometheus",
+			hasError: true,
+		},
+		{
+			name:     "prometheus export of vector",
+			data:     `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			metric:   "prometheus",
+			hasError: true,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			samples, err := ParseSeries([]byte(testCase.data), testCase.format, testCase.metric)
+			if testCase.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, samples, len(testCase.expected))
+			for i := range samples {
+				require.True(t, testCase.expected[i].Timestamp.Equal(samples[i].Timestamp), "sample %d", i)
+				require.Equal(t, testCase.expected[i].Values, samples[i].Values, "sample %d", i)
+			}
+		})
+	}
+}
+
+const testAutoscaler = `
+apiVersion: wing.xscaling.dev/v1
+kind: ReplicaAutoscaler
+metadata:
+  name: test
+  namespace: default
+  annotations:
+    wing.xscaling.dev/replica-patches: '[{"timezone":"UTC","start":"2024-01-01 00:10","end":"2024-01-01 00:20","minReplicas":6,"maxReplicas":6}]'
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: test
+  minReplicas: 1
+  maxReplicas: 10
+  strategy:
+    panicThreshold: "2"
+    panicWindowSeconds: 120
+  targets:
+  - metric: prometheus
+    settings:
+      default:
+        query: sum(rate(requests_total[1m]))
+        threshold: 10
+      schedules:
+      - timezone: UTC
+        start: "30 0 * * *"
+        end: "40 0 * * *"
+        settings:
+          threshold: 5
+`
+
+func prometheusSample(offset time.Duration, value float64) Sample {
+	return Sample{
+		Timestamp: replayStartAt.Add(offset),
+		Values:    map[string]float64{"prometheus": value},
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	autoscaler, err := LoadAutoscaler([]byte(testAutoscaler))
+	require.NoError(t, err)
+	_, err = LoadAutoscaler([]byte("kind: Deployment"))
+	require.Error(t, err)
+
+	type expectedStep struct {
+		desiredReplicas int32
+		panicMode       bool
+		patched         bool
+		skipped         bool
+		hasError        bool
+	}
+	for _, testCase := range []struct {
+		name         string
+		disableTuner bool
+		samples      []Sample
+		expected     []expectedStep
+	}{
+		{
+			name: "limited by flux",
+			samples: []Sample{
+				prometheusSample(0, 20),
+				prometheusSample(time.Minute, 80),
+				prometheusSample(2*time.Minute, 80),
+				prometheusSample(2*time.Minute+10*time.Second, 80),
+				prometheusSample(3*time.Minute, 80),
+			},
+			expected: []expectedStep{
+				{desiredReplicas: 2},
+				// 2 * 150%
+				{desiredReplicas: 3},
+				// 3 * 150%
+				{desiredReplicas: 5},
+				// cold-down
+				{desiredReplicas: 5, skipped: true},
+				{desiredReplicas: 8},
+			},
+		},
+		{
+			name:         "panic mode",
+			disableTuner: true,
+			samples: []Sample{
+				prometheusSample(0, 20),
+				prometheusSample(time.Minute, 80),
+				prometheusSample(time.Minute+10*time.Second, 80),
+				prometheusSample(4*time.Minute, 40),
+			},
+			expected: []expectedStep{
+				{desiredReplicas: 2},
+				{desiredReplicas: 8, panicMode: true},
+				// cold-down is skipped under panic mode
+				{desiredReplicas: 8, panicMode: true},
+				// out of panic window
+				{desiredReplicas: 4},
+			},
+		},
+		{
+			name:         "replica patch and scheduled settings",
+			disableTuner: true,
+			samples: []Sample{
+				prometheusSample(5*time.Minute, 20),
+				prometheusSample(15*time.Minute, 20),
+				prometheusSample(25*time.Minute, 20),
+				// halved threshold
+				prometheusSample(35*time.Minute, 20),
+			},
+			expected: []expectedStep{
+				{desiredReplicas: 2},
+				// scaling up to min replicas of patch enters panic mode as well
+				{desiredReplicas: 6, panicMode: true, patched: true},
+				{desiredReplicas: 2},
+				{desiredReplicas: 4, panicMode: true},
+			},
+		},
+		{
+			name:         "metric missing",
+			disableTuner: true,
+			samples: []Sample{
+				prometheusSample(0, 20),
+				{Timestamp: replayStartAt.Add(time.Minute)},
+			},
+			expected: []expectedStep{
+				{desiredReplicas: 2},
+				{desiredReplicas: 2, hasError: true},
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			options := NewDefaultOptions()
+			options.InitialReplicas = pointer.Int32(2)
+			options.Replicator.DisableTuner = testCase.disableTuner
+			simulator, err := New(autoscaler, options)
+			require.NoError(t, err)
+
+			steps := simulator.Run(testCase.samples)
+			require.Len(t, steps, len(testCase.expected))
+			for i, step := range steps {
+				expected := testCase.expected[i]
+				require.Equal(t, expected.desiredReplicas, step.DesiredReplicas, "step %d: %s", i, step.Summary)
+				if !expected.skipped && !expected.hasError {
+					require.NotEmpty(t, step.Decision, "step %d", i)
+				}
+				require.Equal(t, expected.panicMode, step.PanicMode, "step %d", i)
+				require.Equal(t, expected.patched, step.ReplicaPatch != nil, "step %d", i)
+				require.Equal(t, expected.skipped, step.Skipped != "", "step %d", i)
+				require.Equal(t, expected.hasError, step.Error != "", "step %d: %s", i, step.Error)
+			}
+		})
+	}
+}
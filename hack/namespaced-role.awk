@@ -0,0 +1,16 @@
+# Generates namespaced Role from ClusterRole generated by controller-gen,
+# rules of cluster scoped resources are dropped as they could not be granted by Role.
+function flush() {
+	if (!skip) printf "%s", rule
+	rule = ""
+	skip = 0
+}
+/^kind: ClusterRole$/ { print "kind: Role"; next }
+/^- apiGroups:/ { flush(); inRule = 1 }
+inRule {
+	rule = rule $0 "\n"
+	if ($0 ~ /^  - (namespaces|clusterreplicaautoscalerpolicies)$/) skip = 1
+	next
+}
+{ print }
+END { flush() }
@@ -29,6 +29,7 @@ import (
 	// Register plugins
 	"github.com/xscaling/wing/core/engine"
 	_ "github.com/xscaling/wing/core/engine/plugin"
+	"github.com/xscaling/wing/core/sharding"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -56,6 +57,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == simulateCommand {
+		os.Exit(runSimulate(os.Args[2:]))
+	}
+
 	var (
 		metricsAddr          string
 		enableLeaderElection bool
@@ -90,8 +95,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	newCache, err := controllers.NewCache(controllerOptions.WatchNamespaces, config.PodCache)
+	if err != nil {
+		setupLog.Error(err, "invalid pod cache config")
+		os.Exit(1)
+	}
+
+	if config.Sharding.Enabled && enableLeaderElection {
+		setupLog.Info("Leader election is disabled as sharding enabled")
+		enableLeaderElection = false
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		NewCache:               newCache,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
@@ -114,25 +131,71 @@ func main() {
 		os.Exit(1)
 	}
 
+	if config.Tracing.Enabled {
+		tracingFlusher, err := controllers.SetupTracing(config.Tracing)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		if err = mgr.Add(tracingFlusher); err != nil {
+			setupLog.Error(err, "unable to set up tracing flusher")
+			os.Exit(1)
+		}
+	}
+
 	eventRecorder := mgr.GetEventRecorderFor("wing")
-	coreEngine, err := engine.New(mgr.GetConfig(), config.Plugins, eventRecorder)
+	coreEngine, err := engine.New(mgr.GetConfig(), mgr.GetCache(), config.Plugins, eventRecorder)
 	if err != nil {
 		setupLog.Error(err, "unable to start engine")
 		os.Exit(1)
 	}
+	if controllerOptions.ConfigReloadInterval > 0 {
+		configReloader, err := controllers.NewConfigReloader(controllerOptions, config, coreEngine)
+		if err != nil {
+			setupLog.Error(err, "unable to create config reloader")
+			os.Exit(1)
+		}
+		if err = mgr.Add(configReloader); err != nil {
+			setupLog.Error(err, "unable to set up config reloader")
+			os.Exit(1)
+		}
+	}
+	var shardingCoordinator *sharding.Coordinator
+	if config.Sharding.Enabled {
+		if shardingCoordinator, err = controllers.NewShardingCoordinator(config.Sharding, mgr.GetConfig()); err != nil {
+			setupLog.Error(err, "unable to create sharding coordinator")
+			os.Exit(1)
+		}
+		if err = mgr.Add(shardingCoordinator); err != nil {
+			setupLog.Error(err, "unable to set up sharding coordinator")
+			os.Exit(1)
+		}
+	}
 	if err = (&controllers.ReplicaAutoscalerReconciler{
 		Config:           config.ReplicaAutoscalerControllerConfig,
 		KubernetesConfig: mgr.GetConfig(),
 		Client:           mgr.GetClient(),
 		Cache:            mgr.GetCache(),
+		APIReader:        mgr.GetAPIReader(),
 		Scheme:           mgr.GetScheme(),
 		EventRecorder:    eventRecorder,
 		Engine:           coreEngine,
 		DryRun:           dryRun,
+		Sharding:         shardingCoordinator,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ReplicaAutoscaler")
 		os.Exit(1)
 	}
+	if err = (&controllers.ScalingWindowReconciler{
+		Client:        mgr.GetClient(),
+		APIReader:     mgr.GetAPIReader(),
+		EventRecorder: eventRecorder,
+		Clock:         coreEngine.GetClock(),
+		Sharding:      shardingCoordinator,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ScalingWindow")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -143,6 +206,21 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if config.PluginHealth.Interval > 0 {
+		pluginHealthReporter := controllers.NewPluginHealthReporter(config.PluginHealth, coreEngine)
+		if err = mgr.Add(pluginHealthReporter); err != nil {
+			setupLog.Error(err, "unable to set up plugin health reporter")
+			os.Exit(1)
+		}
+		if err = mgr.AddReadyzCheck("plugins", pluginHealthReporter.ReadyzCheck); err != nil {
+			setupLog.Error(err, "unable to set up plugin ready check")
+			os.Exit(1)
+		}
+		if err = mgr.AddMetricsExtraHandler("/plugins", pluginHealthReporter); err != nil {
+			setupLog.Error(err, "unable to set up plugin health endpoint")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -5,9 +5,12 @@ import (
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 	"github.com/xscaling/wing/core/engine"
+	"github.com/xscaling/wing/utils/tracing"
 	"github.com/xscaling/wing/utils/tuner"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -45,7 +48,7 @@ type replicator struct {
 	flux tuner.Tuner
 }
 
-func getUniqueKeyForAutoscaler(autoscaler *wingv1.ReplicaAutoscaler) string {
+func getUniqueKeyForAutoscaler(autoscaler types.NamespacedName) string {
 	return autoscaler.Name + "/" + autoscaler.Namespace
 }
 
@@ -56,7 +59,10 @@ func (r *replicator) GetName() string {
 func (r *replicator) GetDesiredReplicas(ctx engine.ReplicatorContext) (int32, error) {
 	logger := r.logger.WithValues("namespace", ctx.Autoscaler.Namespace, "replicaAutoscaler", ctx.Autoscaler.Name)
 
-	keyForAutoscaler := getUniqueKeyForAutoscaler(ctx.Autoscaler)
+	keyForAutoscaler := getUniqueKeyForAutoscaler(types.NamespacedName{
+		Namespace: ctx.Autoscaler.Namespace,
+		Name:      ctx.Autoscaler.Name,
+	})
 	var settings Settings
 	err := utils.ExtractRawExtension(ctx.Autoscaler.Spec.ReplicatorSettings, &settings)
 	if err != nil {
@@ -66,19 +72,36 @@ func (r *replicator) GetDesiredReplicas(ctx engine.ReplicatorContext) (int32, er
 
 	var (
 		desiredReplicas int32
+		selectedScaler  string
 	)
 	for scaler, scalerOutput := range ctx.ScalersOutput {
 		logger.V(8).Info("Got scaler desired replicas",
 			"scaler", scaler, "selectedDesiredReplicas", desiredReplicas, "desiredReplicas", scalerOutput.DesiredReplicas)
 		if scalerOutput.DesiredReplicas > desiredReplicas {
 			desiredReplicas = scalerOutput.DesiredReplicas
+			selectedScaler = scaler
 			logger.V(8).Info("Using scaler replicas", "replicas", desiredReplicas, "scaler", scaler)
 		}
 	}
+	if selectedScaler != "" {
+		ctx.Decision.Record(wingv1.DecisionStageReplicator, PluginName, desiredReplicas,
+			"max of %d scaler(s) output, selected `%s`", len(ctx.ScalersOutput), selectedScaler)
+	} else {
+		ctx.Decision.Record(wingv1.DecisionStageReplicator, PluginName, desiredReplicas,
+			"all of %d scaler(s) output zero", len(ctx.ScalersOutput))
+	}
 
 	if r.flux != nil {
+		var explainers []tuner.Explainer
+		if ctx.Decision != nil {
+			explainers = append(explainers, ctx.Decision)
+		}
+		_, span := tracing.StartSpan(ctx.GetContext(), "Tuner.GetRecommendation",
+			attribute.String("tuner", r.flux.GetName()), attribute.Int("desiredReplicas", int(desiredReplicas)))
 		fluxReplicas := r.flux.GetRecommendation(keyForAutoscaler,
-			ctx.Autoscaler.Status.CurrentReplicas, desiredReplicas, settings.FluxPreference)
+			ctx.Autoscaler.Status.CurrentReplicas, desiredReplicas, settings.FluxPreference, explainers...)
+		span.SetAttributes(attribute.Int("recommendedReplicas", int(fluxReplicas)))
+		tracing.EndSpan(span, nil)
 		if fluxReplicas != desiredReplicas {
 			logger.V(2).Info("Fluxed desire replicas",
 				"normalizedDesiredReplicas", desiredReplicas, "fluxReplicas", fluxReplicas)
@@ -89,3 +112,24 @@ func (r *replicator) GetDesiredReplicas(ctx engine.ReplicatorContext) (int32, er
 
 	return desiredReplicas, nil
 }
+
+// ExportState implements engine.StatefulPlugin by exporting memory of stateful tuners
+func (r *replicator) ExportState(autoscaler types.NamespacedName) ([]byte, error) {
+	if statefulTuner, ok := r.flux.(tuner.StatefulTuner); ok {
+		return statefulTuner.ExportMemory(getUniqueKeyForAutoscaler(autoscaler))
+	}
+	return nil, nil
+}
+
+func (r *replicator) ImportState(autoscaler types.NamespacedName, state []byte) error {
+	if statefulTuner, ok := r.flux.(tuner.StatefulTuner); ok {
+		return statefulTuner.ImportMemory(getUniqueKeyForAutoscaler(autoscaler), state)
+	}
+	return nil
+}
+
+func (r *replicator) ForgetState(autoscaler types.NamespacedName) {
+	if statefulTuner, ok := r.flux.(tuner.StatefulTuner); ok {
+		statefulTuner.ForgetMemory(getUniqueKeyForAutoscaler(autoscaler))
+	}
+}
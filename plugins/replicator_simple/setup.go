@@ -6,6 +6,7 @@ import (
 	"github.com/xscaling/wing/core/engine"
 	"github.com/xscaling/wing/utils/tuner"
 
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -23,18 +24,45 @@ func setup(c engine.Controller) error {
 		return fmt.Errorf("plugin config is required: ok %v err %v", ok, err)
 	}
 
-	c.AddReplicator(PluginName, NewReplicator(*config))
+	c.AddReplicator(PluginName, NewReplicatorWithClock(*config, c.GetClock()))
+	c.SetReloadHook(engine.PluginEndpointReplicator, PluginName, reload)
+	return nil
+}
+
+// reload sets up replicator with new config and carries flux memory over
+func reload(c engine.Controller, previous interface{}) error {
+	config := NewDefaultConfig()
+	ok, err := c.GetPluginConfig(PluginName, config)
+	if !ok || err != nil {
+		return fmt.Errorf("plugin config is required: ok %v err %v", ok, err)
+	}
+
+	r := NewReplicatorWithClock(*config, c.GetClock())
+	if previousReplicator, ok := previous.(*replicator); ok {
+		previousFlux, previousOk := previousReplicator.flux.(*tuner.FluxTuner)
+		flux, ok := r.flux.(*tuner.FluxTuner)
+		if previousOk && ok {
+			flux.InheritMemory(previousFlux)
+		}
+	}
+	c.AddReplicator(PluginName, r)
+	c.SetReloadHook(engine.PluginEndpointReplicator, PluginName, reload)
 	return nil
 }
 
 func NewReplicator(conf Config) *replicator {
+	return NewReplicatorWithClock(conf, clock.RealClock{})
+}
+
+// NewReplicatorWithClock returns replicator whose tuners work on given clock
+func NewReplicatorWithClock(conf Config, clock clock.PassiveClock) *replicator {
 	r := &replicator{
 		config: conf,
 		logger: log.Log.WithName(PluginName),
 	}
 
 	if !conf.DisableTuner {
-		r.flux = tuner.NewFluxTuner(conf.Flux)
+		r.flux = tuner.NewFluxTunerWithClock(conf.Flux, clock)
 	}
 	return r
 }
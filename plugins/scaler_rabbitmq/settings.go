@@ -1,6 +1,7 @@
 package rabbitmq
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -129,12 +130,12 @@ func (s *Settings) GetStatusMetricName() (result string) {
 	return strings.Join(splits, statusMetricNameJoiner)
 }
 
-func (s *Settings) request(timeout time.Duration) (metricValue float64, err error) {
+func (s *Settings) request(ctx context.Context, timeout time.Duration) (metricValue float64, err error) {
 	requester := s.requestViaChannel
 	if s.Protocol == ProtocolHTTP {
 		requester = s.requestViaHTTP
 	}
-	messages, publishRate, err := requester(timeout)
+	messages, publishRate, err := requester(ctx, timeout)
 	if err != nil {
 		return
 	}
@@ -149,7 +150,7 @@ func getInitMessagesAndPublishRate() (messages int, publishRate float64) {
 	return -1, -1
 }
 
-func (s *Settings) requestViaHTTP(timeout time.Duration) (messages int, publishRate float64, err error) {
+func (s *Settings) requestViaHTTP(ctx context.Context, timeout time.Duration) (messages int, publishRate float64, err error) {
 	messages, publishRate = getInitMessagesAndPublishRate()
 
 	parsedURL, err := url.Parse(s.Host)
@@ -174,6 +175,7 @@ func (s *Settings) requestViaHTTP(timeout time.Duration) (messages int, publishR
 		infos []queueInfo
 	)
 	options := []client.Option{
+		client.WithContext(ctx),
 		client.WithExpectedStatusCode(http.StatusOK),
 	}
 	if s.UseRegex {
@@ -225,7 +227,8 @@ const (
 	defaultLocale    = "en_US"
 )
 
-func (s *Settings) requestViaChannel(timeout time.Duration) (messages int, publishRate float64, err error) {
+// requestViaChannel inspects queue via AMQP, which is not able to carry trace context
+func (s *Settings) requestViaChannel(_ context.Context, timeout time.Duration) (messages int, publishRate float64, err error) {
 	messages, publishRate = getInitMessagesAndPublishRate()
 	conn, err := amqp.DialConfig(s.Host, amqp.Config{
 		Dial:      amqp.DefaultDial(timeout),
@@ -66,7 +66,7 @@ func (s *scaler) Get(ctx engine.ScalerContext) (so *engine.ScalerOutput, err err
 	if t := settings.Timeout; t != nil {
 		timeout = *t
 	}
-	metricValue, err := settings.request(timeout)
+	metricValue, err := settings.request(ctx.GetContext(), timeout)
 	if err != nil {
 		return
 	}
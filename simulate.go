@@ -0,0 +1,199 @@
+/*
+Copyright 2024 xScaling.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xscaling/wing/controllers"
+	"github.com/xscaling/wing/core/simulator"
+	simple "github.com/xscaling/wing/plugins/replicator_simple"
+	cpu "github.com/xscaling/wing/plugins/scaler_cpu"
+	memory "github.com/xscaling/wing/plugins/scaler_memory"
+	prometheusscaler "github.com/xscaling/wing/plugins/scaler_prometheus"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const (
+	simulateCommand = "simulate"
+
+	simulateOutputTable = "table"
+	simulateOutputJSON  = "json"
+)
+
+type simulateOptions struct {
+	autoscaler      string
+	metrics         string
+	format          string
+	metric          string
+	config          string
+	initialReplicas int
+	coldDown        time.Duration
+	output          string
+}
+
+// runSimulate runs `wing simulate` which replays recorded metrics of an autoscaler offline,
+// returns exit code of the command.
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet(simulateCommand, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s --autoscaler <file> --metrics <file> [options]\n\n"+
+			"Replay recorded metrics through scalers, schedules, replica patches, panic mode and flux tuner "+
+			"to see how the autoscaler would have scaled.\n\n", os.Args[0], simulateCommand)
+		fs.PrintDefaults()
+	}
+	var options simulateOptions
+	fs.StringVar(&options.autoscaler, "autoscaler", "", "ReplicaAutoscaler manifest file to simulate.")
+	fs.StringVar(&options.metrics, "metrics", "",
+		"Recorded metrics file, values are keyed by target metric e.g. `cpu` or `prometheus`.")
+	fs.StringVar(&options.format, "format", "",
+		"Format of metrics file, one of csv, json and prometheus(range query response). Detected by content if empty.")
+	fs.StringVar(&options.metric, "metric", prometheusscaler.PluginName,
+		"Target metric which values of Prometheus range query response belong to.")
+	fs.StringVar(&options.config, "config", "",
+		"Wing config file path, plugin configs are used for simulation. Defaults are used if empty.")
+	fs.IntVar(&options.initialReplicas, "initial-replicas", -1,
+		"Replicas before first sample, defaults to current replicas in status or min replicas.")
+	fs.DurationVar(&options.coldDown, "cold-down", simulator.DefaultScalingColdDown,
+		"Scaling cold-down period, scaling is skipped within the period after last scaling unless in panic mode.")
+	fs.StringVar(&options.output, "output", simulateOutputTable, "Output format, one of table and json.")
+	zapOptions := zap.Options{}
+	zapOptions.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOptions), zap.WriteTo(os.Stderr)))
+
+	if err := simulate(os.Stdout, options); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to simulate: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func simulate(w io.Writer, options simulateOptions) error {
+	if options.autoscaler == "" || options.metrics == "" {
+		return errors.New("both --autoscaler and --metrics are required")
+	}
+	if options.output != simulateOutputTable && options.output != simulateOutputJSON {
+		return fmt.Errorf("unknown output format `%s`", options.output)
+	}
+	simulatorOptions, err := loadSimulatorOptions(options.config)
+	if err != nil {
+		return err
+	}
+	simulatorOptions.ScalingColdDown = options.coldDown
+	if options.initialReplicas >= 0 {
+		initialReplicas := int32(options.initialReplicas)
+		simulatorOptions.InitialReplicas = &initialReplicas
+	}
+
+	content, err := os.ReadFile(options.autoscaler)
+	if err != nil {
+		return err
+	}
+	autoscaler, err := simulator.LoadAutoscaler(content)
+	if err != nil {
+		return err
+	}
+	content, err = os.ReadFile(options.metrics)
+	if err != nil {
+		return err
+	}
+	samples, err := simulator.ParseSeries(content, simulator.Format(options.format), options.metric)
+	if err != nil {
+		return fmt.Errorf("invalid metrics: %w", err)
+	}
+	s, err := simulator.New(autoscaler, simulatorOptions)
+	if err != nil {
+		return err
+	}
+	steps := s.Run(samples)
+	if options.output == simulateOutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(steps)
+	}
+	return writeSimulationTable(w, steps)
+}
+
+// loadSimulatorOptions loads plugin configs from wing config
+func loadSimulatorOptions(configPath string) (simulator.Options, error) {
+	simulatorOptions := simulator.NewDefaultOptions()
+	if configPath == "" {
+		return simulatorOptions, nil
+	}
+	config := controllers.NewDefaultConfig()
+	if err := (&controllers.Options{Config: configPath}).LoadConfig(config); err != nil {
+		return simulatorOptions, fmt.Errorf("unable to load config: %w", err)
+	}
+	for name, receiver := range map[string]interface{}{
+		cpu.PluginName:              &simulatorOptions.CPU,
+		memory.PluginName:           &simulatorOptions.Memory,
+		prometheusscaler.PluginName: &simulatorOptions.Prometheus,
+		simple.PluginName:           &simulatorOptions.Replicator,
+	} {
+		rawConfig, ok := config.Plugins[name]
+		if !ok {
+			continue
+		}
+		if err := rawConfig.Unmarshal(receiver); err != nil {
+			return simulatorOptions, fmt.Errorf("invalid config of plugin `%s`: %w", name, err)
+		}
+	}
+	return simulatorOptions, nil
+}
+
+func writeSimulationTable(w io.Writer, steps []simulator.Step) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tMETRICS\tREPLICAS\tPANIC\tPATCH\tDECISION")
+	for _, step := range steps {
+		metrics := make([]string, 0, len(step.Metrics))
+		for metric, value := range step.Metrics {
+			metrics = append(metrics, fmt.Sprintf("%s=%g", metric, value))
+		}
+		sort.Strings(metrics)
+		patch := "-"
+		if step.ReplicaPatch != nil {
+			patch = fmt.Sprintf("[%d, %d]", step.ReplicaPatch.MinReplicas, step.ReplicaPatch.MaxReplicas)
+		}
+		decision := step.Summary
+		switch {
+		case step.Error != "":
+			decision = "error: " + step.Error
+		case step.Skipped != "":
+			decision = "skipped: " + step.Skipped
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d -> %d\t%v\t%s\t%s\n", step.Timestamp.Format(time.RFC3339),
+			strings.Join(metrics, ","), step.CurrentReplicas, step.DesiredReplicas, step.PanicMode, patch, decision)
+	}
+	return tw.Flush()
+}
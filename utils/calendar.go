@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const iCalendarBegin = "BEGIN:VCALENDAR"
+
+// ResolveScheduleCalendar returns copy of calendar with value of referred ConfigMap key inlined,
+// the value is taken as iCalendar if it begins with `BEGIN:VCALENDAR`, otherwise dates line by line.
+func ResolveScheduleCalendar(ctx context.Context, reader runtimeclient.Reader, namespace string,
+	calendar *wingv1.ScheduleCalendar) (*wingv1.ScheduleCalendar, error) {
+	if calendar == nil || calendar.ConfigMapKeyRef == nil {
+		return calendar, nil
+	}
+	ref := calendar.ConfigMapKeyRef
+	optional := ref.Optional != nil && *ref.Optional
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, runtimeclient.ObjectKey{Namespace: namespace, Name: ref.Name}, configMap); err != nil &&
+		!(optional && apierrors.IsNotFound(err)) {
+		return nil, fmt.Errorf("failed to get calendar ConfigMap %s: %w", ref.Name, err)
+	}
+	value, ok := configMap.Data[ref.Key]
+	if !ok && !optional {
+		return nil, fmt.Errorf("key %s not found in calendar ConfigMap %s", ref.Key, ref.Name)
+	}
+
+	resolved := calendar.DeepCopy()
+	resolved.ConfigMapKeyRef = nil
+	if strings.HasPrefix(strings.TrimSpace(value), iCalendarBegin) {
+		resolved.ICalendar = strings.TrimSpace(resolved.ICalendar + "\n" + value)
+		return resolved, nil
+	}
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			resolved.Dates = append(resolved.Dates, line)
+		}
+	}
+	return resolved, nil
+}
+
+// ResolveAutoscalerCalendars resolves calendars of target schedules and annotation replica patches,
+// autoscaler must be a copy which is used for scheduling only.
+// Calendars failed to resolve are left as they are to fail scheduling of their own.
+func ResolveAutoscalerCalendars(ctx context.Context, reader runtimeclient.Reader,
+	autoscaler *wingv1.ReplicaAutoscaler) error {
+	var errs []error
+	for i := range autoscaler.Spec.Targets {
+		schedules := autoscaler.Spec.Targets[i].Settings.Schedules
+		for j := range schedules {
+			resolved, err := ResolveScheduleCalendar(ctx, reader, autoscaler.Namespace, schedules[j].Calendar)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			schedules[j].Calendar = resolved
+		}
+	}
+
+	patches, err := GetReplicaPatches(*autoscaler)
+	if err != nil {
+		// Broken annotation is reported by scheduling
+		return utilerrors.NewAggregate(errs)
+	}
+	resolvedAny := false
+	for i := range patches {
+		if patches[i].Calendar == nil || patches[i].Calendar.ConfigMapKeyRef == nil {
+			continue
+		}
+		resolved, err := ResolveScheduleCalendar(ctx, reader, autoscaler.Namespace, patches[i].Calendar)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		patches[i].Calendar, resolvedAny = resolved, true
+	}
+	if resolvedAny {
+		if err = SetReplicaPatches(autoscaler, patches); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ResolveScalingWindowCalendar resolves calendar of window which must be a copy used for scheduling only
+func ResolveScalingWindowCalendar(ctx context.Context, reader runtimeclient.Reader,
+	window *wingv1.ScalingWindow) error {
+	resolved, err := ResolveScheduleCalendar(ctx, reader, window.Namespace, window.Spec.Calendar)
+	if err != nil {
+		return err
+	}
+	window.Spec.Calendar = resolved
+	return nil
+}
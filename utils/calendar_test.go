@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveScheduleCalendar(t *testing.T) {
+	reader := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "holidays"},
+		Data: map[string]string{
+			"dates":    "# National Day\n2024-10-01\n 2024-10-02 \n\n",
+			"calendar": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20241001\nEND:VEVENT\nEND:VCALENDAR",
+		},
+	}).Build()
+	newCalendar := func(key string, optional bool) *wingv1.ScheduleCalendar {
+		return &wingv1.ScheduleCalendar{
+			Mode:  wingv1.CalendarExclude,
+			Dates: []string{"2024-09-17"},
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "holidays"},
+				Key:                  key,
+				Optional:             pointer.Bool(optional),
+			},
+		}
+	}
+
+	calendar := newCalendar("dates", false)
+	resolved, err := ResolveScheduleCalendar(context.TODO(), reader, "default", calendar)
+	require.NoError(t, err)
+	require.Nil(t, resolved.ConfigMapKeyRef)
+	require.Equal(t, wingv1.CalendarExclude, resolved.Mode)
+	require.Equal(t, []string{"2024-09-17", "2024-10-01", "2024-10-02"}, resolved.Dates)
+	// Calendar of spec is untouched
+	require.NotNil(t, calendar.ConfigMapKeyRef)
+	require.Len(t, calendar.Dates, 1)
+
+	resolved, err = ResolveScheduleCalendar(context.TODO(), reader, "default", newCalendar("calendar", false))
+	require.NoError(t, err)
+	require.Contains(t, resolved.ICalendar, "DTSTART;VALUE=DATE:20241001")
+
+	_, err = ResolveScheduleCalendar(context.TODO(), reader, "default", newCalendar("unknown", false))
+	require.Error(t, err)
+	_, err = ResolveScheduleCalendar(context.TODO(), reader, "other", newCalendar("dates", false))
+	require.Error(t, err)
+	resolved, err = ResolveScheduleCalendar(context.TODO(), reader, "other", newCalendar("dates", true))
+	require.NoError(t, err)
+	require.Equal(t, []string{"2024-09-17"}, resolved.Dates)
+
+	// Autoscaler calendars are resolved in place, including annotation patches
+	autoscaler := &wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: wingv1.ReplicaAutoscalerSpec{Targets: []wingv1.ReplicaAutoscalerTarget{{
+			Metric: "cpu",
+			Settings: wingv1.TargetSettings{Schedules: []wingv1.ScheduleTargetSettings{{
+				Timezone: "UTC", Calendar: newCalendar("dates", false),
+			}}},
+		}}},
+	}
+	require.NoError(t, SetReplicaPatches(autoscaler, wingv1.ReplicaPatches{{
+		Timezone: "UTC", Calendar: newCalendar("calendar", false), MinReplicas: 1, MaxReplicas: 2,
+	}}))
+	require.NoError(t, ResolveAutoscalerCalendars(context.TODO(), reader, autoscaler))
+	require.Len(t, autoscaler.Spec.Targets[0].Settings.Schedules[0].Calendar.Dates, 3)
+	patches, err := GetReplicaPatches(*autoscaler)
+	require.NoError(t, err)
+	require.Nil(t, patches[0].Calendar.ConfigMapKeyRef)
+	require.NotEmpty(t, patches[0].Calendar.ICalendar)
+}
@@ -103,3 +103,18 @@ func GetGVKRUnstructured(gvkr wingv1.GroupVersionKindResource) *unstructured.Uns
 	payload.SetGroupVersionKind(gvkr.GroupVersionKind())
 	return payload
 }
+
+// GetScaleTargetKey returns `group/kind/name` of scale target which is used for indexing autoscalers by target.
+// Empty apiVersion or kind will be regarded as default one like ParseGVKR.
+func GetScaleTargetKey(apiVersion, kind, name string) string {
+	group := defaultGroup
+	if apiVersion != "" {
+		if groupVersion, err := schema.ParseGroupVersion(apiVersion); err == nil {
+			group = groupVersion.Group
+		}
+	}
+	if kind == "" {
+		kind = defaultKind
+	}
+	return group + "/" + kind + "/" + name
+}
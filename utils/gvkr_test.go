@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScaleTargetKey(t *testing.T) {
+	for _, testCase := range []struct {
+		apiVersion string
+		kind       string
+		name       string
+		expected   string
+	}{
+		{"", "", "web", "apps/Deployment/web"},
+		{"apps/v1", "Deployment", "web", "apps/Deployment/web"},
+		{"apps/v1beta1", "StatefulSet", "db", "apps/StatefulSet/db"},
+		{"v1", "ReplicationController", "legacy", "/ReplicationController/legacy"},
+		{"argoproj.io/v1alpha1", "Rollout", "web", "argoproj.io/Rollout/web"},
+	} {
+		assert.Equal(t, testCase.expected, GetScaleTargetKey(testCase.apiVersion, testCase.kind, testCase.name))
+	}
+}
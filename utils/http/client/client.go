@@ -31,7 +31,7 @@ func (c *Client) Request(method string, resourceFormat string, options ...Option
 			return nil, err
 		}
 	}
-	resp, err := c.requester.do(
+	resp, err := c.requester.do(opts.Context(),
 		opts.GetSigner(), method, c.endpoint, opts.Body(), opts.Headers(), resourceFormat, opts.Query())
 	if err != nil {
 		return resp, err
@@ -1,11 +1,14 @@
 package client
 
 import (
-	"github.com/xscaling/wing/utils/http/client/sign"
+	"context"
 	"net/url"
+
+	"github.com/xscaling/wing/utils/http/client/sign"
 )
 
 type Options struct {
+	ctx                context.Context
 	signer             sign.Signer
 	query              url.Values
 	body               interface{}
@@ -17,10 +20,15 @@ type Options struct {
 
 func NewOptions() *Options {
 	return &Options{
+		ctx:                context.Background(),
 		expectedStatusCode: -1,
 	}
 }
 
+func (o Options) Context() context.Context {
+	return o.ctx
+}
+
 func (o Options) Signer() sign.Signer {
 	return o.signer
 }
@@ -47,6 +55,16 @@ func (o Options) GetReceiverOptions() (interface{}, error) {
 
 type Option func(*Options) error
 
+// WithContext sets context of request, which carries deadline and trace
+func WithContext(ctx context.Context) Option {
+	return func(o *Options) error {
+		if ctx != nil {
+			o.ctx = ctx
+		}
+		return nil
+	}
+}
+
 func WithSigner(signer sign.Signer) Option {
 	return func(o *Options) error {
 		o.signer = signer
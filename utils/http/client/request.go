@@ -9,6 +9,7 @@ import (
 
 	"github.com/xscaling/wing/utils/http/client/encoding"
 	"github.com/xscaling/wing/utils/http/client/sign"
+	"github.com/xscaling/wing/utils/tracing"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -31,6 +32,7 @@ func NewRequester(encoding encoding.Encoding, timeout time.Duration) *Requester
 		SetTimeout(timeout).
 		OnBeforeRequest(requester.onBeforeRequest).
 		SetPreRequestHook(requester.preRequestHook)
+	client.SetTransport(tracing.NewTransport(client.GetClient().Transport))
 	requester.client = client
 	return requester
 }
@@ -60,7 +62,7 @@ func (r Requester) preRequestHook(_ *resty.Client, request *http.Request) error
 	return nil
 }
 
-func (r Requester) do(
+func (r Requester) do(ctx context.Context,
 	signer sign.Signer, method, endpoint string, requestBody interface{},
 	headers map[string]string, resourceFormat string, query url.Values,
 ) (*resty.Response, error) {
@@ -68,7 +70,7 @@ func (r Requester) do(
 	// As using dynamic signer potentially, we need to set preRequestHook every request to avoid polluting Requester
 	request := r.client.R()
 	request.SetHeaders(headers).
-		SetContext(context.WithValue(request.Context(), requestSignerContext{}, signer))
+		SetContext(context.WithValue(ctx, requestSignerContext{}, signer))
 	if requestBody != nil {
 		// Though we can use auto marshal by resty(but only supports JSON and XML),
 		// considering extension ability decide to manually marshal
@@ -39,7 +39,7 @@ const (
 	metricServerDefaultMetricWindow = time.Minute
 )
 
-func NewRESTMetricsClient(resourceClient resourceclient.PodMetricsesGetter, customClient customclient.CustomMetricsClient, externalClient externalclient.ExternalMetricsClient) MetricsClient {
+func NewRESTMetricsClient(resourceClient resourceclient.MetricsV1beta1Interface, customClient customclient.CustomMetricsClient, externalClient externalclient.ExternalMetricsClient) MetricsClient {
 	return &restMetricsClient{
 		&resourceMetricsClient{resourceClient},
 		&customMetricsClient{customClient},
@@ -59,7 +59,16 @@ type restMetricsClient struct {
 // resourceMetricsClient implements the resource-metrics-related parts of MetricsClient,
 // using data from the resource metrics API.
 type resourceMetricsClient struct {
-	client resourceclient.PodMetricsesGetter
+	client resourceclient.MetricsV1beta1Interface
+}
+
+// CheckResourceMetricsAvailable checks resource metrics API by discovery, which fails when metrics server is down
+func (c *resourceMetricsClient) CheckResourceMetricsAvailable(ctx context.Context) error {
+	err := c.client.RESTClient().Get().AbsPath("/apis", metricsapi.SchemeGroupVersion.String()).Do(ctx).Error()
+	if err != nil {
+		return fmt.Errorf("resource metrics API is unavailable: %v", err)
+	}
+	return nil
 }
 
 // GetResourceMetric gets the given resource metric (and an associated oldest timestamp)
@@ -54,4 +54,7 @@ type MetricsClient interface {
 	// GetExternalMetric gets all the values of a given external metric
 	// that match the specified selector.
 	GetExternalMetric(metricName string, namespace string, selector labels.Selector) ([]int64, time.Time, error)
+
+	// CheckResourceMetricsAvailable checks whether the resource metrics API is served
+	CheckResourceMetricsAvailable(ctx context.Context) error
 }
@@ -2,11 +2,13 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	wingv1 "github.com/xscaling/wing/api/v1"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -55,3 +57,35 @@ func TestShouldEnterPanicMode(t *testing.T) {
 			"shouldEnterPanicMode(%d, %d, %v, %v)", testCase.currentReplicas, testCase.desiredReplicas, testCase.panicThreshold, testCase.panicWindowSeconds)
 	}
 }
+
+func TestStillInPanicMode(t *testing.T) {
+	enteredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	strategy := &wingv1.ReplicaAutoscalerStrategy{
+		PanicThreshold:     resource.NewMilliQuantity(1200, resource.DecimalSI),
+		PanicWindowSeconds: pointer.Int32(30),
+	}
+	panicStatus := wingv1.ReplicaAutoscalerStatus{
+		Conditions: wingv1.SetConditionAt(nil, wingv1.Condition{
+			Type:   wingv1.ConditionPanicMode,
+			Status: metav1.ConditionTrue,
+		}, enteredAt),
+	}
+	for _, testCase := range []struct {
+		name     string
+		now      time.Time
+		status   wingv1.ReplicaAutoscalerStatus
+		strategy *wingv1.ReplicaAutoscalerStrategy
+
+		stillInPanicMode bool
+	}{
+		{"without strategy", enteredAt, panicStatus, nil, false},
+		{"never entered", enteredAt, wingv1.ReplicaAutoscalerStatus{}, strategy, false},
+		{"just entered", enteredAt, panicStatus, strategy, true},
+		{"within window", enteredAt.Add(29 * time.Second), panicStatus, strategy, true},
+		{"window ends", enteredAt.Add(30 * time.Second), panicStatus, strategy, false},
+		{"out of window", enteredAt.Add(time.Hour), panicStatus, strategy, false},
+	} {
+		assert.Equal(t, testCase.stillInPanicMode,
+			StillInPanicMode(testCase.now, testCase.status, testCase.strategy), testCase.name)
+	}
+}
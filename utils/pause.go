@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// GetPausedUntil returns the expiry time of pause annotation, nil means not paused
+func GetPausedUntil(replicaAutoscaler wingv1.ReplicaAutoscaler) (*time.Time, error) {
+	if replicaAutoscaler.Annotations == nil {
+		return nil, nil
+	}
+	rawString, ok := replicaAutoscaler.Annotations[wingv1.PausedUntilAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	until, err := time.Parse(time.RFC3339, rawString)
+	if err != nil {
+		return nil, err
+	}
+	return &until, nil
+}
+
+// GetReplicaOverride returns the replica override in annotation, nil means not overridden
+func GetReplicaOverride(replicaAutoscaler wingv1.ReplicaAutoscaler) (*wingv1.ReplicaOverride, error) {
+	if replicaAutoscaler.Annotations == nil {
+		return nil, nil
+	}
+	rawString, ok := replicaAutoscaler.Annotations[wingv1.OverrideReplicasAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var replicaOverride wingv1.ReplicaOverride
+	err := json.Unmarshal([]byte(rawString), &replicaOverride)
+	if err != nil {
+		return nil, err
+	}
+	if replicaOverride.Replicas < 0 {
+		return nil, fmt.Errorf("override replicas %d is negative", replicaOverride.Replicas)
+	}
+	return &replicaOverride, nil
+}
+
+// PurgeExpiredPauseAnnotations deletes pause and override annotations expired at given time.
+// Broken annotations will be kept and returns error, which doesn't stop purging the other one.
+func PurgeExpiredPauseAnnotations(now time.Time, replicaAutoscaler *wingv1.ReplicaAutoscaler) error {
+	var errs []error
+	pausedUntil, err := GetPausedUntil(*replicaAutoscaler)
+	if err != nil {
+		errs = append(errs, err)
+	} else if pausedUntil != nil && !now.Before(*pausedUntil) {
+		delete(replicaAutoscaler.Annotations, wingv1.PausedUntilAnnotation)
+	}
+
+	replicaOverride, err := GetReplicaOverride(*replicaAutoscaler)
+	if err != nil {
+		errs = append(errs, err)
+	} else if replicaOverride != nil && !now.Before(replicaOverride.Until.Time) {
+		delete(replicaAutoscaler.Annotations, wingv1.OverrideReplicasAnnotation)
+	}
+	return utilerrors.NewAggregate(errs)
+}
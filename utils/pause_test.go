@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPurgeExpiredPauseAnnotations(t *testing.T) {
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for _, testCase := range []struct {
+		description string
+		annotations map[string]string
+		expected    map[string]string
+		causeError  bool
+	}{
+		{
+			description: "no annotations",
+		},
+		{
+			description: "expired pause",
+			annotations: map[string]string{wingv1.PausedUntilAnnotation: past},
+			expected:    map[string]string{},
+		},
+		{
+			description: "working pause",
+			annotations: map[string]string{wingv1.PausedUntilAnnotation: future},
+			expected:    map[string]string{wingv1.PausedUntilAnnotation: future},
+		},
+		{
+			description: "expired override",
+			annotations: map[string]string{
+				wingv1.OverrideReplicasAnnotation: `{"replicas":3,"until":"` + past + `"}`,
+				wingv1.DryRunAnnotation:           "true",
+			},
+			expected: map[string]string{wingv1.DryRunAnnotation: "true"},
+		},
+		{
+			description: "working override",
+			annotations: map[string]string{wingv1.OverrideReplicasAnnotation: `{"replicas":3,"until":"` + future + `"}`},
+			expected:    map[string]string{wingv1.OverrideReplicasAnnotation: `{"replicas":3,"until":"` + future + `"}`},
+		},
+		{
+			description: "broken pause",
+			annotations: map[string]string{wingv1.PausedUntilAnnotation: "tomorrow"},
+			expected:    map[string]string{wingv1.PausedUntilAnnotation: "tomorrow"},
+			causeError:  true,
+		},
+		{
+			description: "broken pause with expired override",
+			annotations: map[string]string{
+				wingv1.PausedUntilAnnotation:      "tomorrow",
+				wingv1.OverrideReplicasAnnotation: `{"replicas":3,"until":"` + past + `"}`,
+			},
+			expected:   map[string]string{wingv1.PausedUntilAnnotation: "tomorrow"},
+			causeError: true,
+		},
+		{
+			description: "negative override",
+			annotations: map[string]string{wingv1.OverrideReplicasAnnotation: `{"replicas":-1,"until":"` + past + `"}`},
+			expected:    map[string]string{wingv1.OverrideReplicasAnnotation: `{"replicas":-1,"until":"` + past + `"}`},
+			causeError:  true,
+		},
+		{
+			description: "expired pause with broken override",
+			annotations: map[string]string{
+				wingv1.PausedUntilAnnotation:      past,
+				wingv1.OverrideReplicasAnnotation: "3",
+			},
+			expected:   map[string]string{wingv1.OverrideReplicasAnnotation: "3"},
+			causeError: true,
+		},
+	} {
+		replicaAutoscaler := &wingv1.ReplicaAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Annotations: testCase.annotations},
+		}
+		err := PurgeExpiredPauseAnnotations(time.Now(), replicaAutoscaler)
+		require.Equal(t, testCase.causeError, err != nil, testCase.description)
+		require.Equal(t, testCase.expected, replicaAutoscaler.Annotations, testCase.description)
+	}
+}
+
+func TestGetReplicaOverride(t *testing.T) {
+	replicaOverride, err := GetReplicaOverride(wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			wingv1.OverrideReplicasAnnotation: `{"replicas":3,"until":"2024-08-15T10:00:00Z"}`,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), replicaOverride.Replicas)
+	require.Equal(t, time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC), replicaOverride.Until.UTC())
+
+	_, err = GetReplicaOverride(wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			wingv1.OverrideReplicasAnnotation: `{"replicas":-3,"until":"2024-08-15T10:00:00Z"}`,
+		}},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStripPod(t *testing.T) {
+	now := metav1.Now()
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "bar",
+			Labels:      map[string]string{"app": "foo"},
+			Annotations: map[string]string{"large": "annotation"},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubelet"},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:      "app",
+					Image:     "app:latest",
+					Env:       []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+					Resources: resources,
+				},
+			},
+			NodeName: "node",
+		},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			StartTime: &now,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+			PodIP: "127.0.0.1",
+		},
+	}
+	object, err := StripPod(pod)
+	require.NoError(t, err)
+	stripped := object.(*corev1.Pod)
+	require.Equal(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "bar",
+			Labels:    map[string]string{"app": "foo"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Resources: resources},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			StartTime: &now,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}, stripped)
+
+	// Non-pod objects are untouched
+	object, err = StripPod("tombstone")
+	require.NoError(t, err)
+	require.Equal(t, "tombstone", object)
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	wingv1 "github.com/xscaling/wing/api/v1"
+	"github.com/xscaling/wing/core/group"
+)
+
+// IsWithinAutoscalerQuota returns true if the given autoscaler is within the earliest created `limit` autoscalers,
+// terminating autoscalers are not counted. Non-positive limit means unlimited.
+func IsWithinAutoscalerQuota(autoscalers []wingv1.ReplicaAutoscaler, autoscaler *wingv1.ReplicaAutoscaler, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+	earlier := 0
+	for i := range autoscalers {
+		candidate := &autoscalers[i]
+		if candidate.DeletionTimestamp != nil || candidate.Name == autoscaler.Name {
+			continue
+		}
+		if candidate.CreationTimestamp.Before(&autoscaler.CreationTimestamp) ||
+			(candidate.CreationTimestamp.Equal(&autoscaler.CreationTimestamp) && candidate.Name < autoscaler.Name) {
+			earlier++
+		}
+	}
+	return earlier < limit
+}
+
+// GetReplicaQuotaBudget returns how many replicas the given autoscaler may hold under namespace replica quota.
+// Replicas of other autoscalers are counted by the larger one of current and desired replicas,
+// together with replicas of their members following it.
+func GetReplicaQuotaBudget(autoscalers []wingv1.ReplicaAutoscaler, autoscaler *wingv1.ReplicaAutoscaler, limit int32) int32 {
+	budget := int64(limit)
+	for i := range autoscalers {
+		candidate := &autoscalers[i]
+		if candidate.DeletionTimestamp != nil || candidate.Name == autoscaler.Name {
+			continue
+		}
+		held := candidate.Status.CurrentReplicas
+		if candidate.Status.DesiredReplicas > held {
+			held = candidate.Status.DesiredReplicas
+		}
+		budget -= group.GetTotalReplicas(held, candidate.Spec.Members)
+	}
+	if budget < 0 {
+		return 0
+	}
+	return int32(budget)
+}
+
+// LimitReplicasByQuota prevents scaling up beyond budget, which is shared with members following replicas,
+// but never forces scaling down existing replicas.
+func LimitReplicasByQuota(currentReplicas, desiredReplicas int32,
+	members []wingv1.ScaleTargetMember, budget int32) (replicas int32, limited bool) {
+	if desiredReplicas <= currentReplicas || group.GetTotalReplicas(desiredReplicas, members) <= int64(budget) {
+		return desiredReplicas, false
+	}
+	replicas, _ = group.LimitReplicasByBudget(desiredReplicas, currentReplicas, members, budget)
+	return replicas, true
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsWithinAutoscalerQuota(t *testing.T) {
+	now := time.Now()
+	newAutoscaler := func(name string, createdAt time.Time, terminating bool) wingv1.ReplicaAutoscaler {
+		autoscaler := wingv1.ReplicaAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(createdAt)},
+		}
+		if terminating {
+			autoscaler.DeletionTimestamp = &autoscaler.CreationTimestamp
+		}
+		return autoscaler
+	}
+	autoscalers := []wingv1.ReplicaAutoscaler{
+		newAutoscaler("a", now.Add(-time.Hour), false),
+		newAutoscaler("b", now.Add(-time.Minute), true),
+		newAutoscaler("c", now, false),
+		newAutoscaler("d", now, false),
+	}
+	for _, testCase := range []struct {
+		name     string
+		limit    int
+		expected bool
+	}{
+		{name: "a", limit: 1, expected: true},
+		{name: "c", limit: 1, expected: false},
+		{name: "c", limit: 2, expected: true},
+		{name: "d", limit: 2, expected: false},
+		{name: "d", limit: 0, expected: true},
+	} {
+		autoscaler := &autoscalers[0]
+		for i := range autoscalers {
+			if autoscalers[i].Name == testCase.name {
+				autoscaler = &autoscalers[i]
+			}
+		}
+		require.Equal(t, testCase.expected, IsWithinAutoscalerQuota(autoscalers, autoscaler, testCase.limit),
+			"%s with limit %d", testCase.name, testCase.limit)
+	}
+}
+
+func TestGetReplicaQuotaBudget(t *testing.T) {
+	autoscalers := []wingv1.ReplicaAutoscaler{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Status:     wingv1.ReplicaAutoscalerStatus{CurrentReplicas: 5, DesiredReplicas: 8},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Status:     wingv1.ReplicaAutoscalerStatus{CurrentReplicas: 6, DesiredReplicas: 3},
+		},
+	}
+	require.Equal(t, int32(6), GetReplicaQuotaBudget(autoscalers, &autoscalers[1], 14))
+	require.Equal(t, int32(6), GetReplicaQuotaBudget(autoscalers, &autoscalers[0], 12))
+	require.Equal(t, int32(0), GetReplicaQuotaBudget(autoscalers, &autoscalers[0], 4))
+
+	// Members following 8 replicas of a by ratio 3 hold 24 more replicas
+	autoscalers[0].Spec.Members = []wingv1.ScaleTargetMember{{
+		ScaleTargetRef: wingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "worker"},
+		Ratio:          resource.MustParse("3"),
+	}}
+	require.Equal(t, int32(6), GetReplicaQuotaBudget(autoscalers, &autoscalers[1], 38))
+	require.Equal(t, int32(0), GetReplicaQuotaBudget(autoscalers, &autoscalers[1], 14))
+}
+
+func TestLimitReplicasByQuota(t *testing.T) {
+	members := []wingv1.ScaleTargetMember{{
+		ScaleTargetRef: wingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "worker"},
+		Ratio:          resource.MustParse("3"),
+	}}
+	for _, testCase := range []struct {
+		description     string
+		current         int32
+		desired         int32
+		members         []wingv1.ScaleTargetMember
+		budget          int32
+		expected        int32
+		expectedLimited bool
+	}{
+		{description: "within budget", current: 3, desired: 5, budget: 10, expected: 5},
+		{description: "scale down is never limited", current: 8, desired: 5, budget: 2, expected: 5},
+		{description: "limit scaling up", current: 3, desired: 12, budget: 10, expected: 10, expectedLimited: true},
+		{description: "keep current replicas over budget", current: 6, desired: 8, budget: 4, expected: 6, expectedLimited: true},
+		{description: "members share budget", current: 1, desired: 5, members: members, budget: 10, expected: 2,
+			expectedLimited: true},
+		{description: "members within budget", current: 1, desired: 2, members: members, budget: 10, expected: 2},
+	} {
+		replicas, limited := LimitReplicasByQuota(testCase.current, testCase.desired, testCase.members, testCase.budget)
+		require.Equal(t, testCase.expected, replicas, testCase.description)
+		require.Equal(t, testCase.expectedLimited, limited, testCase.description)
+	}
+}
@@ -24,7 +24,30 @@ func GetReplicaPatches(replicaAutoscaler wingv1.ReplicaAutoscaler) (wingv1.Repli
 	return replicaPatches, nil
 }
 
-func PurgeUnusedReplicaPatches(replicaAutoscaler *wingv1.ReplicaAutoscaler) error {
+// GetReplicaPatchExpireTime returns the time date replica patch will be purged at, which is end time plus
+// scale-down delay and retention.
+// Cron or broken replica patch never expires and returns false.
+func GetReplicaPatchExpireTime(patch wingv1.ReplicaPatch) (time.Time, bool) {
+	timezone, err := time.LoadLocation(patch.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	dateScheduler, err := timerange.NewDateScheduler(timezone, patch.Start, patch.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expireTime := dateScheduler.GetEndTime()
+	if patch.ScaleDownDelay != nil {
+		expireTime = expireTime.Add(patch.ScaleDownDelay.Duration)
+	}
+	if patch.RetentionSeconds != nil {
+		expireTime = expireTime.Add(time.Duration(*patch.RetentionSeconds) * time.Second)
+	}
+	return expireTime, true
+}
+
+// PurgeUnusedReplicaPatches deletes date replica patches expired at given time with retention
+func PurgeUnusedReplicaPatches(now time.Time, replicaAutoscaler *wingv1.ReplicaAutoscaler) error {
 	patches, err := GetReplicaPatches(*replicaAutoscaler)
 	if err != nil {
 		return err
@@ -34,19 +57,9 @@ func PurgeUnusedReplicaPatches(replicaAutoscaler *wingv1.ReplicaAutoscaler) erro
 	}
 	var newPatches wingv1.ReplicaPatches
 	for _, patch := range patches {
-		timezone, err := time.LoadLocation(patch.Timezone)
-		if err == nil {
-			dateScheduler, err := timerange.NewDateScheduler(timezone, patch.Start, patch.End)
-			if err == nil {
-				expiredTime := dateScheduler.GetEndTime()
-				if patch.RetentionSeconds != nil {
-					expiredTime = expiredTime.Add(time.Duration(*patch.RetentionSeconds) * time.Second)
-				}
-				// Delete it
-				if time.Now().After(expiredTime) {
-					continue
-				}
-			}
+		// Delete it
+		if expireTime, ok := GetReplicaPatchExpireTime(patch); ok && now.After(expireTime) {
+			continue
 		}
 		newPatches = append(newPatches, patch)
 	}
@@ -54,14 +67,22 @@ func PurgeUnusedReplicaPatches(replicaAutoscaler *wingv1.ReplicaAutoscaler) erro
 		// nothing to update
 		return nil
 	}
-	if len(newPatches) == 0 {
+	return SetReplicaPatches(replicaAutoscaler, newPatches)
+}
+
+// SetReplicaPatches stores replica patches into annotation, annotation will be deleted if no patch left
+func SetReplicaPatches(replicaAutoscaler *wingv1.ReplicaAutoscaler, patches wingv1.ReplicaPatches) error {
+	if len(patches) == 0 {
 		delete(replicaAutoscaler.Annotations, wingv1.ReplicaPatchesAnnotation)
-	} else {
-		newPatchesString, err := json.Marshal(newPatches)
-		if err != nil {
-			return err
-		}
-		replicaAutoscaler.Annotations[wingv1.ReplicaPatchesAnnotation] = string(newPatchesString)
+		return nil
+	}
+	patchesString, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+	if replicaAutoscaler.Annotations == nil {
+		replicaAutoscaler.Annotations = make(map[string]string)
 	}
+	replicaAutoscaler.Annotations[wingv1.ReplicaPatchesAnnotation] = string(patchesString)
 	return nil
 }
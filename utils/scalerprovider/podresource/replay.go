@@ -0,0 +1,63 @@
+package podresource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xscaling/wing/utils/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	replayPodRequestMilliValue = 1000
+)
+
+// CalculateDesiredReplicasByUtilization calculates desired replicas as if all current pods are ready
+// and working on the same average utilization, it's used to replay recorded utilization.
+func CalculateDesiredReplicasByUtilization(utilizationToleration float64, resourceName corev1.ResourceName,
+	currentReplicas, averageUtilization, targetUtilization int32) (int32, error) {
+	if currentReplicas == 0 {
+		return 0, nil
+	}
+	var (
+		// Started long ago to skip initialization period
+		startTime       = metav1.NewTime(time.Unix(0, 0))
+		podList         = make([]*corev1.Pod, 0, currentReplicas)
+		resourceMetrics = make(metrics.PodMetricsInfo, currentReplicas)
+	)
+	for i := int32(0); i < currentReplicas; i++ {
+		name := fmt.Sprintf("replay-%d", i)
+		podList = append(podList, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							resourceName: *resource.NewMilliQuantity(replayPodRequestMilliValue, resource.DecimalSI),
+						},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase:     corev1.PodRunning,
+				StartTime: &startTime,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: startTime,
+				}},
+			},
+		})
+		resourceMetrics[name] = metrics.PodMetric{
+			Timestamp: time.Now(),
+			Window:    time.Minute,
+			Value:     int64(averageUtilization) * replayPodRequestMilliValue / 100,
+		}
+	}
+	desiredReplicas, _, _, err := tidyAndCalculateDesiredReplicas(utilizationToleration,
+		resourceMetrics, podList, resourceName, "", targetUtilization, currentReplicas)
+	return desiredReplicas, err
+}
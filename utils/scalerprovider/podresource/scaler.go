@@ -25,7 +25,10 @@ type scaler struct {
 	pluginName              string
 }
 
-var _ engine.Scaler = &scaler{}
+var (
+	_ engine.Scaler        = &scaler{}
+	_ engine.HealthChecker = &scaler{}
+)
 
 type Config struct {
 	UtilizationToleration float64 `yaml:"utilizationToleration"`
@@ -65,6 +68,11 @@ func New(pluginName string, config Config, resource corev1.ResourceName, kuberne
 	}, nil
 }
 
+// CheckHealth implements engine.HealthChecker
+func (s *scaler) CheckHealth(ctx context.Context) error {
+	return s.kubernetesMetricsClient.CheckResourceMetricsAvailable(ctx)
+}
+
 func (s *scaler) Get(ctx engine.ScalerContext) (*engine.ScalerOutput, error) {
 	settings := new(Settings)
 	if err := ctx.LoadSettings(settings); err != nil {
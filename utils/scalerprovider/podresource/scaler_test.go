@@ -580,3 +580,31 @@ func TestTidyAndCalculateDesiredReplicas(t *testing.T) {
 		assert.Equal(t, testCase.expectedRawAverageValue, rawAverageValue, "case %d", index)
 	}
 }
+
+func TestCalculateDesiredReplicasByUtilization(t *testing.T) {
+	for index, testCase := range []struct {
+		resource           corev1.ResourceName
+		currentReplicas    int32
+		averageUtilization int32
+		targetUtilization  int32
+
+		expectedDesiredReplica int32
+	}{
+		// no pod to calculate
+		{corev1.ResourceCPU, 0, 100, 50, 0},
+		// scale up
+		{corev1.ResourceCPU, 2, 100, 50, 4},
+		{corev1.ResourceMemory, 3, 90, 60, 5},
+		// scale down
+		{corev1.ResourceCPU, 4, 20, 80, 1},
+		// within toleration
+		{corev1.ResourceCPU, 10, 52, 50, 10},
+	} {
+		desiredReplica, err := CalculateDesiredReplicasByUtilization(DefaultUtilizationToleration,
+			testCase.resource, testCase.currentReplicas, testCase.averageUtilization, testCase.targetUtilization)
+		if !assert.NoError(t, err, "case %d", index) {
+			continue
+		}
+		assert.Equal(t, testCase.expectedDesiredReplica, desiredReplica, "case %d", index)
+	}
+}
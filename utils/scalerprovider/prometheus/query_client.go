@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/xscaling/wing/utils"
+	"github.com/xscaling/wing/utils/tracing"
 
 	"github.com/prometheus/common/model"
 )
@@ -41,7 +43,7 @@ type VectorData struct {
 }
 
 type QueryClient interface {
-	Query(server Server, query string, when time.Time) (float64, error)
+	Query(ctx context.Context, server Server, query string, when time.Time) (float64, error)
 }
 
 type promQueryClient struct {
@@ -53,23 +55,24 @@ func NewQueryClient(timeout time.Duration) *promQueryClient {
 	client := &promQueryClient{
 		insecureHTTPClient: &http.Client{
 			Timeout: timeout,
-			Transport: &http.Transport{
+			Transport: tracing.NewTransport(&http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
 				},
-			},
+			}),
 		},
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.NewTransport(http.DefaultTransport),
 		},
 	}
 	return client
 }
 
-func (c *promQueryClient) Query(server Server, query string, when time.Time) (float64, error) {
+func (c *promQueryClient) Query(ctx context.Context, server Server, query string, when time.Time) (float64, error) {
 	queryEscaped := url.QueryEscape(query)
 	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%d", *server.ServerAddress, queryEscaped, when.Unix())
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return -1, err
 	}
@@ -2,6 +2,7 @@ package prometheus
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -57,7 +58,14 @@ type scaler struct {
 	queryClient QueryClient
 }
 
-var _ engine.Scaler = &scaler{}
+var (
+	_ engine.Scaler        = &scaler{}
+	_ engine.HealthChecker = &scaler{}
+)
+
+const (
+	healthCheckQuery = "vector(1)"
+)
 
 type Server struct {
 	// Left empty to use the default prometheus server
@@ -108,16 +116,28 @@ func (s *Settings) Validate() error {
 }
 
 func New(pluginName string, config ScalerConfig) (*scaler, error) {
+	return NewWithQueryClient(pluginName, config, NewQueryClient(config.DefaultTimeout))
+}
+
+// NewWithQueryClient returns scaler querying metrics by given client, e.g. replaying recorded metrics
+func NewWithQueryClient(pluginName string, config ScalerConfig, queryClient QueryClient) (*scaler, error) {
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 	return &scaler{
 		pluginName:  pluginName,
 		config:      config,
-		queryClient: NewQueryClient(config.DefaultTimeout),
+		queryClient: queryClient,
 	}, nil
 }
 
+// CheckHealth implements engine.HealthChecker by querying a constant from default server,
+// servers specified by autoscalers are not checked.
+func (s *scaler) CheckHealth(ctx context.Context) error {
+	_, err := s.queryClient.Query(ctx, s.config.DefaultServer, healthCheckQuery, time.Now())
+	return err
+}
+
 func (s *scaler) Get(ctx engine.ScalerContext) (*engine.ScalerOutput, error) {
 	settings := new(Settings)
 	if err := ctx.LoadSettings(settings); err != nil {
@@ -150,7 +170,7 @@ func (s *scaler) CalculateDesiredReplicas(ctx engine.ScalerContext, settings *Se
 
 	targetStatusName := s.makeTargetStatusName(settings.Query)
 
-	value, err := s.queryClient.Query(provisionServer, settings.Query, time.Now())
+	value, err := s.queryClient.Query(ctx.GetContext(), provisionServer, settings.Query, ctx.Now())
 	if err != nil {
 		// To avoid override status and doing nonsense update
 		shouldUpdateAverageValue = false
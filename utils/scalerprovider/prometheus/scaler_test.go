@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,7 +22,7 @@ type fakeQueryClient struct {
 	err         error
 }
 
-func (f *fakeQueryClient) Query(server Server, query string, when time.Time) (float64, error) {
+func (f *fakeQueryClient) Query(_ context.Context, server Server, query string, when time.Time) (float64, error) {
 	return f.metricValue, f.err
 }
 
@@ -298,3 +299,22 @@ func TestScalerFailAsZero(t *testing.T) {
 	_, err = testScaler.Get(ctx)
 	require.NotNil(t, err)
 }
+
+func TestScalerCheckHealth(t *testing.T) {
+	testScaler, err := New("test", ScalerConfig{
+		Toleration:     0.1,
+		DefaultTimeout: 10 & time.Second,
+		DefaultServer: Server{
+			ServerAddress: pointer.String("https://prometheus.example.com"),
+		},
+	})
+	require.NoError(t, err)
+	fakeQueryClient := &fakeQueryClient{
+		metricValue: 1,
+	}
+	testScaler.queryClient = fakeQueryClient
+	require.NoError(t, testScaler.CheckHealth(context.TODO()))
+
+	fakeQueryClient.err = errors.New("connection refused")
+	require.Error(t, testScaler.CheckHealth(context.TODO()))
+}
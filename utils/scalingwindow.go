@@ -0,0 +1,29 @@
+package utils
+
+import (
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// IsScalingWindowSelecting returns whether the window selects autoscaler by name or labels
+func IsScalingWindowSelecting(window wingv1.ScalingWindow, autoscaler wingv1.ReplicaAutoscaler) (bool, error) {
+	if window.Namespace != autoscaler.Namespace {
+		return false, nil
+	}
+	for _, name := range window.Spec.AutoscalerNames {
+		if name == autoscaler.Name {
+			return true, nil
+		}
+	}
+	if window.Spec.AutoscalerSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(window.Spec.AutoscalerSelector)
+	if err != nil {
+		return false, err
+	}
+	// Empty selector selects nothing rather than everything to avoid patching whole namespace by mistake
+	return !selector.Empty() && selector.Matches(labels.Set(autoscaler.Labels)), nil
+}
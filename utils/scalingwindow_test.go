@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestIsScalingWindowSelecting(t *testing.T) {
+	autoscaler := wingv1.ReplicaAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Labels: map[string]string{"tier": "frontend"}},
+	}
+	for _, testCase := range []struct {
+		description string
+		namespace   string
+		spec        wingv1.ScalingWindowSpec
+		expected    bool
+		causeError  bool
+	}{
+		{
+			description: "select by name",
+			spec:        wingv1.ScalingWindowSpec{AutoscalerNames: []string{"api", "web"}},
+			expected:    true,
+		},
+		{
+			description: "select by labels",
+			spec: wingv1.ScalingWindowSpec{AutoscalerSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "frontend"},
+			}},
+			expected: true,
+		},
+		{
+			description: "labels not match",
+			spec: wingv1.ScalingWindowSpec{AutoscalerSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "backend"},
+			}},
+		},
+		{
+			description: "empty selector selects nothing",
+			spec:        wingv1.ScalingWindowSpec{AutoscalerSelector: &metav1.LabelSelector{}},
+		},
+		{
+			description: "other namespace",
+			namespace:   "other",
+			spec:        wingv1.ScalingWindowSpec{AutoscalerNames: []string{"web"}},
+		},
+		{
+			description: "invalid selector",
+			spec: wingv1.ScalingWindowSpec{AutoscalerSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Unknown"}},
+			}},
+			causeError: true,
+		},
+	} {
+		namespace := testCase.namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		window := wingv1.ScalingWindow{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "window"},
+			Spec:       testCase.spec,
+		}
+		selected, err := IsScalingWindowSelecting(window, autoscaler)
+		if testCase.causeError {
+			require.Error(t, err, testCase.description)
+			continue
+		}
+		require.NoError(t, err, testCase.description)
+		require.Equal(t, testCase.expected, selected, testCase.description)
+	}
+}
+
+func TestGetReplicaPatchExpireTime(t *testing.T) {
+	expireTime, ok := GetReplicaPatchExpireTime(wingv1.ReplicaPatch{
+		Timezone: "Asia/Shanghai", Start: "2024-08-15 08:00", End: "2024-08-15 10:00", RetentionSeconds: pointer.Int64(60),
+	})
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 8, 15, 2, 1, 0, 0, time.UTC), expireTime.UTC())
+
+	// Cron patches never expire
+	_, ok = GetReplicaPatchExpireTime(wingv1.ReplicaPatch{Timezone: "UTC", Start: "0 8 * * *", End: "0 10 * * *"})
+	require.False(t, ok)
+}
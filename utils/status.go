@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"time"
+
 	wingv1 "github.com/xscaling/wing/api/v1"
 )
 
@@ -34,3 +36,21 @@ func PurgeTargetStatus(managed []string, status *wingv1.ReplicaAutoscalerStatus)
 		}
 	}
 }
+
+// AddScalingHistory prepends the record to scaling history and purges records
+// over limit or older than retention(zero means no retention limit).
+func AddScalingHistory(status *wingv1.ReplicaAutoscalerStatus, record wingv1.ScalingRecord,
+	limit int, retention time.Duration) {
+	history := make([]wingv1.ScalingRecord, 0, len(status.ScalingHistory)+1)
+	history = append(history, record)
+	for _, historicalRecord := range status.ScalingHistory {
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+		if retention > 0 && record.Time.Sub(historicalRecord.Time.Time) > retention {
+			break
+		}
+		history = append(history, historicalRecord)
+	}
+	status.ScalingHistory = history
+}
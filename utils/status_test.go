@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	wingv1 "github.com/xscaling/wing/api/v1"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddScalingHistory(t *testing.T) {
+	now := time.Now()
+	newRecord := func(offset time.Duration, to int32) wingv1.ScalingRecord {
+		return wingv1.ScalingRecord{
+			Time:       metav1.NewTime(now.Add(offset)),
+			ToReplicas: to,
+		}
+	}
+	for _, testCase := range []struct {
+		description string
+		history     []wingv1.ScalingRecord
+		limit       int
+		retention   time.Duration
+		expected    []int32
+	}{
+		{
+			description: "empty history",
+			limit:       3,
+			expected:    []int32{0},
+		},
+		{
+			description: "newest first",
+			history:     []wingv1.ScalingRecord{newRecord(-time.Minute, 1), newRecord(-time.Hour, 2)},
+			limit:       3,
+			expected:    []int32{0, 1, 2},
+		},
+		{
+			description: "over limit",
+			history:     []wingv1.ScalingRecord{newRecord(-time.Minute, 1), newRecord(-time.Hour, 2)},
+			limit:       2,
+			expected:    []int32{0, 1},
+		},
+		{
+			description: "out of retention",
+			history:     []wingv1.ScalingRecord{newRecord(-time.Minute, 1), newRecord(-time.Hour, 2)},
+			limit:       3,
+			retention:   time.Minute * 30,
+			expected:    []int32{0, 1},
+		},
+		{
+			description: "no limit",
+			history:     []wingv1.ScalingRecord{newRecord(-time.Minute, 1), newRecord(-time.Hour, 2)},
+			expected:    []int32{0, 1, 2},
+		},
+	} {
+		status := &wingv1.ReplicaAutoscalerStatus{ScalingHistory: testCase.history}
+		AddScalingHistory(status, newRecord(0, 0), testCase.limit, testCase.retention)
+		var got []int32
+		for _, record := range status.ScalingHistory {
+			got = append(got, record.ToReplicas)
+		}
+		require.Equal(t, testCase.expected, got, testCase.description)
+	}
+}
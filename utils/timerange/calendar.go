@@ -0,0 +1,281 @@
+package timerange
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCalendarDate             = errors.New("invalid calendar date, accepts `2006-01-02` format")
+	ErrInvalidICalendar                = errors.New("invalid iCalendar")
+	ErrICalendarRecurrenceNotSupported = errors.New("iCalendar recurrence rule is not supported")
+)
+
+const (
+	CalendarDateFormat = "2006-01-02"
+
+	iCalendarDateFormat     = "20060102"
+	iCalendarDateTimeFormat = "20060102T150405"
+)
+
+// Calendar is a set of periods such as public holidays or sales events
+type Calendar struct {
+	periods []calendarPeriod
+}
+
+// calendarPeriod is [start, end)
+type calendarPeriod struct {
+	start time.Time
+	end   time.Time
+}
+
+func (c *Calendar) Contains(when time.Time) bool {
+	for _, period := range c.periods {
+		if !when.Before(period.start) && when.Before(period.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNextTransition returns the upcoming start or end of periods not before when, zero time means none
+func (c *Calendar) GetNextTransition(when time.Time) time.Time {
+	var next time.Time
+	for _, period := range c.periods {
+		switch {
+		case when.Before(period.start):
+			next = earliestTransition(next, period.start)
+		case when.Before(period.end):
+			next = earliestTransition(next, period.end)
+		}
+	}
+	return next
+}
+
+// Merge returns calendar containing periods of both calendars
+func (c *Calendar) Merge(other *Calendar) *Calendar {
+	merged := &Calendar{periods: make([]calendarPeriod, 0, len(c.periods)+len(other.periods))}
+	merged.periods = append(merged.periods, c.periods...)
+	merged.periods = append(merged.periods, other.periods...)
+	return merged
+}
+
+// ParseCalendarDates returns calendar of whole days in timezone
+func ParseCalendarDates(timezone *time.Location, dates []string) (*Calendar, error) {
+	c := &Calendar{periods: make([]calendarPeriod, 0, len(dates))}
+	for _, date := range dates {
+		start, err := time.ParseInLocation(CalendarDateFormat, strings.TrimSpace(date), timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCalendarDate, date)
+		}
+		c.periods = append(c.periods, calendarPeriod{start: start, end: start.AddDate(0, 0, 1)})
+	}
+	return c, nil
+}
+
+// ParseICalendar returns calendar of events in iCalendar(RFC 5545) payload.
+// Floating times and dates are taken in timezone, cancelled events are ignored
+// and recurring events are rejected.
+func ParseICalendar(timezone *time.Location, payload string) (*Calendar, error) {
+	c := &Calendar{}
+	var event map[string]iCalendarProperty
+	for _, line := range unfoldICalendarLines(payload) {
+		property, err := parseICalendarProperty(line)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case property.name == "BEGIN" && property.value == "VEVENT":
+			event = make(map[string]iCalendarProperty)
+		case property.name == "END" && property.value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("%w: unexpected END:VEVENT", ErrInvalidICalendar)
+			}
+			period, ok, err := getICalendarEventPeriod(timezone, event)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				c.periods = append(c.periods, period)
+			}
+			event = nil
+		case event != nil:
+			event[property.name] = property
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("%w: VEVENT not ended", ErrInvalidICalendar)
+	}
+	return c, nil
+}
+
+type iCalendarProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfoldICalendarLines joins folded lines which start with white space
+func unfoldICalendarLines(payload string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(payload))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func parseICalendarProperty(line string) (iCalendarProperty, error) {
+	separator := strings.Index(line, ":")
+	if separator < 0 {
+		return iCalendarProperty{}, fmt.Errorf("%w: malformed line `%s`", ErrInvalidICalendar, line)
+	}
+	fields := strings.Split(line[:separator], ";")
+	property := iCalendarProperty{
+		name:   strings.ToUpper(fields[0]),
+		params: make(map[string]string, len(fields)-1),
+		value:  line[separator+1:],
+	}
+	for _, param := range fields[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			property.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return property, nil
+}
+
+func getICalendarEventPeriod(timezone *time.Location,
+	event map[string]iCalendarProperty) (period calendarPeriod, ok bool, err error) {
+	if status, found := event["STATUS"]; found && strings.EqualFold(status.value, "CANCELLED") {
+		return period, false, nil
+	}
+	if _, found := event["RRULE"]; found {
+		return period, false, fmt.Errorf("%w: event %s", ErrICalendarRecurrenceNotSupported, event["SUMMARY"].value)
+	}
+	start, found := event["DTSTART"]
+	if !found {
+		return period, false, fmt.Errorf("%w: DTSTART not found in event %s", ErrInvalidICalendar, event["SUMMARY"].value)
+	}
+	var startIsDate bool
+	if period.start, startIsDate, err = parseICalendarTime(timezone, start); err != nil {
+		return period, false, err
+	}
+	if end, found := event["DTEND"]; found {
+		if period.end, _, err = parseICalendarTime(timezone, end); err != nil {
+			return period, false, err
+		}
+	} else if duration, found := event["DURATION"]; found {
+		if period.end, err = addICalendarDuration(period.start, duration.value); err != nil {
+			return period, false, err
+		}
+	} else if startIsDate {
+		// An all-day event lasts for the day by default
+		period.end = period.start.AddDate(0, 0, 1)
+	} else {
+		return period, false, fmt.Errorf("%w: DTEND not found in event %s", ErrInvalidICalendar, event["SUMMARY"].value)
+	}
+	if !period.start.Before(period.end) {
+		return period, false, fmt.Errorf("%w: event %s ends before start", ErrInvalidICalendar, event["SUMMARY"].value)
+	}
+	return period, true, nil
+}
+
+func parseICalendarTime(timezone *time.Location, property iCalendarProperty) (when time.Time, isDate bool, err error) {
+	if tzid, found := property.params["TZID"]; found {
+		if timezone, err = time.LoadLocation(tzid); err != nil {
+			return when, false, fmt.Errorf("%w: unknown TZID %s", ErrInvalidICalendar, tzid)
+		}
+	}
+	value := property.value
+	switch {
+	case property.params["VALUE"] == "DATE" || len(value) == len(iCalendarDateFormat):
+		when, err = time.ParseInLocation(iCalendarDateFormat, value, timezone)
+		isDate = true
+	case strings.HasSuffix(value, "Z"):
+		when, err = time.ParseInLocation(iCalendarDateTimeFormat, strings.TrimSuffix(value, "Z"), time.UTC)
+	default:
+		when, err = time.ParseInLocation(iCalendarDateTimeFormat, value, timezone)
+	}
+	if err != nil {
+		return when, false, fmt.Errorf("%w: invalid %s `%s`", ErrInvalidICalendar, property.name, value)
+	}
+	return when, isDate, nil
+}
+
+var iCalendarDurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// addICalendarDuration adds positive duration like `P1D` or `PT2H30M` to start,
+// days are nominal so that they follow wall clock across DST transitions.
+func addICalendarDuration(start time.Time, value string) (time.Time, error) {
+	matches := iCalendarDurationPattern.FindStringSubmatch(strings.TrimPrefix(value, "+"))
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return start, fmt.Errorf("%w: invalid DURATION `%s`", ErrInvalidICalendar, value)
+	}
+	var parts [5]int
+	for i, match := range matches[1:] {
+		if match != "" {
+			parts[i], _ = strconv.Atoi(match)
+		}
+	}
+	end := start.AddDate(0, 0, parts[0]*7+parts[1])
+	return end.Add(time.Duration(parts[2])*time.Hour +
+		time.Duration(parts[3])*time.Minute + time.Duration(parts[4])*time.Second), nil
+}
+
+// CalendarScheduler narrows period of scheduler by calendar, the period works only within calendar
+// when including, or only out of calendar when excluding.
+// Without scheduler the calendar itself is the period.
+type CalendarScheduler struct {
+	baseScheduler
+	scheduler Scheduler
+	calendar  *Calendar
+	exclude   bool
+}
+
+func NewCalendarScheduler(timezone *time.Location, scheduler Scheduler,
+	calendar *Calendar, exclude bool) *CalendarScheduler {
+	s := &CalendarScheduler{
+		baseScheduler: baseScheduler{
+			timezone: timezone,
+		},
+		scheduler: scheduler,
+		calendar:  calendar,
+		exclude:   exclude,
+	}
+	if scheduler != nil {
+		s.rawStart, s.rawEnd = scheduler.GetStart(), scheduler.GetEnd()
+	}
+	return s
+}
+
+func (s *CalendarScheduler) Contains(when time.Time) bool {
+	if s.calendar.Contains(when) == s.exclude {
+		return false
+	}
+	return s.scheduler == nil || s.scheduler.Contains(when)
+}
+
+func (s *CalendarScheduler) GetNextTransition(when time.Time) time.Time {
+	next := s.calendar.GetNextTransition(when)
+	if s.scheduler != nil {
+		next = earliestTransition(next, s.scheduler.GetNextTransition(when))
+	}
+	return next
+}
+
+// GetScheduler returns the narrowed scheduler, nil means calendar only
+func (s *CalendarScheduler) GetScheduler() Scheduler {
+	return s.scheduler
+}
@@ -0,0 +1,176 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testICalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//xScaling//Wing//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:National Day\r\n" +
+	"DTSTART;VALUE=DATE:20241001\r\n" +
+	"DTEND;VALUE=DATE:20241008\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Singles Day\r\n" +
+	"  sale\r\n" +
+	"DTSTART;TZID=Asia/Shanghai:20241110T200000\r\n" +
+	"DURATION:PT6H\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Mid-Autumn Festival\r\n" +
+	"DTSTART;VALUE=DATE:20240917\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Flash sale\r\n" +
+	"DTSTART:20240815T020000Z\r\n" +
+	"DTEND:20240815T030000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Cancelled sale\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20240816T020000Z\r\n" +
+	"DTEND:20240816T030000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICalendar(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	calendar, err := ParseICalendar(shanghai, testICalendar)
+	require.NoError(t, err)
+	for when, expected := range map[time.Time]bool{
+		time.Date(2024, 9, 30, 23, 59, 0, 0, shanghai):  false,
+		time.Date(2024, 10, 1, 0, 0, 0, 0, shanghai):    true,
+		time.Date(2024, 10, 7, 23, 59, 0, 0, shanghai):  true,
+		time.Date(2024, 10, 8, 0, 0, 0, 0, shanghai):    false,
+		time.Date(2024, 11, 10, 19, 59, 0, 0, shanghai): false,
+		time.Date(2024, 11, 11, 1, 59, 0, 0, shanghai):  true,
+		time.Date(2024, 11, 11, 2, 0, 0, 0, shanghai):   false,
+		time.Date(2024, 9, 17, 12, 0, 0, 0, shanghai):   true, // All-day event without end
+		time.Date(2024, 9, 18, 0, 0, 0, 0, shanghai):    false,
+		time.Date(2024, 8, 15, 10, 30, 0, 0, shanghai):  true, // 02:30 UTC
+		time.Date(2024, 8, 16, 10, 30, 0, 0, shanghai):  false,
+	} {
+		require.Equal(t, expected, calendar.Contains(when), "when: %s", when)
+	}
+
+	for _, testCase := range []struct {
+		payload    string
+		causeError error
+	}{
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20241001\nRRULE:FREQ=YEARLY\nEND:VEVENT",
+			causeError: ErrICalendarRecurrenceNotSupported,
+		},
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART:20241001T100000\nEND:VEVENT",
+			causeError: ErrInvalidICalendar,
+		},
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART:20241001T100000\nDTEND:20241001T090000\nEND:VEVENT",
+			causeError: ErrInvalidICalendar,
+		},
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART;TZID=Mars/Base:20241001T100000\nDURATION:PT1H\nEND:VEVENT",
+			causeError: ErrInvalidICalendar,
+		},
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART:20241001T100000\nDURATION:1H\nEND:VEVENT",
+			causeError: ErrInvalidICalendar,
+		},
+		{
+			payload:    "BEGIN:VEVENT\nDTSTART:20241001T100000",
+			causeError: ErrInvalidICalendar,
+		},
+	} {
+		_, err = ParseICalendar(shanghai, testCase.payload)
+		require.ErrorIs(t, err, testCase.causeError, testCase.payload)
+	}
+}
+
+func TestCalendarScheduler(t *testing.T) {
+	holidays, err := ParseCalendarDates(time.UTC, []string{"2024-08-15", "2024-08-16"})
+	require.NoError(t, err)
+	_, err = ParseCalendarDates(time.UTC, []string{"2024/08/15"})
+	require.ErrorIs(t, err, ErrInvalidCalendarDate)
+
+	worktime, err := NewCronScheduler(time.UTC, "0 9 * * 1-5", "0 19 * * 1-5")
+	require.NoError(t, err)
+	for _, c := range []struct {
+		description string
+		scheduler   Scheduler
+		exclude     bool
+		expected    map[string]bool
+	}{
+		{
+			description: "Worktime except holidays",
+			scheduler:   worktime,
+			exclude:     true,
+			expected: map[string]bool{
+				"2024-08-14 10:00": true,
+				"2024-08-14 20:00": false,
+				"2024-08-15 10:00": false, // Thursday holiday
+				"2024-08-16 10:00": false, // Friday holiday
+				"2024-08-17 10:00": false, // Saturday
+				"2024-08-19 10:00": true,
+			},
+		},
+		{
+			description: "Worktime of holidays",
+			scheduler:   worktime,
+			expected: map[string]bool{
+				"2024-08-14 10:00": false,
+				"2024-08-15 08:59": false,
+				"2024-08-15 10:00": true,
+				"2024-08-16 18:59": true,
+				"2024-08-16 19:00": false,
+			},
+		},
+		{
+			description: "Whole days of holidays",
+			expected: map[string]bool{
+				"2024-08-14 23:59": false,
+				"2024-08-15 00:00": true,
+				"2024-08-16 23:59": true,
+				"2024-08-17 00:00": false,
+			},
+		},
+		{
+			description: "Any time except holidays",
+			exclude:     true,
+			expected: map[string]bool{
+				"2024-08-14 23:59": true,
+				"2024-08-15 00:00": false,
+				"2024-08-17 00:00": true,
+			},
+		},
+	} {
+		s := NewCalendarScheduler(time.UTC, c.scheduler, holidays, c.exclude)
+		for when, expected := range c.expected {
+			require.Equal(t, expected, s.Contains(mustGetTime(when)), "[%s] when: %s", c.description, when)
+		}
+	}
+}
+
+func TestCalendarSchedulerGetNextTransition(t *testing.T) {
+	holidays, err := ParseCalendarDates(time.UTC, []string{"2024-08-15", "2024-08-16"})
+	require.NoError(t, err)
+	worktime, err := NewCronScheduler(time.UTC, "0 9 * * 1-5", "0 19 * * 1-5")
+	require.NoError(t, err)
+	s := NewCalendarScheduler(time.UTC, worktime, holidays, true)
+	for when, expected := range map[string]string{
+		"2024-08-14 20:00": "2024-08-15 00:00",
+		"2024-08-15 12:00": "2024-08-15 19:00",
+		"2024-08-16 20:00": "2024-08-17 00:00",
+		"2024-08-17 12:00": "2024-08-19 09:00",
+	} {
+		require.Equal(t, mustGetTime(expected), s.GetNextTransition(mustGetTime(when)).UTC(), "when: %s", when)
+	}
+	require.True(t, NewCalendarScheduler(time.UTC, nil, holidays, false).
+		GetNextTransition(mustGetTime("2024-08-17 00:00")).IsZero())
+}
@@ -12,6 +12,7 @@ import (
 var (
 	ErrCronScheduleSupportsExactMinuteHourValueOnly = errors.New("cron schedule supports exact minute and hour value only")
 	ErrNotAStandardCronSpec                         = errors.New("not a standard cron spec(https://en.wikipedia.org/wiki/Cron)")
+	ErrCronScheduleDurationMustBePositive           = errors.New("cron schedule duration must be positive")
 )
 
 const (
@@ -19,14 +20,14 @@ const (
 	CronFieldSeparator         = " "
 )
 
-func validateCronSpec(spec string) error {
+func validateCronSpec(spec string, exactMinuteHour bool) error {
 	if spec[0] != '@' {
 		fields := strings.Split(spec, CronFieldSeparator)
 		if len(fields) != 5 {
 			return fmt.Errorf("%w: `%s`", ErrNotAStandardCronSpec, spec)
 		}
-		if strings.ContainsAny(fields[0], cronAnyRangeListCharacters) ||
-			strings.ContainsAny(fields[1], cronAnyRangeListCharacters) {
+		if exactMinuteHour && (strings.ContainsAny(fields[0], cronAnyRangeListCharacters) ||
+			strings.ContainsAny(fields[1], cronAnyRangeListCharacters)) {
 			return fmt.Errorf("%w: `%s`", ErrCronScheduleSupportsExactMinuteHourValueOnly, spec)
 		}
 	}
@@ -34,18 +35,27 @@ func validateCronSpec(spec string) error {
 	return err
 }
 
+// parseCronScheduleSpec parses spec of start/end pair, whose minute and hour must be exact value
+// to pair start with end without ambiguity.
 func parseCronScheduleSpec(spec string) (cron.Schedule, error) {
-	if err := validateCronSpec(spec); err != nil {
+	return parseCronSpec(spec, true)
+}
+
+func parseCronSpec(spec string, exactMinuteHour bool) (cron.Schedule, error) {
+	if err := validateCronSpec(spec, exactMinuteHour); err != nil {
 		return nil, err
 	}
 	sched, err := cron.ParseStandard(spec)
 	return sched, err
 }
 
+// CronScheduler is a recurring period which starts at start spec and ends at end spec,
+// or lasts for duration from each start when duration is set.
 type CronScheduler struct {
 	baseScheduler
 	startSched cron.Schedule
 	endSched   cron.Schedule
+	duration   time.Duration
 }
 
 func NewCronScheduler(timezone *time.Location, start, end string) (*CronScheduler, error) {
@@ -68,11 +78,58 @@ func NewCronScheduler(timezone *time.Location, start, end string) (*CronSchedule
 	return s, nil
 }
 
+// NewCronDurationScheduler returns scheduler of windows lasting for duration from each start,
+// ranges and lists are allowed in minute and hour of start spec, e.g. `0 */2 * * *` with 15m.
+// Duration is elapsed time, so windows crossing DST transitions last for the same duration.
+func NewCronDurationScheduler(timezone *time.Location, start string, duration time.Duration) (*CronScheduler, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCronScheduleDurationMustBePositive, duration)
+	}
+	s := &CronScheduler{
+		baseScheduler: baseScheduler{
+			timezone: timezone,
+			rawStart: start,
+		},
+		duration: duration,
+	}
+	var err error
+	s.startSched, err = parseCronSpec(start, false)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 func (s *CronScheduler) Contains(when time.Time) bool {
 	whenInTimezone := when.In(s.timezone)
+	if s.duration > 0 {
+		// Latest start not after when, and when in [lastStart, lastStart + duration)
+		lastStart := s.startSched.Prev(whenInTimezone.Add(time.Second))
+		return !lastStart.IsZero() && whenInTimezone.Before(lastStart.Add(s.duration))
+	}
 	lastStart := s.startSched.Prev(whenInTimezone)
 	nextStart := s.startSched.Next(whenInTimezone)
 	nextEnd := s.endSched.Next(whenInTimezone)
 	// when in [lastStart, nextEnd) and nextStart > nextEnd
 	return whenInTimezone.After(lastStart) && whenInTimezone.Before(nextEnd) && nextStart.After(nextEnd)
 }
+
+func (s *CronScheduler) GetNextTransition(when time.Time) time.Time {
+	whenInTimezone := when.In(s.timezone)
+	nextStart := s.startSched.Next(whenInTimezone)
+	if s.duration == 0 {
+		return earliestTransition(nextStart, s.endSched.Next(whenInTimezone))
+	}
+	// End of the current window if any
+	var end time.Time
+	if lastStart := s.startSched.Prev(whenInTimezone.Add(time.Second)); !lastStart.IsZero() &&
+		whenInTimezone.Before(lastStart.Add(s.duration)) {
+		end = lastStart.Add(s.duration)
+	}
+	return earliestTransition(nextStart, end)
+}
+
+// GetDuration returns duration of windows, zero means windows end at end spec
+func (s *CronScheduler) GetDuration() time.Duration {
+	return s.duration
+}
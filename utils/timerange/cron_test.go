@@ -261,3 +261,166 @@ func TestCronScheduleContains(t *testing.T) {
 		}
 	}
 }
+
+func TestCronDurationScheduleContains(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		start       string
+		duration    time.Duration
+		expected    map[string]bool
+	}{
+		{
+			description: "First 15 minutes of every 2 hours",
+			start:       "0 */2 * * *",
+			duration:    15 * time.Minute,
+			expected: map[string]bool{
+				"2024-08-15 07:59": false,
+				"2024-08-15 08:00": true,
+				"2024-08-15 08:14": true,
+				"2024-08-15 08:15": false,
+				"2024-08-15 09:00": false,
+				"2024-08-15 10:05": true,
+				"2024-08-16 00:00": true,
+			},
+		},
+		{
+			description: "08:00-09:00 and 13:00-14:00 on weekdays",
+			start:       "0 8,13 * * 1-5",
+			duration:    time.Hour,
+			expected: map[string]bool{
+				"2024-08-15 07:59": false,
+				"2024-08-15 08:30": true,
+				"2024-08-15 09:00": false,
+				"2024-08-15 13:00": true,
+				"2024-08-15 13:59": true,
+				"2024-08-15 14:00": false,
+				"2024-08-17 08:30": false, // Saturday
+			},
+		},
+		{
+			description: "Crossing the midnight",
+			start:       "30 23 * * 5",
+			duration:    time.Hour,
+			expected: map[string]bool{
+				"2024-08-16 23:29": false,
+				"2024-08-16 23:30": true,
+				"2024-08-17 00:29": true, // Saturday
+				"2024-08-17 00:30": false,
+				"2024-08-17 23:30": false,
+			},
+		},
+		{
+			description: "Duration longer than interval covers all the time",
+			start:       "*/10 * * * *",
+			duration:    time.Hour,
+			expected: map[string]bool{
+				"2024-08-15 00:00": true,
+				"2024-08-15 00:05": true,
+				"2024-08-15 12:34": true,
+			},
+		},
+	} {
+		s, err := NewCronDurationScheduler(time.UTC, c.start, c.duration)
+		require.NoError(t, err)
+		for when, expected := range c.expected {
+			t.Run(c.description+": "+when, func(t *testing.T) {
+				require.Equal(t, expected, s.Contains(mustGetTime(when)),
+					"[%s] start: %s, duration: %s, when: %s", c.description, c.start, c.duration, when)
+			})
+		}
+	}
+
+	_, err := NewCronDurationScheduler(time.UTC, "0 */2 * * *", 0)
+	require.ErrorIs(t, err, ErrCronScheduleDurationMustBePositive)
+	_, err = NewCronDurationScheduler(time.UTC, "0 */2 * *", time.Hour)
+	require.ErrorIs(t, err, ErrNotAStandardCronSpec)
+}
+
+func TestCronScheduleContainsAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	for _, c := range []struct {
+		description string
+		start       string
+		end         string
+		duration    time.Duration
+		expected    map[time.Time]bool
+	}{
+		{
+			// 2024-03-10 02:00 EST jumps to 03:00 EDT
+			description: "Duration window lasts elapsed time across spring forward",
+			start:       "0 1 * * *",
+			duration:    2 * time.Hour,
+			expected: map[time.Time]bool{
+				time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC):  true,  // 01:00 EST
+				time.Date(2024, 3, 10, 7, 59, 0, 0, time.UTC): true,  // 03:59 EDT
+				time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC):  false, // 04:00 EDT
+			},
+		},
+		{
+			// 2024-11-03 02:00 EDT falls back to 01:00 EST
+			description: "Duration window lasts elapsed time across fall back",
+			start:       "0 0 * * *",
+			duration:    2 * time.Hour,
+			expected: map[time.Time]bool{
+				time.Date(2024, 11, 3, 4, 0, 0, 0, time.UTC):  true,  // 00:00 EDT
+				time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC): true,  // 01:30 EDT
+				time.Date(2024, 11, 3, 5, 59, 0, 0, time.UTC): true,  // 01:59 EDT
+				time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC):  false, // 01:00 EST
+			},
+		},
+		{
+			description: "Duration window starting in skipped hour",
+			start:       "30 2 * * *",
+			duration:    time.Hour,
+			expected: map[time.Time]bool{
+				time.Date(2024, 3, 9, 7, 30, 0, 0, time.UTC):  true,  // 02:30 EST the day before
+				time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC): false, // 03:30 EDT, 02:30 never comes
+				time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC): true,  // 02:30 EDT the day after
+			},
+		},
+		{
+			description: "Start/end window follows wall clock across spring forward",
+			start:       "0 1 * * *",
+			end:         "0 4 * * *",
+			expected: map[time.Time]bool{
+				time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC):  true,  // 01:00 EST
+				time.Date(2024, 3, 10, 7, 59, 0, 0, time.UTC): true,  // 03:59 EDT
+				time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC):  false, // 04:00 EDT
+			},
+		},
+	} {
+		var s *CronScheduler
+		if c.duration > 0 {
+			s, err = NewCronDurationScheduler(newYork, c.start, c.duration)
+		} else {
+			s, err = NewCronScheduler(newYork, c.start, c.end)
+		}
+		require.NoError(t, err)
+		for when, expected := range c.expected {
+			require.Equal(t, expected, s.Contains(when), "[%s] when: %s", c.description, when.In(newYork))
+		}
+	}
+}
+
+func TestCronSchedulerGetNextTransition(t *testing.T) {
+	worktime, err := NewCronScheduler(time.UTC, "0 9 * * 1-5", "0 19 * * 1-5")
+	require.NoError(t, err)
+	windows, err := NewCronDurationScheduler(time.UTC, "0 */2 * * *", 15*time.Minute)
+	require.NoError(t, err)
+	for _, c := range []struct {
+		scheduler Scheduler
+		when      string
+		expected  string
+	}{
+		{scheduler: worktime, when: "2024-08-15 08:00", expected: "2024-08-15 09:00"},
+		{scheduler: worktime, when: "2024-08-15 09:00", expected: "2024-08-15 19:00"},
+		// Friday night to Monday morning
+		{scheduler: worktime, when: "2024-08-16 20:00", expected: "2024-08-19 09:00"},
+		{scheduler: windows, when: "2024-08-15 10:05", expected: "2024-08-15 10:15"},
+		{scheduler: windows, when: "2024-08-15 10:15", expected: "2024-08-15 12:00"},
+	} {
+		require.Equal(t, mustGetTime(c.expected), c.scheduler.GetNextTransition(mustGetTime(c.when)).UTC(),
+			"%s at %s", c.scheduler.GetStart(), c.when)
+	}
+}
@@ -60,6 +60,44 @@ func (s *DateScheduler) GetUpcomingTriggerDuration(when time.Time) (time.Time, t
 	return s.startTime, s.endTime
 }
 
+func (s *DateScheduler) GetNextTransition(when time.Time) time.Time {
+	switch {
+	case when.Before(s.startTime):
+		return s.startTime
+	case !when.After(s.endTime):
+		return s.endTime
+	}
+	return time.Time{}
+}
+
 func (s *DateScheduler) GetEndTime() time.Time {
 	return s.endTime
 }
+
+// shift returns copy of s which starts lead time earlier and ends delay later
+func (s *DateScheduler) shift(leadTime, delay time.Duration) *DateScheduler {
+	shifted := *s
+	shifted.startTime = s.startTime.Add(-leadTime)
+	shifted.endTime = s.endTime.Add(delay)
+	return &shifted
+}
+
+// AsDateScheduler returns date scheduler of s, including the one narrowed by calendar.
+// The date scheduler of shifted one is shifted as well.
+func AsDateScheduler(s Scheduler) (*DateScheduler, bool) {
+	var leadTime, delay time.Duration
+	if shiftedScheduler, ok := s.(*ShiftedScheduler); ok {
+		s, leadTime, delay = shiftedScheduler.GetScheduler(), shiftedScheduler.GetLeadTime(), shiftedScheduler.GetDelay()
+	}
+	if calendarScheduler, ok := s.(*CalendarScheduler); ok {
+		s = calendarScheduler.GetScheduler()
+	}
+	dateScheduler, ok := s.(*DateScheduler)
+	if !ok {
+		return nil, false
+	}
+	if leadTime != 0 || delay != 0 {
+		dateScheduler = dateScheduler.shift(leadTime, delay)
+	}
+	return dateScheduler, true
+}
@@ -4,6 +4,9 @@ import "time"
 
 type Scheduler interface {
 	Contains(when time.Time) bool
+	// GetNextTransition returns the upcoming boundary not before when at which the period starts or ends,
+	// zero time means the period never changes again.
+	GetNextTransition(when time.Time) time.Time
 	GetStart() string
 	GetEnd() string
 	GetTimezone() *time.Location
@@ -26,3 +29,14 @@ func (s baseScheduler) GetEnd() string {
 func (s baseScheduler) GetTimezone() *time.Location {
 	return s.timezone
 }
+
+// earliestTransition returns the earliest non-zero transition, zero time means none
+func earliestTransition(transitions ...time.Time) time.Time {
+	var earliest time.Time
+	for _, transition := range transitions {
+		if !transition.IsZero() && (earliest.IsZero() || transition.Before(earliest)) {
+			earliest = transition
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,74 @@
+package timerange
+
+import "time"
+
+// ShiftedScheduler extends each period of scheduler, it starts lead time earlier and ends delay later.
+// Periods shorter than lead time plus delay might not be fully bridged.
+type ShiftedScheduler struct {
+	baseScheduler
+	scheduler Scheduler
+	leadTime  time.Duration
+	delay     time.Duration
+}
+
+func NewShiftedScheduler(scheduler Scheduler, leadTime, delay time.Duration) *ShiftedScheduler {
+	return &ShiftedScheduler{
+		baseScheduler: baseScheduler{
+			timezone: scheduler.GetTimezone(),
+			rawStart: scheduler.GetStart(),
+			rawEnd:   scheduler.GetEnd(),
+		},
+		scheduler: scheduler,
+		leadTime:  leadTime,
+		delay:     delay,
+	}
+}
+
+func (s *ShiftedScheduler) Contains(when time.Time) bool {
+	// Period starts within lead time or ended within delay
+	return s.scheduler.Contains(when) ||
+		(s.leadTime > 0 && s.scheduler.Contains(when.Add(s.leadTime))) ||
+		(s.delay > 0 && s.scheduler.Contains(when.Add(-s.delay)))
+}
+
+// maxShiftedTransitionLookups bounds lookups skipping boundaries bridged by lead time or delay
+const maxShiftedTransitionLookups = 8
+
+func (s *ShiftedScheduler) GetNextTransition(when time.Time) time.Time {
+	var next time.Time
+	for i := 0; i < maxShiftedTransitionLookups; i++ {
+		next = s.getShiftedTransition(when)
+		// Skip boundary that the period keeps working across, e.g. end shifted by lead time
+		if next.IsZero() || s.Contains(next.Add(-time.Second)) != s.Contains(next.Add(time.Second)) {
+			return next
+		}
+		when = next.Add(time.Second)
+	}
+	return next
+}
+
+// getShiftedTransition returns the earliest boundary of scheduler shifted by either lead time or delay,
+// since starts come lead time earlier and ends come delay later.
+func (s *ShiftedScheduler) getShiftedTransition(when time.Time) time.Time {
+	var leadTransition, delayTransition time.Time
+	if transition := s.scheduler.GetNextTransition(when.Add(s.leadTime)); !transition.IsZero() {
+		leadTransition = transition.Add(-s.leadTime)
+	}
+	if transition := s.scheduler.GetNextTransition(when.Add(-s.delay)); !transition.IsZero() {
+		delayTransition = transition.Add(s.delay)
+	}
+	return earliestTransition(leadTransition, delayTransition)
+}
+
+// GetScheduler returns the shifted scheduler
+func (s *ShiftedScheduler) GetScheduler() Scheduler {
+	return s.scheduler
+}
+
+func (s *ShiftedScheduler) GetLeadTime() time.Duration {
+	return s.leadTime
+}
+
+func (s *ShiftedScheduler) GetDelay() time.Duration {
+	return s.delay
+}
@@ -0,0 +1,47 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftedScheduler(t *testing.T) {
+	worktime, err := NewCronScheduler(time.UTC, "0 9 * * *", "0 19 * * *")
+	require.NoError(t, err)
+	s := NewShiftedScheduler(worktime, 5*time.Minute, 30*time.Minute)
+	for when, expected := range map[string]bool{
+		"2024-08-15 08:54": false,
+		"2024-08-15 08:55": true,
+		"2024-08-15 12:00": true,
+		"2024-08-15 19:29": true,
+		"2024-08-15 19:31": false,
+	} {
+		require.Equal(t, expected, s.Contains(mustGetTime(when)), "when: %s", when)
+	}
+
+	sale, err := NewDateScheduler(time.UTC, "2024-11-11 00:00", "2024-11-11 23:59")
+	require.NoError(t, err)
+	dateScheduler, ok := AsDateScheduler(NewShiftedScheduler(sale, time.Hour, 10*time.Minute))
+	require.True(t, ok)
+	start, end := dateScheduler.GetUpcomingTriggerDuration(mustGetTime("2024-11-10 12:00"))
+	require.Equal(t, mustGetTime("2024-11-10 23:00"), start)
+	require.Equal(t, mustGetTime("2024-11-12 00:09"), end)
+	// The shifted date scheduler is a copy
+	require.Equal(t, mustGetTime("2024-11-11 23:59"), sale.GetEndTime())
+}
+
+func TestShiftedSchedulerGetNextTransition(t *testing.T) {
+	worktime, err := NewCronScheduler(time.UTC, "0 9 * * *", "0 19 * * *")
+	require.NoError(t, err)
+	s := NewShiftedScheduler(worktime, 5*time.Minute, 30*time.Minute)
+	for when, expected := range map[string]string{
+		"2024-08-15 08:00": "2024-08-15 08:55",
+		// Boundaries bridged by lead time or delay are skipped
+		"2024-08-15 09:00": "2024-08-15 19:30",
+		"2024-08-15 19:40": "2024-08-16 08:55",
+	} {
+		require.Equal(t, mustGetTime(expected), s.GetNextTransition(mustGetTime(when)).UTC(), "when: %s", when)
+	}
+}
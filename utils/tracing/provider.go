@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+type Options struct {
+	// Endpoint is host and port of OTLP/HTTP receiver, e.g. `otel-collector:4318`
+	Endpoint string
+	// URLPath overrides the default `/v1/traces`
+	URLPath string
+	// Insecure sends traces over HTTP instead of HTTPS
+	Insecure bool
+	// Headers are sent along with traces, e.g. authentication
+	Headers map[string]string
+	// SampleRatio is the ratio of root spans to sample, spans follow their parents' decision
+	SampleRatio float64
+	// ServiceName identifies this controller in traces
+	ServiceName string
+	// ServiceInstanceID distinguishes replicas of controller
+	ServiceInstanceID string
+}
+
+func (o Options) Validate() error {
+	if o.Endpoint == "" {
+		return errors.New("tracing endpoint is required")
+	}
+	if o.SampleRatio < 0 || o.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio %v is out of [0, 1]", o.SampleRatio)
+	}
+	return nil
+}
+
+// Setup registers a global tracer provider exporting spans via OTLP/HTTP in batches,
+// the provider should be shut down to flush spans before exiting.
+func Setup(options Options) (*sdktrace.TracerProvider, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	clientOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(options.Endpoint),
+	}
+	if options.URLPath != "" {
+		clientOptions = append(clientOptions, otlptracehttp.WithURLPath(options.URLPath))
+	}
+	if options.Insecure {
+		clientOptions = append(clientOptions, otlptracehttp.WithInsecure())
+	}
+	if len(options.Headers) > 0 {
+		clientOptions = append(clientOptions, otlptracehttp.WithHeaders(options.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	attributes := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(options.ServiceName))
+	if options.ServiceInstanceID != "" {
+		attributes, err = resource.Merge(attributes, resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceInstanceIDKey.String(options.ServiceInstanceID)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(attributes),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	return provider, nil
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanAndTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	//nolint:staticcheck
+	ctx, span := StartSpan(nil, "parent")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	response, err := (&http.Client{Transport: NewTransport(nil)}).Do(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	EndSpan(span, errors.New("failed"))
+
+	require.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	parent := spans[1]
+	require.Equal(t, "parent", parent.Name())
+	require.Equal(t, codes.Error, parent.Status().Code)
+	require.Equal(t, span.SpanContext().TraceID(), spans[0].Parent().TraceID())
+}
+
+func TestOptionsValidate(t *testing.T) {
+	for _, testCase := range []struct {
+		name    string
+		options Options
+		valid   bool
+	}{
+		{name: "valid", options: Options{Endpoint: "otel-collector:4318", SampleRatio: 0.1}, valid: true},
+		{name: "without endpoint", options: Options{SampleRatio: 0.1}},
+		{name: "invalid sample ratio", options: Options{Endpoint: "otel-collector:4318", SampleRatio: 2}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.options.Validate()
+			if testCase.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -177,14 +178,22 @@ type FluxRule struct {
 
 type FluxTuner struct {
 	options                          FluxOptions
+	clock                            clock.PassiveClock
 	historicalScaleUpReplicaMemory   *sync.Map
 	historicalScaleDownReplicaMemory *sync.Map
 }
 
 func NewFluxTuner(options FluxOptions) *FluxTuner {
+	return NewFluxTunerWithClock(options, clock.RealClock{})
+}
+
+// NewFluxTunerWithClock returns flux tuner which takes snapshots and cuts off memory by given clock,
+// it's used to replay scaling with a fake clock.
+func NewFluxTunerWithClock(options FluxOptions, clock clock.PassiveClock) *FluxTuner {
 	return &FluxTuner{
 		// Apply default configs for rest of the fields
 		options:                          options.ApplyDefaults(),
+		clock:                            clock,
 		historicalScaleUpReplicaMemory:   &sync.Map{},
 		historicalScaleDownReplicaMemory: &sync.Map{},
 	}
@@ -214,7 +223,7 @@ func (f *FluxTuner) loadPreference(preference interface{}) FluxPreference {
 }
 
 func (f *FluxTuner) GetRecommendation(keyForAutoscaler string,
-	currentReplicas int32, desiredReplicas int32, preference interface{}) int32 {
+	currentReplicas int32, desiredReplicas int32, preference interface{}, explainers ...Explainer) int32 {
 	logger := log.FromContext(context.TODO()).WithValues(
 		"tuner", f.GetName(),
 		"keyForAutoscaler", keyForAutoscaler,
@@ -235,6 +244,7 @@ func (f *FluxTuner) GetRecommendation(keyForAutoscaler string,
 		limit := f.getScaleUpLimit(logger, rm.(ReplicaMemory), currentReplicas, fluxPreference.ScaleUpRuleSet)
 		if limit != nil && desiredReplicas > *limit {
 			logger.V(2).Info("Scale up limit reached", "limit", limit)
+			f.explain(explainers, *limit, fmt.Sprintf("scale up %d -> %d limited by flux rules", currentReplicas, desiredReplicas))
 			desiredReplicas = *limit
 		}
 	} else {
@@ -247,6 +257,7 @@ func (f *FluxTuner) GetRecommendation(keyForAutoscaler string,
 		limit := f.getScaleDownLimit(logger, rm.(ReplicaMemory), currentReplicas, fluxPreference.ScaleDownRuleSet)
 		if limit != nil && desiredReplicas < *limit {
 			logger.V(2).Info("Scale down limit reached", "limit", limit)
+			f.explain(explainers, *limit, fmt.Sprintf("scale down %d -> %d limited by flux rules", currentReplicas, desiredReplicas))
 			desiredReplicas = *limit
 		}
 	}
@@ -256,13 +267,19 @@ func (f *FluxTuner) GetRecommendation(keyForAutoscaler string,
 	return desiredReplicas
 }
 
+func (f *FluxTuner) explain(explainers []Explainer, replicas int32, message string) {
+	for _, explainer := range explainers {
+		explainer.Explain(f.GetName(), replicas, message)
+	}
+}
+
 func (f *FluxTuner) newReplicaMemory() ReplicaMemory {
-	return NewSimpleReplicaMemory(f.options.ReplicaMemoryMaxSize, f.options.ReplicaMemoryRetention)
+	return NewSimpleReplicaMemoryWithClock(f.options.ReplicaMemoryMaxSize, f.options.ReplicaMemoryRetention, f.clock)
 }
 
 func (f *FluxTuner) AcceptRecommendation(keyForAutoscaler string, currentReplicas int32, desiredReplicas int32) {
 	snapshot := ReplicaSnapshot{
-		Timestamp: time.Now(),
+		Timestamp: f.clock.Now(),
 		Replicas:  desiredReplicas,
 	}
 
@@ -286,6 +303,55 @@ func (f *FluxTuner) addSnapshot(memory *sync.Map, key string, snapshot ReplicaSn
 	recordedMemory.(ReplicaMemory).Add(snapshot)
 }
 
+type fluxMemory struct {
+	ScaleUp   []ReplicaSnapshot `json:"scaleUp,omitempty"`
+	ScaleDown []ReplicaSnapshot `json:"scaleDown,omitempty"`
+}
+
+// ExportMemory implements StatefulTuner, returns nil if there is no memory for the autoscaler
+func (f *FluxTuner) ExportMemory(keyForAutoscaler string) ([]byte, error) {
+	var memory fluxMemory
+	if rm, ok := f.historicalScaleUpReplicaMemory.Load(keyForAutoscaler); ok {
+		memory.ScaleUp = rm.(ReplicaMemory).GetMemorySince(time.Time{}, 0)
+	}
+	if rm, ok := f.historicalScaleDownReplicaMemory.Load(keyForAutoscaler); ok {
+		memory.ScaleDown = rm.(ReplicaMemory).GetMemorySince(time.Time{}, 0)
+	}
+	if len(memory.ScaleUp) == 0 && len(memory.ScaleDown) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(memory)
+}
+
+// ImportMemory implements StatefulTuner, imported memory replaces existing one
+func (f *FluxTuner) ImportMemory(keyForAutoscaler string, data []byte) error {
+	var memory fluxMemory
+	if err := json.Unmarshal(data, &memory); err != nil {
+		return err
+	}
+	f.ForgetMemory(keyForAutoscaler)
+	for _, snapshot := range memory.ScaleUp {
+		f.addSnapshot(f.historicalScaleUpReplicaMemory, keyForAutoscaler, snapshot)
+	}
+	for _, snapshot := range memory.ScaleDown {
+		f.addSnapshot(f.historicalScaleDownReplicaMemory, keyForAutoscaler, snapshot)
+	}
+	return nil
+}
+
+// ForgetMemory implements StatefulTuner
+func (f *FluxTuner) ForgetMemory(keyForAutoscaler string) {
+	f.historicalScaleUpReplicaMemory.Delete(keyForAutoscaler)
+	f.historicalScaleDownReplicaMemory.Delete(keyForAutoscaler)
+}
+
+// InheritMemory shares memory of previous tuner on reloading with new options.
+// Memory already recorded keeps its max size and retention until the autoscaler is forgotten.
+func (f *FluxTuner) InheritMemory(previous *FluxTuner) {
+	f.historicalScaleUpReplicaMemory = previous.historicalScaleUpReplicaMemory
+	f.historicalScaleDownReplicaMemory = previous.historicalScaleDownReplicaMemory
+}
+
 func (f *FluxTuner) getScaleUpLimit(logger logr.Logger, replicaMemory ReplicaMemory, currentReplicas int32, ruleSet *FluxRuleSet) *int32 {
 	limit := int32(math.MaxInt32)
 	choosePolicy := min
@@ -300,7 +366,7 @@ func (f *FluxTuner) getScaleUpLimit(logger logr.Logger, replicaMemory ReplicaMem
 			logger.Info("Invalid rule value for calculating scale up limit", "rule", rule.Value.String())
 			continue
 		}
-		cutoff := time.Now().Add(-time.Duration(rule.PeriodSeconds) * time.Second)
+		cutoff := f.clock.Now().Add(-time.Duration(rule.PeriodSeconds) * time.Second)
 		snapshotAfterCutoff := replicaMemory.GetFirstSnapshotAfter(cutoff, f.options.MemoryCutoffJitterToleration)
 		var replicasBase int32
 		if snapshotAfterCutoff != nil {
@@ -338,7 +404,7 @@ func (f *FluxTuner) getScaleDownLimit(logger logr.Logger, replicaMemory ReplicaM
 			logger.Info("Invalid rule value for calculating scale down limit", "rule", rule.Value.String())
 			continue
 		}
-		cutoff := time.Now().Add(-time.Duration(rule.PeriodSeconds) * time.Second)
+		cutoff := f.clock.Now().Add(-time.Duration(rule.PeriodSeconds) * time.Second)
 		snapshotAfterCutoff := replicaMemory.GetFirstSnapshotAfter(cutoff, f.options.MemoryCutoffJitterToleration)
 		var replicasBase int32
 		if snapshotAfterCutoff != nil {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestFluxTuner_GetRecommendation(t *testing.T) {
@@ -330,3 +331,94 @@ func TestFluxTuner_GetRecommendation(t *testing.T) {
 		})
 	}
 }
+
+type explanation struct {
+	tuner    string
+	replicas int32
+}
+
+type recordingExplainer struct {
+	explanations []explanation
+}
+
+func (e *recordingExplainer) Explain(tuner string, replicas int32, _ string) {
+	e.explanations = append(e.explanations, explanation{tuner: tuner, replicas: replicas})
+}
+
+func TestFluxTuner_GetRecommendationExplanation(t *testing.T) {
+	fc := NewFluxTuner(NewDefaultFluxOptions())
+
+	explainer := &recordingExplainer{}
+	got := fc.GetRecommendation("test", 10, 12, FluxPreference{}, explainer)
+	if got != 12 {
+		t.Fatalf("FluxTuner.GetRecommendation() = %v, want %v", got, 12)
+	}
+	if len(explainer.explanations) != 0 {
+		t.Errorf("unexpected explanations %v when not limited", explainer.explanations)
+	}
+
+	got = fc.GetRecommendation("test", 10, 100, FluxPreference{}, explainer)
+	if got != 15 {
+		t.Fatalf("FluxTuner.GetRecommendation() = %v, want %v", got, 15)
+	}
+	if len(explainer.explanations) != 1 || explainer.explanations[0] != (explanation{tuner: "flux", replicas: 15}) {
+		t.Errorf("unexpected explanations %v", explainer.explanations)
+	}
+}
+
+func TestFluxTuner_HandOverMemory(t *testing.T) {
+	from := NewFluxTuner(NewDefaultFluxOptions())
+	to := NewFluxTuner(NewDefaultFluxOptions())
+
+	memory, err := from.ExportMemory("test")
+	if err != nil || memory != nil {
+		t.Fatalf("FluxTuner.ExportMemory() = %s, %v, want empty memory", memory, err)
+	}
+
+	// Scaled up to 15 just now, so further scaling up is limited by 15 * 150%
+	from.AcceptRecommendation("test", 10, 15)
+	memory, err = from.ExportMemory("test")
+	if err != nil {
+		t.Fatalf("FluxTuner.ExportMemory() error = %v", err)
+	}
+	from.ForgetMemory("test")
+	if got := from.GetRecommendation("test", 15, 100, FluxPreference{}); got != 23 {
+		t.Errorf("FluxTuner.GetRecommendation() after forgetting = %v, want %v", got, 23)
+	}
+
+	if err = to.ImportMemory("test", memory); err != nil {
+		t.Fatalf("FluxTuner.ImportMemory() error = %v", err)
+	}
+	if got := to.GetRecommendation("test", 30, 100, FluxPreference{}); got != 23 {
+		t.Errorf("FluxTuner.GetRecommendation() after importing = %v, want %v", got, 23)
+	}
+}
+
+func TestFluxTuner_InheritMemory(t *testing.T) {
+	previous := NewFluxTuner(NewDefaultFluxOptions())
+	// Scaled up to 15 just now, so further scaling up is limited by 15 * 150%
+	previous.AcceptRecommendation("test", 10, 15)
+
+	reloaded := NewFluxTuner(NewDefaultFluxOptions())
+	reloaded.InheritMemory(previous)
+	if got := reloaded.GetRecommendation("test", 30, 100, FluxPreference{}); got != 23 {
+		t.Errorf("FluxTuner.GetRecommendation() after inheriting = %v, want %v", got, 23)
+	}
+}
+
+func TestFluxTuner_WithClock(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fc := NewFluxTunerWithClock(NewDefaultFluxOptions(), fakeClock)
+
+	// Scaled up to 15 just now, so further scaling up is limited by 15 * 150%
+	fc.AcceptRecommendation("test", 10, 15)
+	if got := fc.GetRecommendation("test", 15, 100, FluxPreference{}); got != 23 {
+		t.Errorf("FluxTuner.GetRecommendation() = %v, want %v", got, 23)
+	}
+
+	// Snapshot is out of period after a while, limited by current replicas then
+	fakeClock.SetTime(fakeClock.Now().Add(2 * time.Minute))
+	if got := fc.GetRecommendation("test", 30, 100, FluxPreference{}); got != 45 {
+		t.Errorf("FluxTuner.GetRecommendation() after period = %v, want %v", got, 45)
+	}
+}
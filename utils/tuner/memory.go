@@ -4,6 +4,8 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"k8s.io/utils/clock"
 )
 
 var (
@@ -27,14 +29,21 @@ type replicaMemory struct {
 	events    []ReplicaSnapshot
 	maxSize   int
 	retention time.Duration
+	clock     clock.PassiveClock
 	mu        sync.RWMutex
 }
 
 func NewSimpleReplicaMemory(maxSize int, retention time.Duration) *replicaMemory {
+	return NewSimpleReplicaMemoryWithClock(maxSize, retention, clock.RealClock{})
+}
+
+// NewSimpleReplicaMemoryWithClock returns replica memory expiring events by given clock
+func NewSimpleReplicaMemoryWithClock(maxSize int, retention time.Duration, clock clock.PassiveClock) *replicaMemory {
 	return &replicaMemory{
 		events:    make([]ReplicaSnapshot, 0, maxSize), // Pre-allocate capacity to maxSize
 		maxSize:   maxSize,
 		retention: retention,
+		clock:     clock,
 	}
 }
 
@@ -42,7 +51,7 @@ func (s *replicaMemory) Add(event ReplicaSnapshot) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now()
+	now := s.clock.Now()
 	cutoff := now.Add(-s.retention)
 
 	// Filter expired events in-place to avoid allocating new slice
@@ -5,11 +5,25 @@ type Tuner interface {
 	// GetRecommendation returns a recommended replica count based on the current state and preferences.
 	// Some tuners may not distinguish between scale up/down preferences, so the preference structure
 	// is left to each tuner's implementation to interpret appropriately.
+	// Explainers are optional, tuners should explain to them when the recommendation differs from desired replicas.
 	GetRecommendation(keyForAutoscaler string,
-		currentReplicas int32, desiredReplicas int32, preference interface{}) int32
+		currentReplicas int32, desiredReplicas int32, preference interface{}, explainers ...Explainer) int32
 	AcceptRecommendation(keyForAutoscaler string, currentReplicas int32, desiredReplicas int32)
 }
 
+// StatefulTuner keeps memory per autoscaler, which could be exported and imported
+// to hand over autoscaler between controllers.
+type StatefulTuner interface {
+	ExportMemory(keyForAutoscaler string) ([]byte, error)
+	ImportMemory(keyForAutoscaler string, memory []byte) error
+	ForgetMemory(keyForAutoscaler string)
+}
+
+// Explainer receives explanation of tuner adjustments
+type Explainer interface {
+	Explain(tuner string, replicas int32, message string)
+}
+
 func max(a, b int32) int32 {
 	if a >= b {
 		return a